@@ -4,32 +4,56 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
-	"github.com/cloudwego/eino/schema"
 	"log"
 	"os"
 	"strings"
+
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/memory"
 )
 
+/*
+多轮对话与对话记忆:
+	完整历史保存在会话（Session）中，每轮结束自动保存，退出后可以用 /resume 继续。
+	每次调用只发送窗口内的历史：最近几轮原样保留，更早的对话由模型压缩成摘要，避免超出上下文窗口。
+	默认保存在 .sessions 目录，设置 MEMORY_STORE=sqlite 时保存到 sessions.db。
+
+命令:
+	/new            开始新会话
+	/save           保存当前会话
+	/list           列出已保存的会话
+	/resume <id>    继续某个会话
+	/fork [id]      复制当前（或指定）会话，在副本上继续
+	exit            结束对话
+*/
+
+const systemPrompt = "你是一个友好的 AI 助手"
+
 func main() {
 	ctx := context.Background()
 
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:  os.Getenv("API_KEY"),
-		BaseURL: "https://api.deepseek.com",
-		Model:   "deepseek-chat",
-	})
+	chatModel, err := chatmodel.New(ctx, "")
 	if err != nil {
 		log.Fatalf("创建失败：%v", err)
 	}
 
-	// 对话历史
-	message := []*schema.Message{
-		schema.SystemMessage("你是一个友好的 AI 助手"),
+	store, err := openStore()
+	if err != nil {
+		log.Fatalf("打开会话存储失败：%v", err)
 	}
+	// 最近 6 轮原样发送，窗口外积累 4 轮后合并进摘要
+	mem := memory.New(store, memory.Summarize(&memory.SummaryConfig{
+		Model: chatModel,
+		Keep:  memory.LastN(6),
+		Batch: 4,
+	}))
+
+	session := memory.NewSession(systemPrompt)
 
 	scanner := bufio.NewScanner(os.Stdin)
-	fmt.Println("开始对话，输入 'exit' 以结束对话：")
+	fmt.Println("开始对话，输入 'exit' 以结束对话，输入 /list 查看历史会话：")
 
 	for {
 		fmt.Print("你: ")
@@ -45,20 +69,110 @@ func main() {
 		if userInput == "" {
 			continue
 		}
+		if strings.HasPrefix(userInput, "/") {
+			if next := handleCommand(ctx, mem, session, userInput); next != nil {
+				session = next
+			}
+			continue
+		}
 
 		// 添加用户消息到对话历史
-		message = append(message, schema.UserMessage(userInput))
+		session.Append(schema.UserMessage(userInput))
+
+		messages, err := mem.Messages(ctx, session)
+		if err != nil {
+			log.Printf("构造上下文失败：%v", err)
+			continue
+		}
 
 		// 生成响应
-		response, err := chatModel.Generate(ctx, message)
+		response, err := chatModel.Generate(ctx, messages)
 		if err != nil {
-			log.Fatalf("生成失败：%v", err)
+			log.Printf("生成失败：%v", err)
+			// 撤回本轮的用户消息，保持历史一问一答
+			session.Messages = session.Messages[:len(session.Messages)-1]
 			continue
 		}
 
-		// 添加模型响应到对话历史
-		message = append(message, response)
+		// 添加模型响应到对话历史并保存
+		session.Append(response)
+		if err := mem.Save(ctx, session); err != nil {
+			log.Printf("保存会话失败：%v", err)
+		}
+
+		fmt.Printf("\nAI: %s\n", response.Content)
+	}
+}
+
+func openStore() (memory.Store, error) {
+	if os.Getenv("MEMORY_STORE") == "sqlite" {
+		return memory.OpenSQLite("sessions.db")
+	}
+	return memory.NewFileStore(".sessions")
+}
+
+// handleCommand 处理斜杠命令，返回非 nil 时切换到该会话
+func handleCommand(ctx context.Context, mem *memory.Memory, current *memory.Session, line string) *memory.Session {
+	fields := strings.Fields(line)
+	cmd, args := fields[0], fields[1:]
+
+	switch cmd {
+	case "/new":
+		s := memory.NewSession(systemPrompt)
+		fmt.Printf("已开始新会话 %s\n", s.ID)
+		return s
+
+	case "/save":
+		if err := mem.Save(ctx, current); err != nil {
+			fmt.Printf("保存失败：%v\n", err)
+			return nil
+		}
+		fmt.Printf("已保存会话 %s\n", current.ID)
+
+	case "/list":
+		infos, err := mem.List(ctx)
+		if err != nil {
+			fmt.Printf("读取会话列表失败：%v\n", err)
+			return nil
+		}
+		if len(infos) == 0 {
+			fmt.Println("还没有保存的会话")
+		}
+		for _, info := range infos {
+			fmt.Println(memory.FormatInfo(info))
+		}
+
+	case "/resume":
+		if len(args) != 1 {
+			fmt.Println("用法：/resume <id>")
+			return nil
+		}
+		s, err := mem.Resume(ctx, args[0])
+		if err != nil {
+			fmt.Printf("加载失败：%v\n", err)
+			return nil
+		}
+		fmt.Printf("已恢复会话 %s（%d 轮）\n", s.ID, s.Turns())
+		return s
+
+	case "/fork":
+		id := current.ID
+		if len(args) > 0 {
+			id = args[0]
+		} else if err := mem.Save(ctx, current); err != nil {
+			fmt.Printf("保存失败：%v\n", err)
+			return nil
+		}
+		s, err := mem.Fork(ctx, id)
+		if err != nil {
+			fmt.Printf("fork 失败：%v\n", err)
+			return nil
+		}
+		fmt.Printf("已从 %s fork 出新会话 %s\n", id, s.ID)
+		return s
 
-		fmt.Printf("\\nAI: %s\\n", response.Content)
+	default:
+		fmt.Println("可用命令：/new /save /list /resume <id> /fork [id]")
 	}
+	return nil
 }
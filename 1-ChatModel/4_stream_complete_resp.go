@@ -2,25 +2,28 @@ package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"io"
 	"log"
 	"os"
-	"strings"
+	"time"
 
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
 	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/streamx"
 )
 
+/*
+流式输出与完整响应:
+	只拼接 chunk.Content 会丢掉工具调用增量、finish reason、用量和推理内容。
+	streamx.Aggregator 用 schema.ConcatMessages 合并分片，得到与 Generate 相同的完整消息，并统计首 token 时间和生成速度。
+	streamx.Tee 把同一个流同时交给多个消费者（终端、文件、SSE 等），缓冲满时暂停读取，慢消费者不会导致内存无限增长。
+*/
+
 func main() {
 	ctx := context.Background()
 
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:  os.Getenv("DEEPSEEK_API_KEY"),
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-	})
+	chatModel, err := chatmodel.New(ctx, "")
 	if err != nil {
 		log.Fatalf("创建失败: %v", err)
 	}
@@ -29,31 +32,42 @@ func main() {
 		schema.UserMessage("请列举 5 个 Go 语言的特点"),
 	}
 
+	start := time.Now()
 	stream, err := chatModel.Stream(ctx, messages)
 	if err != nil {
 		log.Fatalf("流式生成失败: %v", err)
 	}
-	defer stream.Close()
-
-	var fullContent strings.Builder
-	fmt.Print("AI 回复: ")
 
-	for {
-		chunk, err := stream.Recv()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			log.Fatalf("接收失败: %v", err)
-		}
+	file, err := os.Create("response.md")
+	if err != nil {
+		log.Fatalf("创建文件失败: %v", err)
+	}
+	defer file.Close()
 
-		// 实时打印
-		fmt.Print(chunk.Content)
+	// 同时输出到终端、写入文件并合并完整响应
+	agg := streamx.NewAggregator(start)
+	fmt.Print("AI 回复: ")
+	err = streamx.Tee(stream, 8,
+		streamx.ContentWriter(os.Stdout),
+		streamx.ContentWriter(file),
+		agg.Add,
+	)
+	if err != nil {
+		log.Fatalf("接收失败: %v", err)
+	}
 
-		// 同时收集完整内容
-		fullContent.WriteString(chunk.Content)
+	response, err := agg.Message()
+	if err != nil {
+		log.Fatalf("合并响应失败: %v", err)
 	}
 
-	fmt.Println("\\n\\n======== 完整响应 ========")
-	fmt.Println(fullContent.String())
+	fmt.Println("\n\n======== 完整响应 ========")
+	fmt.Println(response.Content)
+	if response.ResponseMeta != nil {
+		fmt.Printf("\n结束原因: %s\n", response.ResponseMeta.FinishReason)
+		if usage := response.ResponseMeta.Usage; usage != nil {
+			fmt.Printf("Token: 输入 %d，输出 %d，总计 %d\n", usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+		}
+	}
+	fmt.Printf("统计: %s\n", agg.Stats())
 }
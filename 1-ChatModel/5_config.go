@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"time"
 
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
+	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/continuation"
+	"eino-tutorial/internal/sampling"
+	"eino-tutorial/internal/usage"
 )
 
 /*
@@ -18,31 +23,55 @@ import (
 	MaxTokens (最大Token数): 限制生成响应的最大长度，防止过长输出。
 	Stop (停止序列): 定义一组字符串，当生成文本包含这些字符串时，模型将停止生成。这有助于控制响应的结束点。
 	PresencePenalty (存在惩罚): 控制模型引入新话题的倾向。正值增加引入新话题的可能
+
+模型与采样参数统一定义在 configs/models.yaml 的 profile 中（precise / balanced / creative / code / translation / reasoner），
+示例通过 chatmodel.New(ctx, "<profile>") 创建 ChatModel，切换提供方或参数只需要改配置或环境变量。
+profile 通过 preset 引用 sampling 包中的采样预设，参数在创建模型时和每次调用前按模型能力表校验，
+超出上面的范围或模型不支持时直接返回 *sampling.Error，不会发给提供方。
+
+MaxTokens 较小时回答可能被截断（FinishReason 为 "length"），continuation.New 包装后会自动请求模型续写并拼接结果，
+续写次数用完仍被截断时 FinishReason 仍为 "length"。
+
+Token 用量由 usage.Ledger 通过回调统计，每个示例是一个 run，结束时输出汇总表格；超出预算时 ctx 会被取消。
 */
 
 func main() {
-	ctx := context.Background()
+	ledger := usage.New(&usage.Config{
+		Prices: usage.DefaultPrices(),
+		Budget: usage.Budget{MaxTokens: 20000},
+		OnRecord: func(rec *usage.Record) {
+			fmt.Printf("\n[用量] %s: 输入 %d（缓存 %d），输出 %d，总计 %d\n",
+				rec.Run, rec.Usage.PromptTokens, rec.Usage.CachedTokens, rec.Usage.CompletionTokens, rec.Usage.TotalTokens)
+		},
+	})
+	ctx, cancel := ledger.Bind(context.Background())
+	defer cancel()
 
 	// 示例1: 基础配置
 	fmt.Println("=== 示例1: 基础配置 ===")
-	basicExample(ctx)
+	basicExample(usage.WithRun(ctx, "basic"))
 
 	// 示例2: 高级配置
 	fmt.Println("\\n=== 示例2: 高级配置 ===")
-	advancedExample(ctx)
+	advancedExample(usage.WithRun(ctx, "advanced"))
 
 	// 示例3: 创意写作配置
 	fmt.Println("\\n=== 示例3: 创意写作配置 ===")
-	creativeExample(ctx)
+	creativeExample(usage.WithRun(ctx, "creative"))
+
+	// 示例4: 参数校验
+	fmt.Println("\n=== 示例4: 参数校验 ===")
+	validationExample(ctx)
+
+	fmt.Println("\n=== Token 使用统计 ===")
+	if err := ledger.Report().WriteTable(os.Stdout); err != nil {
+		log.Printf("输出报告失败: %v", err)
+	}
 }
 
-// 基础配置示例
+// 基础配置示例 - 使用默认 profile
 func basicExample(ctx context.Context) {
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:  os.Getenv("DEEPSEEK_API_KEY"),
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-	})
+	chatModel, err := chatmodel.New(ctx, "")
 	if err != nil {
 		log.Fatalf("创建失败: %v", err)
 	}
@@ -58,64 +87,46 @@ func basicExample(ctx context.Context) {
 	}
 
 	fmt.Printf("AI 响应: %s\\n", response.Content)
-	printTokenUsage(response)
 }
 
 // 高级配置示例 - 精确控制输出
 func advancedExample(ctx context.Context) {
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		// 基础配置
-		APIKey:  os.Getenv("DEEPSEEK_API_KEY"),
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-		Timeout: 30 * time.Second,
-
-		// 生成参数
-		Temperature: 0.7, // 控制输出随机性，范围 [0.0, 2.0]，越高越随机
-		TopP:        0.9, // 核采样参数，范围 [0.0, 1.0]，越低越聚焦
-		MaxTokens:   500, // 限制最大生成 token 数量，范围 [1, 8192]
-
-		// 停止序列 - 遇到这些文本时停止生成
-		Stop: []string{"\\n\\n", "总结:"},
-
-		// 惩罚参数 - 控制重复度
-		PresencePenalty:  0.6, // 存在惩罚，范围 [-2.0, 2.0]，正值增加新话题可能性
-		FrequencyPenalty: 0.5, // 频率惩罚，范围 [-2.0, 2.0]，正值减少重复词语
-	})
+	// advanced profile: 沿用 balanced 预设 Temperature 0.7（范围 [0.0, 2.0]）、TopP 0.9（范围 [0.0, 1.0]），
+	// 另外设置 Timeout 30s，以及惩罚参数控制重复度（范围都是 [-2.0, 2.0]）：
+	// PresencePenalty 0.6 正值增加新话题可能性，FrequencyPenalty 0.5 正值减少重复词语
+	cm, err := chatmodel.New(ctx, "advanced")
 	if err != nil {
 		log.Fatalf("创建失败: %v", err)
 	}
+	// 输出因 MaxTokens 被截断时自动续写，最多 3 次
+	chatModel := continuation.New(cm, &continuation.Config{MaxContinuations: 3})
 
 	messages := []*schema.Message{
 		schema.SystemMessage("你是一个专业的技术文档撰写专家"),
 		schema.UserMessage("详细介绍 Eino 框架的核心特性，包括架构、组件和优势"),
 	}
 
-	response, err := chatModel.Generate(ctx, messages)
+	// 单次调用可以通过 model.Option 覆盖 profile 中的参数
+	response, err := chatModel.Generate(ctx, messages,
+		model.WithMaxTokens(500), // 限制最大生成 token 数量，范围 [1, 8192]
+		// 停止序列 - 遇到这些文本时停止生成
+		model.WithStop([]string{"\\n\\n", "总结:"}),
+	)
 	if err != nil {
 		log.Fatalf("生成失败: %v", err)
 	}
 
 	fmt.Printf("AI 响应: %s\\n", response.Content)
-	printTokenUsage(response)
+	fmt.Printf("续写 %d 次\n", continuation.Continuations(response))
+	if continuation.Truncated(response) {
+		fmt.Println("续写次数已用完，回答仍不完整")
+	}
 }
 
 // 创意写作配置示例 - 高随机性
 func creativeExample(ctx context.Context) {
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:  os.Getenv("DEEPSEEK_API_KEY"),
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-
-		// 高温度设置，适合创意写作
-		Temperature: 1.2,  // 更高的随机性
-		TopP:        0.95, // 保留更多可能性
-		MaxTokens:   800,
-
-		// 减少重复惩罚，允许一定的重复（适合故事情节）
-		PresencePenalty:  0.3,
-		FrequencyPenalty: 0.3,
-	})
+	// creative profile: 高温度 1.2、TopP 0.95，并减少重复惩罚（适合故事情节）
+	chatModel, err := chatmodel.New(ctx, "creative")
 	if err != nil {
 		log.Fatalf("创建失败: %v", err)
 	}
@@ -125,24 +136,30 @@ func creativeExample(ctx context.Context) {
 		schema.UserMessage("创作一个关于 AI 框架变成超级英雄的有趣故事开头"),
 	}
 
-	response, err := chatModel.Generate(ctx, messages)
+	response, err := chatModel.Generate(ctx, messages, model.WithMaxTokens(800))
 	if err != nil {
 		log.Fatalf("生成失败: %v", err)
 	}
 
 	fmt.Printf("AI 响应: %s\\n", response.Content)
-	printTokenUsage(response)
 }
 
-// 打印 Token 使用情况
-func printTokenUsage(response *schema.Message) {
-	if response.ResponseMeta != nil && response.ResponseMeta.Usage != nil {
-		fmt.Printf("\\nToken 使用统计:\\n")
-		fmt.Printf("  输入 Token: %d\\n", response.ResponseMeta.Usage.PromptTokens)
-		fmt.Printf("  输出 Token: %d\\n", response.ResponseMeta.Usage.CompletionTokens)
-		fmt.Printf("  总计 Token: %d\\n", response.ResponseMeta.Usage.TotalTokens)
-		if response.ResponseMeta.Usage.PromptTokenDetails.CachedTokens > 0 {
-			fmt.Printf("  缓存 Token: %d\\n", response.ResponseMeta.Usage.PromptTokenDetails.CachedTokens)
+// 参数校验示例 - 超出范围的参数在调用前就会报错
+func validationExample(ctx context.Context) {
+	chatModel, err := chatmodel.New(ctx, "code")
+	if err != nil {
+		log.Fatalf("创建失败: %v", err)
+	}
+
+	// 单次调用覆盖预设中的参数，同样按模型能力表校验
+	_, err = chatModel.Generate(ctx, []*schema.Message{schema.UserMessage("你好")},
+		model.WithTemperature(2.5),
+		model.WithMaxTokens(10000),
+	)
+	var serr *sampling.Error
+	if errors.As(err, &serr) {
+		for _, problem := range serr.Problems {
+			fmt.Printf("参数错误: %s\n", problem)
 		}
 	}
 }
@@ -5,35 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/cloudwego/eino-ext/components/model/deepseek"
 	"github.com/cloudwego/eino/schema"
-)
-
-func generateWithRetry(ctx context.Context, chatModel *deepseek.ChatModel, message []*schema.Message, maxRetries int) (*schema.Message, error) {
-	var lastErr error
-
-	for i := 0; i < maxRetries; i++ {
-		response, err := chatModel.Generate(ctx, message)
-		if err == nil {
-			return response, nil
-		}
-
-		lastErr = err
-		log.Printf("尝试第 %d (%d)次失败: %v", i+1, maxRetries, err)
-
-		// 指数退避等待
-		if i < maxRetries-1 {
-			backoff := time.Duration(1<<uint(i)) * time.Second
-			log.Printf("等待 %v 后重试...", backoff)
-			time.Sleep(backoff)
-		}
-	}
 
-	return nil, fmt.Errorf("所有 %d 次尝试均失败: %v", maxRetries, lastErr)
-}
+	"eino-tutorial/internal/retry"
+)
 
 func main() {
 	ctx := context.Background()
@@ -44,6 +24,8 @@ func main() {
 		BaseURL: "https://api.deepseek.com",
 		// 设置超时
 		Timeout: 30 * time.Second,
+		// SDK 会丢掉响应头，用 retry.Transport 把 429 / 503 的 Retry-After 附在错误上
+		HTTPClient: &http.Client{Transport: retry.Transport(nil)},
 	})
 	if err != nil {
 		log.Fatalf("创建失败: %v", err)
@@ -53,9 +35,26 @@ func main() {
 		schema.UserMessage("你好"),
 	}
 
-	// 使用重试机制生成响应
-	response, err := generateWithRetry(ctx, chatModel, message, 5)
+	// 使用重试中间件包装 ChatModel：
+	// 只重试 429 / 5xx / 网络错误，认证失败、参数错误直接返回；Stream 在首个分片前失败也会重试
+	retryModel := retry.New(chatModel, &retry.Config{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		OnRetry: func(attempt int, err error, delay time.Duration) {
+			log.Printf("第 %d 次尝试失败: %v，等待 %v 后重试...", attempt, err, delay)
+		},
+	})
+
+	// 整个调用（包括重试等待）最多 2 分钟
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	response, err := retryModel.Generate(ctx, message)
 	if err != nil {
+		// 重试返回的错误保留了完整的错误链
+		if code, ok := retry.StatusCode(err); ok && code == 401 {
+			log.Fatalf("API Key 无效: %v", err)
+		}
 		if errors.Is(err, context.DeadlineExceeded) {
 			log.Fatalf("请求超时: %v", err)
 		}
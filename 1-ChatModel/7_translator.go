@@ -6,47 +6,66 @@ import (
 	"log"
 	"os"
 
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
-	"github.com/cloudwego/eino/schema"
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/translate"
 )
 
-type Translator struct {
-	chatModel *deepseek.ChatModel
-}
+/*
+文档翻译:
+	长文档按段落切块（超长段落按句子切），多个块并发翻译后按原顺序拼接。
+	代码块原样保留不发送；行内代码、链接、{{name}} / %s 等占位符替换成标记，译文中必须原样出现。
+	术语表中的术语必须使用指定译法，译文不符合要求时把问题反馈给模型重新翻译。
+	源语言自动识别，与目标语言相同时直接返回原文。
 
-func NewTranslator(apiKey string) (*Translator, error) {
-	ctx := context.Background()
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:      apiKey,
-		Model:       "deepseek-chat",
-		Temperature: 0.3,
-	})
-	if err != nil {
-		return nil, err
-	}
-	return &Translator{chatModel: chatModel}, nil
-}
+用法:
+	go run 1-ChatModel/7_translator.go                      # 翻译内置示例
+	go run 1-ChatModel/7_translator.go README.md English    # 翻译文件
+*/
 
-func (t *Translator) Translate(ctx context.Context, text, targetLang string) (string, error) {
-	messages := []*schema.Message{
-		schema.SystemMessage(fmt.Sprintf("你是一个专业的翻译助手。请将用户输入的文本翻译成%s，只返回翻译结果，不要添加任何解释。", targetLang)),
-		schema.UserMessage(text),
-	}
+const sampleDoc = "# Eino 快速开始\n\n" +
+	"Eino 是一个用 Go 编写的 AI 应用开发框架。ChatModel 负责与大模型交互，Tool 让模型可以调用外部能力。\n\n" +
+	"使用 `chatmodel.New` 创建模型，详见 [文档](https://www.cloudwego.io/zh/docs/eino/)。\n\n" +
+	"```go\n" +
+	"cm, err := chatmodel.New(ctx, \"\") // 这里的注释不会被翻译\n" +
+	"```\n\n" +
+	"提示词中的变量写作 {{name}}，日志格式为 %s。\n"
+
+func main() {
+	ctx := context.Background()
 
-	response, err := t.chatModel.Generate(ctx, messages)
+	chatModel, err := chatmodel.New(ctx, "precise")
 	if err != nil {
-		return "", err
+		log.Fatalf("创建模型失败: %v", err)
 	}
 
-	return response.Content, nil
-}
-
-func main() {
-	translator, err := NewTranslator(os.Getenv("OPENAI_API_KEY"))
+	translator, err := translate.New(&translate.Config{
+		Model: chatModel,
+		Glossary: translate.Glossary{
+			"ChatModel": "ChatModel",
+			"提示词":       "prompt",
+			"大模型":       "LLM",
+		},
+		ChunkSize:   1500,
+		Concurrency: 4,
+	})
 	if err != nil {
 		log.Fatalf("创建翻译器失败: %v", err)
 	}
 
+	// 翻译文件
+	if len(os.Args) == 3 {
+		data, err := os.ReadFile(os.Args[1])
+		if err != nil {
+			log.Fatalf("读取文件失败: %v", err)
+		}
+		result, err := translator.Translate(ctx, string(data), os.Args[2])
+		if err != nil {
+			log.Fatalf("翻译失败: %v", err)
+		}
+		fmt.Println(result)
+		return
+	}
+
 	// 测试翻译
 	texts := []struct {
 		content string
@@ -55,14 +74,16 @@ func main() {
 		{"Hello, how are you?", "中文"},
 		{"Eino 是一个强大的 AI 开发框架", "English"},
 		{"Les roses sont rouges", "中文"},
+		{sampleDoc, "English"},
 	}
 
 	for _, item := range texts {
-		result, err := translator.Translate(context.Background(), item.content, item.target)
+		result, err := translator.Translate(ctx, item.content, item.target)
 		if err != nil {
 			log.Printf("翻译失败: %v", err)
 			continue
 		}
-		fmt.Printf("原文: %s\\n翻译: %s\\n\\n", item.content, result)
+		fmt.Printf("原文（%s）: %s\n翻译: %s\n\n",
+			translate.LanguageName(translate.Detect(item.content)), item.content, result)
 	}
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/fallback"
+)
+
+/*
+故障转移与路由:
+	多个后端按顺序尝试，当前后端限流（429）、服务端错误（5xx）、网络错误或超时时自动切换到下一个。
+	路由规则按请求特征选择后端顺序，例如带工具的请求只走支持工具调用的模型，长上下文请求优先走长上下文模型。
+	后端可以是 configs/models.yaml 中任意 profile，把 backup 换成其它提供方的 profile 即可跨厂商容灾。
+*/
+
+func main() {
+	ctx := context.Background()
+
+	newBackend := func(profile string, timeout time.Duration) *fallback.Backend {
+		cm, err := chatmodel.New(ctx, profile)
+		if err != nil {
+			log.Fatalf("创建 %s 失败: %v", profile, err)
+		}
+		return &fallback.Backend{Name: profile, Model: cm, Timeout: timeout}
+	}
+
+	router, err := fallback.New(&fallback.Config{
+		// 默认顺序：balanced -> precise -> reasoner
+		Backends: []*fallback.Backend{
+			newBackend("balanced", 30*time.Second),
+			newBackend("precise", 30*time.Second),
+			newBackend("reasoner", 2*time.Minute),
+		},
+		Rules: []*fallback.Rule{
+			// 带工具的请求：推理模型不参与
+			{Name: "tools", Match: fallback.HasTools(), Backends: []string{"balanced", "precise"}},
+			// 长上下文请求：优先推理模型
+			{Name: "long-context", Match: fallback.LongContext(8000), Backends: []string{"reasoner", "balanced"}},
+		},
+		OnFallback: func(from string, err error) {
+			log.Printf("后端 %s 失败，切换到下一个: %v", from, err)
+		},
+	})
+	if err != nil {
+		log.Fatalf("创建路由失败: %v", err)
+	}
+
+	// 回调中 RunInfo.Name 即为实际调用的后端
+	handler := callbacks.NewHandlerBuilder().
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			if out := model.ConvCallbackOutput(output); out != nil && out.TokenUsage != nil {
+				fmt.Printf("[回调] 后端 %s 使用 %d tokens\n", info.Name, out.TokenUsage.TotalTokens)
+			}
+			return ctx
+		}).
+		Build()
+	ctx = callbacks.InitCallbacks(ctx, &callbacks.RunInfo{Name: "router"}, handler)
+
+	messages := []*schema.Message{
+		schema.UserMessage("用一句话介绍 Eino 框架"),
+	}
+
+	response, err := router.Generate(ctx, messages)
+	if err != nil {
+		log.Fatalf("生成失败: %v", err)
+	}
+	servedBy, _ := fallback.ServedBy(response)
+	fmt.Printf("AI 响应（由 %s 提供）: %s\n", servedBy, response.Content)
+}
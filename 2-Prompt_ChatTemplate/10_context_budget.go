@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/budget"
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/memory"
+	"eino-tutorial/internal/tokens"
+)
+
+/*
+上下文窗口预算：模板中的少样本示例、对话历史和检索到的文档都可能很长，
+超出窗口时按声明的优先级裁剪：先丢弃靠后的示例，再丢弃最早的对话轮次（可以压缩成摘要），
+最后丢弃相似度低的文档。系统提示词和当前问题不在可裁剪的变量中，总是原样发送。
+
+为了演示，这里把窗口设得很小；实际使用时窗口大小来自 tokens.Lookup 或 profile 的 context_window。
+*/
+
+func main() {
+	ctx := context.Background()
+
+	template := prompt.FromMessages(schema.FString,
+		schema.SystemMessage("你是一个 Go 语言助手。回答时参考下面的资料，资料中没有的内容请说明。\n\n资料:\n{docs}"),
+		schema.MessagesPlaceholder("examples", false),
+		schema.MessagesPlaceholder("history", false),
+		schema.UserMessage("{question}"),
+	)
+	vars := map[string]any{
+		"examples": []*schema.Message{
+			schema.UserMessage("切片和数组有什么区别？"),
+			schema.AssistantMessage("数组长度固定，是值类型；切片是对底层数组的引用，长度可变。", nil),
+			schema.UserMessage("map 是并发安全的吗？"),
+			schema.AssistantMessage("不是。并发读写需要加锁，或者使用 sync.Map。", nil),
+		},
+		"history": []*schema.Message{
+			schema.UserMessage("我在写一个爬虫，想同时抓取很多页面。"),
+			schema.AssistantMessage("可以为每个页面启动一个 goroutine，用 sync.WaitGroup 等待全部完成，并用带缓冲的 channel 限制并发数。", nil),
+			schema.UserMessage("抓取结果怎么汇总？"),
+			schema.AssistantMessage("每个 goroutine 把结果发送到同一个 channel，由主 goroutine 接收并汇总，所有任务完成后关闭 channel。", nil),
+			schema.UserMessage("如果某个页面超时了呢？"),
+			schema.AssistantMessage("给每个请求设置 context.WithTimeout，超时后记录错误并继续处理其它页面。", nil),
+		},
+		"docs": []*schema.Document{
+			{Content: "context 包用于在 goroutine 之间传递取消信号、截止时间和请求范围的值。" + strings.Repeat("调用链上的每个函数都应该接收 ctx 参数。", 3)},
+			{Content: "errgroup 在 WaitGroup 的基础上增加了错误传播：任一任务返回错误时取消其它任务。"},
+			{Content: "time.Ticker 按固定间隔触发，可以配合 select 实现限速。"},
+		},
+		"question": "能不能在第一个错误出现时就停止所有抓取？",
+	}
+
+	tk := tokens.Lookup("deepseek", "deepseek-chat")
+	fmt.Printf("估算参数 %s，上下文窗口 %d tokens\n", tk.Name, tk.ContextWindow)
+
+	// 1. 不同窗口大小下的裁剪结果
+	for _, limit := range []int{1000, 400, 350, 280, 200, 150} {
+		b, err := budget.New(&budget.Config{
+			Tokenizer: tk,
+			Limit:     limit,
+			Reserve:   100,
+			Parts: []budget.Part{
+				{Key: "examples", Kind: budget.FewShot},
+				{Key: "history", Kind: budget.History},
+				{Key: "docs", Kind: budget.Context, Format: budget.JoinDocuments},
+			},
+		})
+		if err != nil {
+			log.Fatalf("创建 Budgeter 失败: %v", err)
+		}
+		res, err := b.Fit(ctx, template, vars)
+		fmt.Printf("\n=== 窗口 %d（可用 %d）===\n", limit, b.Budget())
+		if err != nil {
+			// 裁掉所有可裁剪内容后仍然放不下
+			fmt.Printf("失败: %v\n", err)
+			continue
+		}
+		fmt.Printf("约 %d tokens，%d 条消息，丢弃: %v\n", res.Tokens, len(res.Messages), res.Dropped)
+	}
+
+	// 2. 丢弃的历史压缩成摘要后再请求模型
+	chatModel, err := chatmodel.New(ctx, "balanced")
+	if err != nil {
+		log.Fatalf("创建失败: %v", err)
+	}
+	b, err := budget.New(&budget.Config{
+		Tokenizer: tk,
+		Limit:     330,
+		Reserve:   100,
+		Parts: []budget.Part{
+			{Key: "examples", Kind: budget.FewShot},
+			{Key: "history", Kind: budget.History},
+			{Key: "docs", Kind: budget.Context, Format: budget.JoinDocuments},
+		},
+		Summarize: memory.Summarizer(chatModel),
+	})
+	if err != nil {
+		log.Fatalf("创建 Budgeter 失败: %v", err)
+	}
+	res, err := b.Fit(ctx, template, vars)
+	if err != nil {
+		log.Fatalf("构造提示词失败: %v", err)
+	}
+	fmt.Printf("\n=== 使用摘要 ===\n约 %d tokens，丢弃: %v，摘要: %v\n", res.Tokens, res.Dropped, res.Summarized)
+	for _, m := range res.Messages {
+		fmt.Printf("[%s] %s\n", m.Role, m.Content)
+	}
+
+	resp, err := chatModel.Generate(ctx, res.Messages)
+	if err != nil {
+		log.Fatalf("生成失败: %v", err)
+	}
+	fmt.Printf("\nAI: %s\n", resp.Content)
+}
@@ -4,48 +4,130 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
-	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/promptrender"
 )
 
+/*
+复杂变量直接把 Go 结构体交给模板，不需要手工拼成 map[string]any：
+	- 字段名由 prompt 标签决定（没有时用 json 标签或字段名），prompt:"-" 的字段不会出现在模板中
+	- 嵌套结构体渲染为对象，切片渲染为列表，time.Time 渲染为 RFC3339 文本
+	- GoTemplate / Jinja2 模板中可以用循环、条件以及 join、truncate、json 过滤器
+*/
+
 type UserProfile struct {
-	Name      string
-	Age       int
-	Interests []string
-	VIPLevel  int
+	Name      string     `prompt:"name"`
+	Age       int        `prompt:"age"`
+	Interests []string   `prompt:"interests"`
+	VIPLevel  int        `prompt:"vip_level"`
+	History   []BookRead `prompt:"history"`
+	Phone     string     `prompt:"-"` // 隐私信息不进入提示词
+}
+
+type BookRead struct {
+	Title  string    `prompt:"title"`
+	Rating int       `prompt:"rating"`
+	ReadAt time.Time `prompt:"read_at"`
+}
+
+// Document 检索到的文档
+type Document struct {
+	Title   string  `json:"title"`
+	Source  string  `json:"source"`
+	Content string  `json:"content"`
+	Score   float64 `json:"score"`
 }
 
 func main() {
 	ctx := context.Background()
 
-	template := prompt.FromMessages(
-		schema.FString,
-		schema.SystemMessage("你是一个个性化推荐系统"),
-		schema.UserMessage("用户信息：姓名：{name}，年龄：{age}，兴趣：{interests}，VIP等级：{vip_level}。请根据这些信息推荐三本书籍。"),
-	)
-
 	//准备用户数据
 	user := UserProfile{
 		Name:      "张伟",
 		Age:       28,
 		Interests: []string{"科技", "历史", "旅行"},
 		VIPLevel:  3,
+		History: []BookRead{
+			{Title: "人类简史", Rating: 5, ReadAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)},
+			{Title: "三体", Rating: 4, ReadAt: time.Date(2024, 5, 20, 0, 0, 0, 0, time.Local)},
+		},
+		Phone: "13800000000",
 	}
 
-	variables := map[string]any{
-		"name":      user.Name,
-		"age":       user.Age,
-		"interests": fmt.Sprintf("%v", user.Interests),
-		"vip_level": user.VIPLevel,
+	// 1. GoTemplate：结构体字段、条件和循环
+	goTemplate, err := promptrender.FromMessages(
+		schema.GoTemplate,
+		schema.SystemMessage("你是一个个性化推荐系统"),
+		schema.UserMessage(`用户信息：姓名：{{.user.name}}，年龄：{{.user.age}}，兴趣：{{.user.interests | join "、"}}
+{{- if ge .user.vip_level 3}}
+该用户是高级会员（VIP{{.user.vip_level}}），可以推荐新书和精装版。
+{{- end}}
+{{- with .user.history}}
+最近读过：
+{{- range .}}
+- 《{{.title}}》评分 {{.rating}}
+{{- end}}
+{{- end}}
+请根据这些信息推荐三本书籍。`),
+	)
+	if err != nil {
+		log.Fatalf("创建模板失败: %v", err)
 	}
 
-	message, err := template.Format(ctx, variables)
+	message, err := goTemplate.Format(ctx, map[string]any{"user": user})
 	if err != nil {
 		log.Fatalf("template format err: %v", err)
 	}
+	fmt.Println("=== GoTemplate ===")
+	printMessages(message)
+
+	// 2. Jinja2：渲染检索到的文档列表
+	docs := []Document{
+		{Title: "Eino 编排入门", Source: "docs/chain.md", Score: 0.92,
+			Content: "Chain 是 Eino 中最简单的编排方式，按顺序把多个组件连接起来，前一个组件的输出作为后一个组件的输入。"},
+		{Title: "Graph 进阶", Source: "docs/graph.md", Score: 0.81,
+			Content: "Graph 支持分支、循环和并行，适合需要根据中间结果决定下一步的复杂流程。"},
+	}
+	ragTemplate, err := promptrender.FromMessages(
+		schema.Jinja2,
+		schema.SystemMessage("请只根据参考资料回答问题，并注明引用的编号。用户画像：{{ user | json }}"),
+		schema.UserMessage(`参考资料：
+{% for doc in docs -%}
+[{{ loop.index }}] {{ doc.title }}（{{ doc.source }}，相关度 {{ "%.2f"|format(doc.score) }}）
+{{ doc.content | truncate(30) }}
+{% else -%}
+（没有检索到相关资料）
+{% endfor %}
+问题：{{ question }}`),
+	)
+	if err != nil {
+		log.Fatalf("创建模板失败: %v", err)
+	}
+
+	message, err = ragTemplate.Format(ctx, map[string]any{
+		"user":     user,
+		"docs":     docs,
+		"question": "Chain 和 Graph 有什么区别？",
+	})
+	if err != nil {
+		log.Fatalf("template format err: %v", err)
+	}
+	fmt.Println("=== Jinja2 ===")
+	printMessages(message)
+
+	// 3. 整个结构体作为变量表
+	vars, err := promptrender.Vars(user)
+	if err != nil {
+		log.Fatalf("转换变量失败: %v", err)
+	}
+	fmt.Printf("=== 变量 ===\n%v\n", vars)
+}
 
-	for _, msg := range message {
-		fmt.Printf("[%s] %s\\n", msg.Role, msg.Content)
+func printMessages(messages []*schema.Message) {
+	for _, msg := range messages {
+		fmt.Printf("[%s] %s\n", msg.Role, msg.Content)
 	}
 }
@@ -3,113 +3,121 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
-	"github.com/cloudwego/eino/components/prompt"
-	"github.com/cloudwego/eino/schema"
 	"log"
 	"os"
-)
+	"strings"
+	"time"
 
-// PromptTemplate 提示词管理模板
-type PromptTemplate struct{}
+	"github.com/cloudwego/eino/schema"
 
-func (p *PromptTemplate) Translator(sourceLang, targetLang string) prompt.ChatTemplate {
-	return prompt.FromMessages(
-		schema.FString,
-		schema.SystemMessage(fmt.Sprintf(
-			"你是一个专业的翻译助手。请将%s翻译成%s。\\n"+
-				"要求：\\n"+
-				"1. 保持原文的语义和风格。\\n"+
-				"2. 使用地道的表达方式。\\n"+
-				"3. 只返回结果，不要添加解释。",
-			sourceLang, targetLang,
-		)),
-		schema.UserMessage("{text}"),
-	)
-}
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/codeassist"
+	"eino-tutorial/internal/prompts"
+	"eino-tutorial/internal/review"
+)
 
-// 代码审核模板
-func (p *PromptTemplate) CodeReview(language string) prompt.ChatTemplate {
-	return prompt.FromMessages(
-		schema.FString,
-		schema.SystemMessage(fmt.Sprintf(
-			"你是一个专业的%s开发专家。请审核以下代码。\\n"+
-				"要求：\\n"+
-				"1. 检查代码的正确性和效率。\\n"+
-				"2. 提出改进建议。\\n"+
-				"3. 只返回结果，不要添加解释。",
-			language,
-		)),
-		schema.UserMessage("请审核以下代码: \\n\n```{language}\\n{code}\\n```"),
-	)
-}
+/*
+可复用的提示词放在 configs/prompts 目录下的 YAML / Markdown 文件中，不需要改 Go 代码就能调整：
+	translator.yaml / translator-v1.1.yaml  翻译助手的两个版本
+	code_review.md / code_review-v1.1.md    代码审核（Markdown 格式），1.1 附上本地静态分析的诊断
+	tech_interview.yaml                     技术面试官
 
-// 技术面试官模板
-func (p *PromptTemplate) TechInterview(position, level string) prompt.ChatTemplate {
-	return prompt.FromMessages(
-		schema.FString,
-		schema.SystemMessage(fmt.Sprintf(
-			"你是一个%s职位的技术面试官，负责面试%s级别职位。\\n"+
-				"要求：\\n"+
-				"1. 提出与职位相关的技术问题。\\n"+
-				"2. 根据回答进行深入追问。\\n"+
-				"3. 只返回问题，不要添加解释。",
-			position, level,
-		)),
-		schema.UserMessage("候选人回答: {answer}\\n\n请评估并追问。"),
-	)
-}
+每个文件声明名称、版本、说明、带类型和默认值的变量以及各角色的消息，prompts.Registry 按名称和版本返回
+prompt.ChatTemplate。设置 PROMPTS_WATCH=1 后会轮询目录，修改文件后下一次 Format 使用新内容。
+*/
 
 func main() {
 	ctx := context.Background()
 
-	// 创建 ChatModel
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:  os.Getenv("DEEPSEEK_API_KEY"),
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-	})
+	chatModel, err := chatmodel.New(ctx, "")
 	if err != nil {
 		log.Fatalf("创建失败: %v", err)
 	}
 
-	// 创建提示词模板管理器
-	templates := &PromptTemplate{}
+	// 加载提示词模板库
+	registry, err := prompts.Load(prompts.DefaultDir)
+	if err != nil {
+		log.Fatalf("加载提示词失败: %v", err)
+	}
+	for _, name := range registry.Names() {
+		fmt.Printf("模板 %s，版本 %v\n", name, registry.Versions(name))
+	}
+
+	if os.Getenv("PROMPTS_WATCH") != "" {
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go registry.Watch(watchCtx, time.Second, func(err error) {
+			log.Printf("重新加载提示词失败，继续使用旧版本: %v", err)
+		})
+	}
 
-	// 使用翻译模板
+	generate := func(name, version string, vars map[string]any) {
+		template, err := registry.Get(name, version)
+		if err != nil {
+			log.Fatalf("获取模板失败: %v", err)
+		}
+		messages, err := template.Format(ctx, vars)
+		if err != nil {
+			log.Fatalf("格式化失败: %v", err)
+		}
+		response, err := chatModel.Generate(ctx, messages)
+		if err != nil {
+			log.Fatalf("生成失败: %v", err)
+		}
+		fmt.Printf("AI 回答：\n%s\n", response.Content)
+	}
+
+	// 使用翻译模板：指定版本，source / target 使用默认值
 	fmt.Println("=== 翻译示例 ===")
-	translatorTemplate := templates.Translator("中文", "英文")
-	messages, _ := translatorTemplate.Format(ctx, map[string]any{
+	generate("translator", "1.0.0", map[string]any{
 		"text": "你好，欢迎使用我们的翻译服务！",
 	})
-	response, err := chatModel.Generate(ctx, messages)
-	if err != nil {
-		log.Fatalf("生成失败: %v", err)
-	}
-	fmt.Printf("AI 回答：\\n%s\\n", response.Content)
 
-	// 使用代码审核模板
-	fmt.Println("=== 代码审核示例 ===")
-	codeReviewTemplate := templates.CodeReview("Go")
-	messages, _ = codeReviewTemplate.Format(ctx, map[string]any{
-		"language": "Go",
-		"code":     "package main\\n\\nfunc main() {\\n    println(\"Hello, World!\")\\n}",
+	// 不指定版本时使用最新版本，1.1.0 增加了术语表和对话历史
+	fmt.Println("=== 翻译示例（最新版本）===")
+	generate("translator", "", map[string]any{
+		"glossary": "编排 => orchestration",
+		"text":     "Eino 提供了强大的编排能力。",
+		"history": []*schema.Message{
+			schema.UserMessage("组件"),
+			schema.AssistantMessage("component", nil),
+		},
 	})
-	response, err = chatModel.Generate(ctx, messages)
+
+	// 使用代码审核模板：1.1.0 先用 go vet、复杂度统计和 gofmt 分析代码，把诊断作为审核的依据
+	fmt.Println("=== 代码审核示例 ===")
+	code := "package main\n\nimport \"fmt\"\n\nfunc main() {\n    fmt.Printf(\"%d\\n\", \"Hello, World!\")\n}\n"
+	analysis, err := review.Analyze(ctx, []codeassist.File{{Path: "main.go", Content: code}}, nil)
 	if err != nil {
-		log.Fatalf("生成失败: %v", err)
+		log.Fatalf("静态分析失败: %v", err)
+	}
+	notes := "无"
+	if len(analysis.Diagnostics) > 0 {
+		var sb strings.Builder
+		for _, d := range analysis.Diagnostics {
+			fmt.Fprintf(&sb, "%s:%d [%s/%s] %s\n", d.File, d.Line, d.Severity, d.Source, d.Message)
+		}
+		notes = sb.String()
 	}
-	fmt.Printf("AI 回答：\\n%s\\n", response.Content)
+	fmt.Printf("静态分析结果：\n%s\n", notes)
+	generate("code_review", "", map[string]any{
+		"language": "Go",
+		"analysis": notes,
+		"code":     code,
+	})
 
 	// 使用技术面试官模板
 	fmt.Println("=== 技术面试官示例 ===")
-	interviewTemplate := templates.TechInterview("后端开发工程师", "中级")
-	messages, _ = interviewTemplate.Format(ctx, map[string]any{
+	generate("tech_interview", "", map[string]any{
 		"answer": "我有3年的Go语言开发经验，熟悉微服务架构。",
 	})
-	response, err = chatModel.Generate(ctx, messages)
+
+	// 变量类型不符或缺少必填变量时，Format 直接返回错误
+	fmt.Println("=== 变量校验示例 ===")
+	template, err := registry.Get("tech_interview", "")
 	if err != nil {
-		log.Fatalf("生成失败: %v", err)
+		log.Fatalf("获取模板失败: %v", err)
 	}
-	fmt.Printf("AI 回答：\\n%s\\n", response.Content)
+	_, err = template.Format(ctx, map[string]any{"level": 3})
+	fmt.Printf("错误: %v\n", err)
 }
@@ -3,45 +3,70 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
-	"github.com/cloudwego/eino/components/prompt"
-	"github.com/cloudwego/eino/schema"
 	"log"
 	"os"
+
+	"github.com/cloudwego/eino-ext/components/embedding/ark"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/fewshot"
+	"eino-tutorial/internal/structured"
 )
 
+// Sentiment 情感分析结果，jsonschema 标签中的 enum 和范围会在解码后校验
+type Sentiment struct {
+	Label      string `json:"label" jsonschema:"description=情感倾向,enum=正面,enum=负面,enum=中性"`
+	Confidence int    `json:"confidence" jsonschema:"description=置信度,minimum=0,maximum=100"`
+}
+
 func main() {
 	ctx := context.Background()
 
-	// 创建 Few-Shot 模板
-	template := prompt.FromMessages(
-		schema.FString,
-		schema.SystemMessage("你是一个情感分析助手。请分析文本的情感倾向，返回格式：情感：[正面/负面/中性] | 置信度：[0-100]。"),
-
-		// 提供示例
-		schema.UserMessage("这个产品非常好，我很喜欢！"),
-		schema.AssistantMessage("情感：正面 | 置信度：95", nil),
+	// 示例库放在 JSONL 文件中，补充标注数据不需要改代码
+	examples, err := fewshot.LoadExamples("configs/fewshot/sentiment.jsonl")
+	if err != nil {
+		log.Fatalf("加载示例失败: %v", err)
+	}
 
-		schema.UserMessage("服务态度差，体验很糟糕。"),
-		schema.AssistantMessage("情感：负面 | 置信度：90", nil),
+	// 每个问题用 MMR 选出 3 个相关且互不重复的示例，示例总长度不超过 200 token
+	selector, err := fewshot.New(ctx, &fewshot.Config{
+		Embedder:  newEmbedder(ctx),
+		K:         3,
+		Strategy:  fewshot.MMR,
+		MaxTokens: 200,
+	}, examples...)
+	if err != nil {
+		log.Fatalf("创建示例选择器失败: %v", err)
+	}
 
-		schema.UserMessage("质量一般，没有特别出色的地方。"),
-		schema.AssistantMessage("情感：中性 | 置信度：80", nil),
+	// 创建 Few-Shot 模板，选中的示例以 user / assistant 消息对的形式放入 examples 占位符
+	template := selector.Template(prompt.FromMessages(
+		schema.FString,
+		schema.SystemMessage("你是一个情感分析助手。请分析文本的情感倾向，给出情感（正面/负面/中性）和 0-100 的置信度。"),
+		schema.MessagesPlaceholder(fewshot.DefaultKey, false),
 
 		// 实际分析文本
 		schema.UserMessage("{text}"),
-	)
+	), "text")
 
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:      os.Getenv("DEEPSEEK_API_KEY"),
-		Model:       "deepseek-chat",
-		BaseURL:     "https://api.deepseek.com",
-		Temperature: 0.8,
-	})
+	chatModel, err := chatmodel.New(ctx, "precise")
 	if err != nil {
 		log.Fatalf("创建失败: %v", err)
 	}
 
+	// 按 Sentiment 的 Schema 要求模型输出 JSON，不合法时最多修复 2 次
+	// 也可以用 structured.ModeTool 强制模型调用一个以 Schema 为参数的工具
+	extractor, err := structured.New[Sentiment](&structured.Config{
+		Model: chatModel,
+		Mode:  structured.ModeJSON,
+	})
+	if err != nil {
+		log.Fatalf("创建结构化输出失败: %v", err)
+	}
+
 	// 要分析的文本
 	testTexts := []string{
 		"这个框架的文档写的很详细，上手很快",
@@ -56,10 +81,31 @@ func main() {
 		if err != nil {
 			log.Fatalf("格式化失败: %v", err)
 		}
-		response, err := chatModel.Generate(ctx, messages)
+		fmt.Println("选中的示例:")
+		for _, m := range messages[1 : len(messages)-1] {
+			if m.Role == schema.User {
+				fmt.Printf("  %s\n", m.Content)
+			}
+		}
+		result, err := extractor.Generate(ctx, messages)
 		if err != nil {
 			log.Fatalf("生成失败: %v", err)
 		}
-		fmt.Printf("文本: %s\\n分析结果: %s\\n\\n", text, response.Content)
+		fmt.Printf("文本: %s\n情感: %s，置信度: %d\n\n", text, result.Label, result.Confidence)
+	}
+}
+
+// newEmbedder 配置了 ARK_EMBEDDING_MODEL 时使用 ARK Embedding，否则退回到按字面相似度计算的 n-gram 向量
+func newEmbedder(ctx context.Context) embedding.Embedder {
+	if model := os.Getenv("ARK_EMBEDDING_MODEL"); model != "" {
+		embedder, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
+			APIKey: os.Getenv("EINO_API_KEY"),
+			Model:  model,
+		})
+		if err != nil {
+			log.Fatalf("创建 ARK Embedding 模型失败: %v", err)
+		}
+		return embedder
 	}
+	return &fewshot.NGramEmbedder{}
 }
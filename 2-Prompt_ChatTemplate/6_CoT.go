@@ -2,12 +2,16 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
+	"log"
+
 	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/schema"
-	"log"
-	"os"
+
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/consistency"
+	"eino-tutorial/internal/reasoning"
 )
 
 func main() {
@@ -21,15 +25,12 @@ func main() {
 			"2. 分析已知信息；列出相关事实"+
 			"3. 制定解决方案；描述解决问题的方法"+
 			"4. 逐步推理；详细说明每一步的逻辑"+
-			"5. 得出结论；给出最终答案"),
+			"5. 得出结论；给出最终答案"+
+			"最后单独一行写“最终答案：”加上答案。"),
 		schema.UserMessage("{problem}"),
 	)
 
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:  os.Getenv("DEEPSEEK_API_KEY"),
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-	})
+	chatModel, err := chatmodel.New(ctx, "")
 	if err != nil {
 		log.Fatalf("创建失败: %v", err)
 	}
@@ -49,5 +50,52 @@ func main() {
 		log.Fatalf("生成失败: %v", err)
 	}
 
-	fmt.Printf("AI 回答：\\n%s\\n", response.Content)
+	fmt.Printf("AI 回答：\n%s\n", response.Content)
+
+	// 自洽性：以较高的温度并发采样多条推理路径，取出每条的最终答案投票，一致率低说明答案不可靠。
+	// Arithmetic 用本地求值器核对推理中的算式和最终答案，算错的路径不参与投票
+	runner, err := consistency.New(&consistency.Config{
+		Model:     chatModel,
+		N:         5,
+		Extractor: consistency.Number,
+		Verifier:  &consistency.Arithmetic{Expr: "240 / (60 + 80)"},
+	})
+	if err != nil {
+		log.Fatalf("创建失败: %v", err)
+	}
+	result, err := runner.Run(ctx, messages)
+	if err != nil && !errors.Is(err, consistency.ErrNoAnswer) {
+		log.Fatalf("采样失败: %v", err)
+	}
+
+	fmt.Printf("\n=== 自洽性投票 ===\n")
+	for _, s := range result.Samples {
+		if s.Err != "" {
+			fmt.Printf("路径 %d：答案 %q，不计票（%s）\n", s.Index+1, s.Answer, s.Err)
+		} else {
+			fmt.Printf("路径 %d：答案 %s\n", s.Index+1, s.Answer)
+		}
+	}
+	fmt.Printf("投票：%v\n", result.Votes)
+	fmt.Printf("最终答案：%s，一致率 %.0f%%（%d/%d 条路径有效）\n",
+		result.Answer, result.Agreement*100, result.Valid(), len(result.Samples))
+
+	// 推理模型自带思维链：不需要在提示词中要求分步骤，思考过程与最终回答分开返回
+	reasoner, err := chatmodel.New(ctx, "reasoner")
+	if err != nil {
+		log.Fatalf("创建推理模型失败: %v", err)
+	}
+	response, err = reasoner.Generate(ctx, []*schema.Message{schema.UserMessage(problem)})
+	if err != nil {
+		log.Fatalf("生成失败: %v", err)
+	}
+
+	fmt.Printf("\n=== 推理模型 ===\n")
+	fmt.Printf("思考过程：\n%s\n\n", reasoning.Of(response))
+	fmt.Printf("最终回答：\n%s\n", response.Content)
+	if meta := response.ResponseMeta; meta != nil && meta.Usage != nil {
+		// deepseek 没有单独上报推理 token 时，按思考过程估算
+		fmt.Printf("输出 token: %d（其中推理 %d）\n",
+			meta.Usage.CompletionTokens, meta.Usage.CompletionTokensDetails.ReasoningTokens)
+	}
 }
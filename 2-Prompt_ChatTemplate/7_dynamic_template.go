@@ -3,73 +3,108 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
-	"github.com/cloudwego/eino/components/prompt"
-	"github.com/cloudwego/eino/schema"
 	"log"
-	"os"
-)
 
-type ConversationStyle string
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
 
-const (
-	StyleProfessional ConversationStyle = "professional"
-	StyleCasual       ConversationStyle = "casual"
-	StyleFriendly     ConversationStyle = "friendly"
-	StyleFormal       ConversationStyle = "formal"
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/persona"
 )
 
-func createDynamicTemplate(style ConversationStyle, domain string) prompt.ChatTemplate {
-	var systemMessage string
-
-	switch style {
-	case StyleProfessional:
-		systemMessage = fmt.Sprintf("你是一个专业且高效的%s专家，提供准确且简洁的信息。", domain)
-	case StyleCasual:
-		systemMessage = fmt.Sprintf("你是一个随和且易于接近的%s专家，喜欢用轻松的语气与用户交流。", domain)
-	case StyleFriendly:
-		systemMessage = fmt.Sprintf("你是一个友好且乐于助人的%s专家，喜欢用温暖和鼓励的语气与用户交流。", domain)
-	case StyleFormal:
-		systemMessage = fmt.Sprintf("你是一个正式且尊重礼仪的%s专家，使用专业且恰当的语言与用户交流。", domain)
-	default:
-		systemMessage = fmt.Sprintf("你是一个%s专家，提供信息和帮助。", domain)
-	}
-	return prompt.FromMessages(
-		schema.FString,
-		schema.SystemMessage(systemMessage),
-		schema.UserMessage("{query}"),
-	)
-}
+/*
+用可组合的特征拼出人设：风格、领域、受众、输出语言、篇幅和格式互相独立，
+每个维度选一个特征，渲染成系统提示词。特征可以在运行时登记，也可以从 configs/personas/traits.yaml 加载。
+同一个人设既可以加到任意 ChatTemplate 前面，也可以作为 ChatModelAgent 的 Instruction。
+*/
 
 func main() {
 	ctx := context.Background()
 
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:  os.Getenv("DEEPSEEK_API_KEY"),
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-	})
+	chatModel, err := chatmodel.New(ctx, "balanced")
 	if err != nil {
 		log.Fatalf("创建失败: %v", err)
 	}
 
-	// 示例：使用不同的对话风格和领域
-	styles := []ConversationStyle{StyleProfessional, StyleCasual, StyleFriendly, StyleFormal}
-	domain := "医疗健康"
+	// 1. 运行时扩展特征：代码中登记，或从文件加载
+	if err := persona.Register(&persona.Trait{
+		Dimension:   persona.Style,
+		Name:        "socratic",
+		Description: "苏格拉底式提问",
+		Text:        "不要直接给出答案，先用一两个问题引导用户思考，再给出提示。",
+	}); err != nil {
+		log.Fatalf("登记特征失败: %v", err)
+	}
+	if err := persona.LoadFile("configs/personas/traits.yaml"); err != nil {
+		log.Fatalf("加载特征失败: %v", err)
+	}
+	for _, dim := range persona.Default.Dimensions() {
+		fmt.Printf("%-9s", dim)
+		for _, t := range persona.Default.Traits(dim) {
+			fmt.Printf(" %s(%s)", t.Name, t.Description)
+		}
+		fmt.Println()
+	}
+
+	// 2. 基础人设，只替换风格这一个维度
+	base := &persona.Persona{
+		Role: "医疗健康专家",
+		Traits: map[persona.Dimension]string{
+			persona.Domain:   "medical",
+			persona.Audience: "beginner",
+			persona.Length:   "normal",
+			persona.Language: "zh",
+		},
+		Vars: map[string]any{"domain": "医疗健康"},
+	}
+	question := prompt.FromMessages(schema.FString, schema.UserMessage("{query}"))
 	query := "请解释一下高血压的预防措施。"
 
-	for _, style := range styles {
-		template := createDynamicTemplate(style, domain)
-		messages, err := template.Format(ctx, map[string]any{
-			"query": query,
-		})
+	for _, style := range []string{"professional", "casual", "friendly", "formal", "socratic"} {
+		template := persona.Template(question, base.With(persona.Style, style))
+		messages, err := template.Format(ctx, map[string]any{"query": query})
 		if err != nil {
 			log.Fatalf("格式化失败: %v", err)
 		}
+		fmt.Printf("\n==================== 风格：%s ====================\n", style)
+		fmt.Printf("系统提示词：\n%s\n\n", messages[0].Content)
+
 		response, err := chatModel.Generate(ctx, messages)
 		if err != nil {
 			log.Fatalf("生成失败: %v", err)
 		}
-		fmt.Printf("风格：%s\\nAI 回答：\\n%s\\n\\n", style, response.Content)
+		fmt.Printf("AI 回答：\n%s\n", response.Content)
+	}
+
+	// 3. 同一套特征用于 Agent：老年人、分步骤、附免责声明
+	elderly := base.With(persona.Audience, "elderly").With(persona.Format, "steps").With("safety", "disclaimer")
+	instruction, err := persona.Instruction(ctx, elderly, map[string]any{"max_steps": 3})
+	if err != nil {
+		log.Fatalf("渲染人设失败: %v", err)
+	}
+	agent, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
+		Name:        "HealthAssistant",
+		Description: "面向老年人的健康助手",
+		Instruction: instruction,
+		Model:       chatModel,
+	})
+	if err != nil {
+		log.Fatalf("创建 ChatModelAgent 失败: %v", err)
+	}
+
+	fmt.Printf("\n==================== Agent ====================\nInstruction：\n%s\n\n", instruction)
+	iter := adk.NewRunner(ctx, adk.RunnerConfig{Agent: agent}).Query(ctx, "血压计怎么用？")
+	for {
+		event, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if event.Err != nil {
+			log.Fatalf("运行 Agent 失败: %v", event.Err)
+		}
+		if event.Output != nil && event.Output.MessageOutput != nil && event.Output.MessageOutput.Message != nil {
+			fmt.Printf("AI 回答：\n%s\n", event.Output.MessageOutput.Message.Content)
+		}
 	}
 }
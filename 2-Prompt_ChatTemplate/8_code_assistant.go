@@ -3,113 +3,102 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
-	"github.com/cloudwego/eino/components/prompt"
-	"github.com/cloudwego/eino/schema"
 	"log"
+
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/codeassist"
 )
 
-type CodeAssistant struct {
-	chatModel *deepseek.ChatModel
-}
+/*
+代码助手（internal/codeassist）：
+	ExplainCode / OptimizeCode  解释、优化单个代码片段
+	ReviewFiles                 一次审查多个文件，返回带文件、行号、严重程度和修改建议的问题列表
+	Refactor                    按要求重构 Go 文件，结果经过 go/parser 和 gofmt 检查后以 unified diff 返回
+*/
 
-func NewCodeAssistant(apiKey string) (*CodeAssistant, error) {
-	ctx := context.Background()
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:  apiKey,
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-	})
-	if err != nil {
-		log.Fatalf("创建失败: %v", err)
-	}
-	return &CodeAssistant{
-		chatModel: chatModel,
-	}, nil
-}
+const dataGo = `package data
 
-func (ca *CodeAssistant) ExplainCode(ctx context.Context, code, language string) (string, error) {
-	template := prompt.FromMessages(
-		schema.FString,
-		schema.SystemMessage("你是一个专业的代码助手，擅长解释各种编程语言的代码片段。"),
-		schema.UserMessage("请解释以下{language}代码的功能和作用：\\n\n```{language}\\n{code}\\n```"),
-	)
-	messages, err := template.Format(ctx, map[string]any{
-		"language": language,
-		"code":     code,
-	})
-	if err != nil {
-		log.Fatalf("格式化失败: %v", err)
-	}
-	response, err := ca.chatModel.Generate(ctx, messages)
-	if err != nil {
-		log.Fatalf("生成失败: %v", err)
+func getData() []int {
+	data := []int{}
+	for i := 0; i < 1000; i++ {
+		data = append(data, i)
 	}
-	return response.Content, nil
+	return data
 }
+`
 
-func (ca *CodeAssistant) OptimizeCode(ctx context.Context, code, language string) (string, error) {
-	template := prompt.FromMessages(
-		schema.FString,
-		schema.SystemMessage("你是一个专业的代码助手，擅长优化各种编程语言的代码片段。请你从以下方面进行优化："+
-			"1. 提高代码性能；"+
-			"2. 增强代码可读性；"+
-			"3. 遵循最佳实践。"+
-			"4. 错误处理和边界情况。"),
-		schema.UserMessage("请优化以下{language}代码，提高其性能和可读性：\\n\n```{language}\\n{code}\\n```"),
-	)
-	messages, err := template.Format(ctx, map[string]any{
-		"language": language,
-		"code":     code,
-	})
-	if err != nil {
-		log.Fatalf("格式化失败: %v", err)
-	}
-	response, err := ca.chatModel.Generate(ctx, messages)
-	if err != nil {
-		log.Fatalf("生成失败: %v", err)
-	}
-	return response.Content, nil
+const cacheGo = `package data
 
+import "sync"
+
+var cache = map[string][]int{}
+var mu sync.Mutex
+
+func Get(key string) []int {
+	if v, ok := cache[key]; ok {
+		return v
+	}
+	mu.Lock()
+	v := getData()
+	cache[key] = v
+	mu.Unlock()
+	return v
 }
+`
 
 func main() {
-	assistant, err := NewCodeAssistant("YOUR_DEEPSEEK_API_KEY")
+	ctx := context.Background()
+
+	chatModel, err := chatmodel.New(ctx, "code")
 	if err != nil {
-		log.Fatalf("初始化代码助手失败: %v", err)
+		log.Fatalf("创建失败: %v", err)
 	}
-
-	code := `func getData() []int {
-	data := []int{}
-	for i := 0; i < 1000; i++ {
-		data = append(data, i)
+	assistant, err := codeassist.New(chatModel)
+	if err != nil {
+		log.Fatalf("初始化代码助手失败: %v", err)
 	}
-	return data
-}`
 
-	// 解释代码
+	// 1. 解释代码
 	fmt.Println("=== 代码解释 ===")
-	explanation, err := assistant.ExplainCode(context.Background(), code, "Go")
+	explanation, err := assistant.ExplainCode(ctx, dataGo, "Go")
 	if err != nil {
 		log.Fatalf("代码解释失败: %v", err)
 	}
-	fmt.Printf("代码解释：\\n%s\\n", explanation)
+	fmt.Printf("%s\n\n", explanation)
 
-	// 优化代码
+	// 2. 优化代码
 	fmt.Println("=== 代码优化 ===")
-	optimizedCode, err := assistant.OptimizeCode(context.Background(), code, "Go")
+	optimized, err := assistant.OptimizeCode(ctx, dataGo, "Go")
 	if err != nil {
 		log.Fatalf("代码优化失败: %v", err)
 	}
-	fmt.Printf("优化后的代码：\\n%s\\n", optimizedCode)
+	fmt.Printf("%s\n\n", optimized)
+
+	// 3. 多文件审查
+	fmt.Println("=== 多文件审查 ===")
+	files := []codeassist.File{
+		{Path: "data/data.go", Content: dataGo},
+		{Path: "data/cache.go", Content: cacheGo},
+	}
+	findings, err := assistant.ReviewFiles(ctx, files)
+	if err != nil {
+		log.Fatalf("代码审查失败: %v", err)
+	}
+	for _, f := range findings {
+		fmt.Printf("%s:%d [%s/%s] %s\n", f.File, f.Line, f.Severity, f.Category, f.Message)
+		if f.Suggestion != "" {
+			fmt.Printf("    建议: %s\n", f.Suggestion)
+		}
+	}
+	fmt.Println()
 
-	// 解释优化后的代码
-	fmt.Println("=== 优化后代码解释 ===")
-	explanationOptimized, err := assistant.ExplainCode(context.Background(), optimizedCode, "Go")
+	// 4. 重构并输出补丁，可以直接用 git apply 应用
+	fmt.Println("=== 重构 ===")
+	result, err := assistant.Refactor(ctx, files[1], "修复并发读写 map 的数据竞争，并改用 sync.RWMutex")
 	if err != nil {
-		log.Fatalf("优化后代码解释失败: %v", err)
+		log.Fatalf("重构失败: %v", err)
 	}
-	fmt.Printf("优化后代码解释：\\n%s\\n", explanationOptimized)
+	fmt.Printf("%s\n\n%s", result.Summary, result.Diff)
 }
 
 /*
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/eval"
+	"eino-tutorial/internal/structured"
+)
+
+/*
+用评测代替肉眼比较提示词：
+	configs/eval/sentiment.jsonl     情感分析，用 exact（标签是否正确）和 schema（输出格式）打分
+	configs/eval/health_style.jsonl  不同对话风格，用 regex（关键点）和 judge（评审模型按评分标准）打分
+
+每个数据集都用多个模板变体跑一遍，输出各变体的平均分以及每个用例的输出差异。
+仓库中没有附带录制文件：先设置 EINO_REPLAY_MODE=record 运行一次，把回答录制到 testdata/replay，
+之后设置 EINO_REPLAY_MODE=replay 即可离线重跑；修改提示词后使用 auto 模式，只有变化的请求会重新请求模型。
+*/
+
+func main() {
+	ctx := context.Background()
+
+	chatModel, err := chatmodel.New(ctx, "precise")
+	if err != nil {
+		log.Fatalf("创建失败: %v", err)
+	}
+
+	// 1. 情感分析：零样本 vs 少样本
+	cases, err := eval.LoadDataset("configs/eval/sentiment.jsonl")
+	if err != nil {
+		log.Fatalf("加载数据集失败: %v", err)
+	}
+	system := schema.SystemMessage(`你是一个情感分析助手。请分析文本的情感倾向，只输出 JSON：{{"label": "正面/负面/中性", "confidence": 0-100}}`)
+	report, err := eval.Run(ctx, &eval.Config{
+		Model: chatModel,
+		Variants: []eval.Variant{
+			{Name: "zero-shot", Template: prompt.FromMessages(schema.FString, system, schema.UserMessage("{text}"))},
+			{Name: "few-shot", Template: prompt.FromMessages(schema.FString, system,
+				schema.UserMessage("这个产品非常好，我很喜欢！"),
+				schema.AssistantMessage(`{{"label": "正面", "confidence": 95}}`, nil),
+				schema.UserMessage("服务态度差，体验很糟糕。"),
+				schema.AssistantMessage(`{{"label": "负面", "confidence": 90}}`, nil),
+				schema.UserMessage("质量一般，没有特别出色的地方。"),
+				schema.AssistantMessage(`{{"label": "中性", "confidence": 80}}`, nil),
+				schema.UserMessage("{text}"),
+			)},
+		},
+		Metrics: []eval.Metric{
+			// 只比较 JSON 中的 label 字段
+			&eval.ExactMatch{Extract: sentimentLabel},
+			&eval.JSONSchema{},
+		},
+	}, cases)
+	if err != nil {
+		log.Fatalf("评测失败: %v", err)
+	}
+	printReport("情感分析", report)
+
+	// 2. 对话风格：用评审模型按评分标准打分
+	cases, err = eval.LoadDataset("configs/eval/health_style.jsonl")
+	if err != nil {
+		log.Fatalf("加载数据集失败: %v", err)
+	}
+	// 演示中评审也使用同一个模型，实际评测时最好换一个更强的模型，避免给自己的回答打高分
+	judge, err := eval.NewJudge(chatModel)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	styles := map[string]string{
+		"professional": "你是一个专业且高效的医疗健康专家，提供准确且简洁的信息。",
+		"casual":       "你是一个随和且易于接近的医疗健康专家，喜欢用轻松的语气与用户交流。",
+		"friendly":     "你是一个友好且乐于助人的医疗健康专家，喜欢用温暖和鼓励的语气与用户交流。",
+		"formal":       "你是一个正式且尊重礼仪的医疗健康专家，使用专业且恰当的语言与用户交流。",
+	}
+	var variants []eval.Variant
+	for _, name := range []string{"professional", "casual", "friendly", "formal"} {
+		variants = append(variants, eval.Variant{
+			Name:     name,
+			Template: prompt.FromMessages(schema.FString, schema.SystemMessage(styles[name]), schema.UserMessage("{query}")),
+		})
+	}
+	report, err = eval.Run(ctx, &eval.Config{
+		Model:    chatModel,
+		Variants: variants,
+		Metrics:  []eval.Metric{&eval.Regex{}, judge},
+	}, cases)
+	if err != nil {
+		log.Fatalf("评测失败: %v", err)
+	}
+	printReport("对话风格", report)
+	fmt.Printf("评审得分最高的风格: %s\n", report.Best("judge"))
+}
+
+// sentimentLabel 取出回答 JSON 中的 label
+func sentimentLabel(output string) string {
+	var v struct {
+		Label string `json:"label"`
+	}
+	if err := json.Unmarshal([]byte(structured.ExtractJSON(output)), &v); err != nil {
+		return output
+	}
+	return v.Label
+}
+
+func printReport(title string, report *eval.Report) {
+	fmt.Printf("==================== %s ====================\n", title)
+	if err := report.WriteTable(os.Stdout); err != nil {
+		log.Fatalf("输出报告失败: %v", err)
+	}
+	fmt.Println()
+	if err := report.WriteCases(os.Stdout); err != nil {
+		log.Fatalf("输出报告失败: %v", err)
+	}
+}
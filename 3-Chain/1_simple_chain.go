@@ -2,12 +2,12 @@ package main
 
 import (
 	"context"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
 	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 	"log"
-	"os"
+
+	"eino-tutorial/internal/chatmodel"
 )
 
 func main() {
@@ -21,11 +21,7 @@ func main() {
 	)
 
 	// 2. 创建 ChatModel
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:  os.Getenv("API_KEY"),
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-	})
+	chatModel, err := chatmodel.New(ctx, "")
 	if err != nil {
 		log.Fatalf("创建 ChatModel 失败: %v", err)
 	}
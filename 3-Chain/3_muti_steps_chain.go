@@ -3,23 +3,19 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
 	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 	"log"
-	"os"
 	"strings"
+
+	"eino-tutorial/internal/chatmodel"
 )
 
 func main() {
 	ctx := context.Background()
 
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:  os.Getenv("CHAT_MODEL_API_KEY"),
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-	})
+	chatModel, err := chatmodel.New(ctx, "")
 	if err != nil {
 		log.Fatalf("创建 ChatModel 失败: %v", err)
 	}
@@ -53,7 +49,7 @@ func main() {
 			template := prompt.FromMessages(
 				schema.FString,
 				schema.SystemMessage("你是一个专业的数据分析师。请根据提供的文本进行分析，并给出见解。"),
-				schema.UserMessage("请分析以下文本内容：\\n{text}"),
+				schema.UserMessage("请分析以下文本内容：\n{text}"),
 			)
 
 			analysisChain.AppendChatTemplate(template).AppendChatModel(chatModel)
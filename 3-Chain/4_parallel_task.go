@@ -3,22 +3,19 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
 	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 	"log"
-	"os"
+
+	"eino-tutorial/internal/chatmodel"
 )
 
 func main() {
 	cxt := context.Background()
 
-	chatModel, err := deepseek.NewChatModel(cxt, &deepseek.ChatModelConfig{
-		APIKey:  os.Getenv("CHAT_MODEL_API_KEY"),
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-	})
+	// 并行分支同时请求模型，受 configs/models.yaml 中 rate_limits 的限流保护，超出配额时排队
+	chatModel, err := chatmodel.New(cxt, "")
 	if err != nil {
 		log.Fatalf("创建 ChatModel 失败: %v", err)
 	}
@@ -114,5 +111,4 @@ func main() {
 	fmt.Printf("\\n关键词: %s\\n", result["keyword"])
 	fmt.Printf("情感分析: %s\\n", result["sentiment"])
 	fmt.Printf("摘要: %s\\n", result["summary"])
-}))
 }
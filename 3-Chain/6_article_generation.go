@@ -3,12 +3,13 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
-	"github.com/cloudwego/eino/components/prompt"
+	"log"
+
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
-	"log"
-	"os"
+
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/promptcheck"
 )
 
 type ArticleRequest struct {
@@ -20,15 +21,34 @@ type ArticleRequest struct {
 func main() {
 	ctx := context.Background()
 
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:  os.Getenv("CHAT_MODEL_API_KEY"),
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-	})
+	chatModel, err := chatmodel.New(ctx, "")
 	if err != nil {
 		log.Fatalf("创建 ChatModel 失败: %v", err)
 	}
 
+	// 提示词模板在创建时检查：变量名与 Format 传入的键一致，并且没有多写反斜杠的 \n
+	outlineTemplate, err := promptcheck.FromMessages(schema.FString, []string{"topic", "keywords"},
+		schema.SystemMessage("你是一个专业的内容策划师。请根据主题和关键词生成文章大纲。"),
+		schema.UserMessage("主题: {topic}\n关键词: {keywords}\n\n请生成一个包含主要章节和小节的文章大纲。"),
+	)
+	if err != nil {
+		log.Fatalf("大纲模板有误: %v", err)
+	}
+	draftTemplate, err := promptcheck.FromMessages(schema.FString, []string{"outline"},
+		schema.SystemMessage("你是一个专业的内容写作专家。请根据提供的大纲扩写成完整的文章。"),
+		schema.UserMessage("大纲: {outline}\n\n请根据大纲撰写一篇详细的文章，目标字数为800字。"),
+	)
+	if err != nil {
+		log.Fatalf("扩写模板有误: %v", err)
+	}
+	polishTemplate, err := promptcheck.FromMessages(schema.FString, []string{"draft"},
+		schema.SystemMessage("你是一个专业的编辑。请对文章进行修改和润色，使其更流畅易读。"),
+		schema.UserMessage("文章初稿: {draft}\n\n请对文章进行修改和润色。"),
+	)
+	if err != nil {
+		log.Fatalf("润色模板有误: %v", err)
+	}
+
 	// 构建文章生成流水线
 	chain := compose.NewChain[ArticleRequest, string]()
 	chain.
@@ -36,21 +56,18 @@ func main() {
 		AppendLambda(compose.InvokableLambda(func(ctx context.Context, req ArticleRequest) (string, error) {
 			fmt.Println("=== 步骤1: 生成文章大纲 ===")
 
-			template := prompt.FromMessages(
-				schema.FString,
-				schema.SystemMessage("你是一个专业的内容策划师。请根据主题和关键词生成文章大纲。"),
-				schema.UserMessage("主题: {topic}\\n关键词: {keywords}\\n\n请生成一个包含主要章节和小节的文章大纲。"),
-			)
-
-			message, _ := template.Format(ctx, map[string]any{
+			message, err := outlineTemplate.Format(ctx, map[string]any{
 				"topic":    req.Topic,
 				"keywords": req.Keywords,
 			})
+			if err != nil {
+				return "", err
+			}
 			response, err := chatModel.Generate(ctx, message)
 			if err != nil {
 				return "", err
 			}
-			fmt.Printf("生成的大纲:\\n%s\\n\n", response.Content)
+			fmt.Printf("生成的大纲:\n%s\n\n", response.Content)
 
 			return response.Content, nil
 		})).
@@ -59,20 +76,17 @@ func main() {
 		AppendLambda(compose.InvokableLambda(func(ctx context.Context, outline string) (string, error) {
 			fmt.Println("=== 步骤2: 扩写内容 ===")
 
-			template := prompt.FromMessages(
-				schema.FString,
-				schema.SystemMessage("你是一个专业的内容写作专家。请根据提供的大纲扩写成完整的文章。"),
-				schema.UserMessage("大纲: {outline}\\n\n请根据大纲撰写一篇详细的文章，目标字数为800字。"),
-			)
-
-			message, _ := template.Format(ctx, map[string]any{
+			message, err := draftTemplate.Format(ctx, map[string]any{
 				"outline": outline,
 			})
+			if err != nil {
+				return "", err
+			}
 			response, err := chatModel.Generate(ctx, message)
 			if err != nil {
 				return "", err
 			}
-			fmt.Printf("初稿完成, 字数: %d\\n\n", len(response.Content))
+			fmt.Printf("初稿完成, 字数: %d\n\n", len(response.Content))
 
 			return response.Content, nil
 		})).
@@ -81,20 +95,17 @@ func main() {
 		AppendLambda(compose.InvokableLambda(func(ctx context.Context, draft string) (string, error) {
 			fmt.Println("=== 步骤3: 修改润色 ===")
 
-			template := prompt.FromMessages(
-				schema.FString,
-				schema.SystemMessage("你是一个专业的编辑。请对文章进行修改和润色，使其更流畅易读。"),
-				schema.UserMessage("文章初稿: {draft}\\n\n请对文章进行修改和润色。"),
-			)
-
-			message, _ := template.Format(ctx, map[string]any{
+			message, err := polishTemplate.Format(ctx, map[string]any{
 				"draft": draft,
 			})
+			if err != nil {
+				return "", err
+			}
 			response, err := chatModel.Generate(ctx, message)
 			if err != nil {
 				return "", err
 			}
-			fmt.Printf("润色完成, 字数: %d\\n\n", len(response.Content))
+			fmt.Printf("润色完成, 字数: %d\n\n", len(response.Content))
 
 			return response.Content, nil
 		})).
@@ -104,7 +115,7 @@ func main() {
 			fmt.Println("=== 步骤4: 格式化输出 ===")
 
 			// 添加 Markdown 格式
-			formatted := fmt.Sprintf("# 文章生成结果\\n\\n%s", article)
+			formatted := fmt.Sprintf("# 文章生成结果\n\n%s", article)
 			return formatted, nil
 		}))
 
@@ -125,5 +136,5 @@ func main() {
 		log.Fatalf("运行 Chain 失败: %v", err)
 	}
 
-	fmt.Printf("=== 最终文章输出 ===\\n%s\\n", result)
+	fmt.Printf("=== 最终文章输出 ===\n%s\n", result)
 }
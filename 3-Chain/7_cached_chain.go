@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/cache"
+	"eino-tutorial/internal/chatmodel"
+)
+
+/*
+响应缓存:
+	调试 Chain 时同样的提示词会被反复执行，缓存可以省下时间和费用。
+	key 由规范化后的消息、工具定义和生成参数计算，命中时不请求模型，流式调用会把缓存的响应切片回放。
+	所有示例都可以通过环境变量启用缓存，无需改代码：
+		EINO_CACHE=disk go run 3-Chain/1_simple_chain.go    # 缓存保存在 .cache/eino，跨进程复用
+		EINO_CACHE=memory EINO_CACHE_TTL=10m ...            # 进程内 LRU，10 分钟过期
+*/
+
+func main() {
+	ctx := context.Background()
+
+	chatTemplate := prompt.FromMessages(
+		schema.FString,
+		schema.SystemMessage("你是一个{role}"),
+		schema.UserMessage("{question}"),
+	)
+
+	chatModel, err := chatmodel.New(ctx, "")
+	if err != nil {
+		log.Fatalf("创建 ChatModel 失败: %v", err)
+	}
+
+	// 1. 为模型增加进程内 LRU 缓存
+	cached, err := cache.New(chatModel, &cache.Config{
+		Backend: cache.NewLRU(100),
+		TTL:     time.Hour,
+	})
+	if err != nil {
+		log.Fatalf("创建缓存失败: %v", err)
+	}
+
+	chain := compose.NewChain[map[string]any, *schema.Message]()
+	chain.
+		AppendChatTemplate(chatTemplate).
+		AppendChatModel(cached)
+
+	runnable, err := chain.Compile(ctx)
+	if err != nil {
+		log.Fatalf("编译 Chain 失败: %v", err)
+	}
+
+	// 2. 通过回调观察命中情况
+	handler := callbacks.NewHandlerBuilder().
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			if status, ok := cache.Status(output); ok {
+				log.Printf("[回调] 缓存 %s", status)
+			}
+			return ctx
+		}).
+		Build()
+
+	input := map[string]any{
+		"role":     "专业的 Go 语言工程师",
+		"question": "请用一句话解释 Go 语言中的 goroutine 是什么？",
+	}
+
+	// 3. 相同输入运行两次，第二次命中缓存
+	for i := 1; i <= 2; i++ {
+		start := time.Now()
+		output, err := runnable.Invoke(ctx, input, compose.WithCallbacks(handler))
+		if err != nil {
+			log.Fatalf("运行 Chain 失败: %v", err)
+		}
+		log.Printf("第 %d 次（%v，命中: %v）: %s", i, time.Since(start).Round(time.Millisecond), cache.IsHit(output), output.Content)
+	}
+
+	// 4. 流式调用同样可以命中，缓存的响应会被切成多个分片
+	stream, err := runnable.Stream(ctx, input, compose.WithCallbacks(handler))
+	if err != nil {
+		log.Fatalf("流式运行失败: %v", err)
+	}
+	output, err := schema.ConcatMessageStream(stream)
+	if err != nil {
+		log.Fatalf("读取流失败: %v", err)
+	}
+	log.Printf("流式: %s", output.Content)
+
+	// 5. 跳过缓存强制请求模型，新的响应会刷新缓存
+	output, err = runnable.Invoke(ctx, input, compose.WithChatModelOption(cache.WithBypass()))
+	if err != nil {
+		log.Fatalf("运行 Chain 失败: %v", err)
+	}
+	log.Printf("跳过缓存: %s", output.Content)
+
+	stats := cached.Stats()
+	log.Printf("命中 %d 次，未命中 %d 次，跳过 %d 次", stats.Hits, stats.Misses, stats.Bypassed)
+}
@@ -9,7 +9,8 @@ import (
 	"github.com/cloudwego/eino/schema"
 )
 
-// CalculatorTool 计算器工具
+// CalculatorTool 计算器工具，手写 InvokableTool 的 Info 和 InvokableRun 两个方法；
+// 其它示例和命令行使用 internal/tools 中用 utils.NewTool 实现的 calculator
 type CalculatorTool struct{}
 
 // Info 返回工具信息
@@ -4,93 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"github.com/cloudwego/eino/components/tool"
-	"github.com/cloudwego/eino/schema"
 	"log"
-)
-
-// WeatherTool 天气查询工具
-type WeatherTool struct {
-	// 模拟天气预报
-	weatherData map[string]map[string]string
-}
-
-func NewWeatherTool() *WeatherTool {
-	return &WeatherTool{
-		weatherData: map[string]map[string]string{
-			"北京": {
-				"temperature": "25°C",
-				"condition":   "晴朗",
-				"humanity":    "40%",
-				"wind":        "北风3级",
-			},
-			"上海": {
-				"temperature": "28°C",
-				"condition":   "多云",
-				"humanity":    "60%",
-				"wind":        "东风2级",
-			},
-			"广州": {
-				"temperature": "30°C",
-				"condition":   "雷阵雨",
-				"humanity":    "80%",
-				"wind":        "南风4级",
-			},
-		},
-	}
-}
-
-func (t *WeatherTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
-	return &schema.ToolInfo{
-		Name: "get_weather",
-		Desc: "查询指定城市的当前天气信息。",
-		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
-			"city": {
-				Type:     "string",
-				Desc:     "要查询天气的城市名称，例如：北京、上海、广州。",
-				Required: true,
-			},
-		}),
-	}, nil
-}
-
-type WeatherParams struct {
-	City string `json:"city"`
-}
 
-func (t *WeatherTool) InvokableRun(ctx context.Context, argumentsInJSON string, ops ...tool.Option) (string, error) {
-	// 1. 解析参数
-	var params WeatherParams
-	if err := json.Unmarshal([]byte(argumentsInJSON), &params); err != nil {
-		return "", err
-	}
-
-	// 2. 查询天气
-	weather, exists := t.weatherData[params.City]
-	if !exists {
-		result := map[string]string{
-			"error": "未找到该城市的天气信息。",
-		}
-		resultJSON, _ := json.Marshal(result)
-		return string(resultJSON), nil
-	}
-
-	// 3. 返回结果
-	resultJSON, err := json.Marshal(weather)
-	if err != nil {
-		return "", err
-	}
-
-	return string(resultJSON), nil
-}
+	"eino-tutorial/internal/tools"
+)
 
 func main() {
 	ctx := context.Background()
-	weatherTool := NewWeatherTool()
+	// 天气查询工具（模拟数据），定义见 internal/tools/tools.go
+	weatherTool := tools.Weather()
 
-	cities := []string{"北京", "上海", "广州"}
+	cities := []string{"北京", "上海", "广州", "深圳"}
 	for _, city := range cities {
-		params := WeatherParams{City: city}
+		params := tools.WeatherParams{City: city}
 		paramsJSON, _ := json.Marshal(params)
 
 		result, err := weatherTool.InvokableRun(ctx, string(paramsJSON))
@@ -98,6 +24,6 @@ func main() {
 			log.Printf("调用天气工具失败: %v", err)
 			continue
 		}
-		fmt.Printf("%s 的天气: %s\\n", city, result)
+		fmt.Printf("%s 的天气: %s\n", city, result)
 	}
 }
@@ -3,58 +3,25 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
+	"os"
+
 	"github.com/cloudwego/eino-ext/components/model/deepseek"
 	"github.com/cloudwego/eino/components/model"
-	"github.com/cloudwego/eino/components/tool"
-	"github.com/cloudwego/eino/components/tool/utils"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
-	"log"
-	"os"
-	"time"
+
+	"eino-tutorial/internal/tools"
 )
 
 func main() {
 	ctx := context.Background()
 
-	// 1. 创建多个工具
-	// 计算工具
-	calculaor := utils.NewTool(
-		&schema.ToolInfo{
-			Name: "calculator",
-			Desc: "一个简单的计算器，支持加减乘除运算。",
-			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
-				"operation": {
-					Type:     "string",
-					Desc:     "运算类型: add(加), subtract(减), multiply(乘), divide(除)",
-					Required: true,
-				},
-			}),
-		},
-		func(ctx context.Context, params map[string]any) (string, error) {
-			// 简化实现: 只处理简单加法
-			return "30", nil
-		},
-	)
-
-	// 时间工具
-	timeTool := utils.NewTool(
-		&schema.ToolInfo{
-			Name: "get_current_time",
-			Desc: "获取当前时间，支持不同格式（date, time, datetime）。",
-			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
-				"format": {
-					Type:     "string",
-					Desc:     "时间格式: date(日期), time(时间), datetime(完整时间)",
-					Required: false,
-				},
-			}),
-		},
-		func(ctx context.Context, params map[string]any) (string, error) {
-			// 简化实现: 返回固定时间
-			return time.Now().Format("2006-01-02 15:04:05"), nil
-		},
-	)
+	// 1. 创建多个工具：计算器和时间工具，定义见 internal/tools/tools.go
+	toolList, err := tools.Get("calculator", "get_current_time")
+	if err != nil {
+		log.Fatalf("创建工具失败: %v", err)
+	}
 
 	// 2. 创建 ChatModel (支持 Function Calling)
 	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
@@ -68,23 +35,17 @@ func main() {
 
 	// 3. 创建 ToolsNode
 	toolsNode, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{
-		Tools: []tool.BaseTool{calculaor, timeTool},
+		Tools: toolList,
 	})
 	if err != nil {
 		log.Fatalf("创建 ToolsNode 失败: %v", err)
 	}
 
 	// 4. 获取工具信息列表
-	calcInfo, err := calculaor.Info(ctx)
+	toolsInfo, err := tools.Infos(ctx, toolList)
 	if err != nil {
-		log.Fatalf("获取计算器工具信息失败: %v", err)
+		log.Fatalf("获取工具信息失败: %v", err)
 	}
-	timeInfo, err := timeTool.Info(ctx)
-	if err != nil {
-		log.Fatalf("获取时间工具信息失败: %v", err)
-	}
-
-	toolsInfo := []*schema.ToolInfo{calcInfo, timeInfo}
 
 	// 测试多个场景
 	testCases := []string{
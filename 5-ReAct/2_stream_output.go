@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
 	"github.com/cloudwego/eino/compose"
@@ -12,18 +11,15 @@ import (
 	"github.com/cloudwego/eino/schema"
 	"io"
 	"log"
-	"os"
+
+	"eino-tutorial/internal/chatmodel"
 )
 
 func main() {
 	ctx := context.Background()
 
 	// 创建模型和工具
-	chatModel, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
-		APIKey:  os.Getenv("API_KEY"),
-		Model:   "deepseek-chat",
-		BaseURL: "https://api.deepseek.com",
-	})
+	chatModel, err := chatmodel.New(ctx, "")
 	if err != nil {
 		log.Fatalf("创建 ChatModel 失败: %v", err)
 	}
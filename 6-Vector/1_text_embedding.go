@@ -7,19 +7,26 @@ import (
 	"os"
 
 	"github.com/cloudwego/eino-ext/components/embedding/ark"
+
+	"eino-tutorial/internal/chatmodel"
 )
 
 func main() {
 	ctx := context.Background()
 
 	// 创建 ARK Embedding 模型
-	embedder, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
+	arkEmbedder, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
 		APIKey: os.Getenv("EINO_API_KEY"),
 		Model:  os.Getenv("ARK_EMBEDDING_MODEL"),
 	})
 	if err != nil {
 		log.Fatalf("创建 ARK Embedding 模型失败: %v", err)
 	}
+	// 与同一提供方的其它调用共享限流配额，见 configs/models.yaml 中的 rate_limits
+	embedder, err := chatmodel.LimitEmbedder(arkEmbedder, "ark")
+	if err != nil {
+		log.Fatalf("创建 Embedding 限流失败: %v", err)
+	}
 
 	// 待向量化的文本
 	texts := []string{
@@ -10,6 +10,8 @@ import (
 	"github.com/cloudwego/eino-ext/components/indexer/es8"
 	"github.com/cloudwego/eino/schema"
 	"github.com/elastic/go-elasticsearch/v8"
+
+	"eino-tutorial/internal/chatmodel"
 )
 
 const (
@@ -31,13 +33,18 @@ func main() {
 
 	// 2. 创建 Embedding 模型
 	// 创建 ARK Embedding 模型
-	embedder, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
+	arkEmbedder, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
 		APIKey: os.Getenv("ARK_API_KEY"),
 		Model:  os.Getenv("ARK_EMBEDDING_MODEL"),
 	})
 	if err != nil {
 		log.Fatalf("创建 ARK Embedding 模型失败: %v", err)
 	}
+	// 与同一提供方的其它调用共享限流配额，见 configs/models.yaml 中的 rate_limits
+	embedder, err := chatmodel.LimitEmbedder(arkEmbedder, "ark")
+	if err != nil {
+		log.Fatalf("创建 Embedding 限流失败: %v", err)
+	}
 
 	// 3. 创建 Indexer
 	indexer, err := es8.NewIndexer(ctx, &es8.IndexerConfig{
@@ -12,6 +12,8 @@ import (
 	"github.com/cloudwego/eino/schema"
 	"github.com/milvus-io/milvus-sdk-go/v2/client"
 	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+
+	"eino-tutorial/internal/chatmodel"
 )
 
 func main() {
@@ -42,13 +44,18 @@ func main() {
 	}
 
 	// 2. 创建 Embedding 模型
-	emb, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
+	arkEmbedder, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
 		APIKey: os.Getenv("MILVUS_API_KEY"),
 		Model:  os.Getenv("MILVUS_EMBEDDING_MODEL"),
 	})
 	if err != nil {
 		log.Fatalf("创建 ARK Embedding 模型失败: %v", err)
 	}
+	// 与同一提供方的其它调用共享限流配额，见 configs/models.yaml 中的 rate_limits
+	emb, err := chatmodel.LimitEmbedder(arkEmbedder, "ark")
+	if err != nil {
+		log.Fatalf("创建 Embedding 限流失败: %v", err)
+	}
 
 	// 创建 Indexer
 	// 注意: 默认配置使用二进制向量 (81920维度， HAMMING 度量), 不适用于 ARK Embedding 的浮点向量
@@ -10,6 +10,8 @@ import (
 	milvusretriever "github.com/cloudwego/eino-ext/components/retriever/milvus"
 	"github.com/milvus-io/milvus-sdk-go/v2/client"
 	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+
+	"eino-tutorial/internal/chatmodel"
 )
 
 func main() {
@@ -41,13 +43,18 @@ func main() {
 	log.Printf("集合存在: %s, 开始检索", collectionName)
 
 	// 创建 Embedding 模型
-	embedder, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
+	arkEmbedder, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
 		APIKey: os.Getenv("ARK_API_KEY"),
 		Model:  os.Getenv("ARK_EMBEDDING_MODEL"),
 	})
 	if err != nil {
 		log.Fatalf("创建 ARK Embedding 模型失败: %v", err)
 	}
+	// 与同一提供方的其它调用共享限流配额，见 configs/models.yaml 中的 rate_limits
+	embedder, err := chatmodel.LimitEmbedder(arkEmbedder, "ark")
+	if err != nil {
+		log.Fatalf("创建 Embedding 限流失败: %v", err)
+	}
 
 	// 创建 Milvus Retriever
 	searchParams, err := entity.NewIndexAUTOINDEXSearchParam(1)
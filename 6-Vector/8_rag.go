@@ -3,16 +3,21 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
-	"github.com/cloudwego/eino/schema"
 	"log"
 	"os"
 
 	"github.com/cloudwego/eino-ext/components/embedding/ark"
 	milvusindexer "github.com/cloudwego/eino-ext/components/indexer/milvus"
+	"github.com/cloudwego/eino-ext/components/model/deepseek"
 	milvusretriever "github.com/cloudwego/eino-ext/components/retriever/milvus"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
 	"github.com/milvus-io/milvus-sdk-go/v2/client"
 	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+
+	"eino-tutorial/internal/budget"
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/tokens"
 )
 
 const (
@@ -37,13 +42,18 @@ func main() {
 	defer cli.Close()
 
 	// 2. 创建 Embedding 模型
-	embedder, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
+	arkEmbedder, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
 		APIKey: os.Getenv("ARK_API_KEY"),
 		Model:  os.Getenv("ARK_EMBEDDING_MODEL"),
 	})
 	if err != nil {
 		log.Fatalf("创建 ARK Embedding 模型失败: %v", err)
 	}
+	// 与同一提供方的其它调用共享限流配额，见 configs/models.yaml 中的 rate_limits
+	embedder, err := chatmodel.LimitEmbedder(arkEmbedder, "ark")
+	if err != nil {
+		log.Fatalf("创建 Embedding 限流失败: %v", err)
+	}
 
 	// 3. 检测向量维度
 	testVector, err := embedder.EmbedStrings(ctx, []string{"test"})
@@ -194,18 +204,27 @@ func main() {
 	}
 	fmt.Println()
 
-	// 9.2 构建上下文
-	contextText := "相关文档内容:\n\n"
-	for i, doc := range retrieveDocs {
-		contextText += fmt.Sprintf("%d: %s\n", i+1, doc.Content)
+	// 9.2 构建上下文：文档按相似度排列，超出上下文窗口时从相似度最低的开始丢弃
+	budgeter, err := budget.New(&budget.Config{
+		Tokenizer: tokens.Lookup("deepseek", "deepseek-chat"),
+		Parts:     []budget.Part{{Key: "docs", Kind: budget.Context, Format: budget.JoinDocuments}},
+	})
+	if err != nil {
+		log.Fatalf("创建 Budgeter 失败: %v", err)
 	}
+	template := prompt.FromMessages(schema.FString,
+		schema.SystemMessage("你是一个知识丰富的 AI 助手。请根据以下提供的相关文档内容，回答用户的问题。如果文档中没有相关信息，请如实告知用户你无法回答该问题。\n\n相关文档内容:\n{docs}"),
+		schema.UserMessage("{query}"),
+	)
+	fitted, err := budgeter.Fit(ctx, template, map[string]any{"docs": retrieveDocs, "query": userQuery})
+	if err != nil {
+		log.Fatalf("构造提示词失败: %v", err)
+	}
+	fmt.Printf("提示词约 %d tokens（可用 %d），丢弃文档 %d 篇\n", fitted.Tokens, fitted.Budget, fitted.Dropped["docs"])
 
 	// 9.3 生成回答
 	fmt.Println("步骤2: 生成回答...")
-	message := []*schema.Message{
-		schema.SystemMessage(fmt.Sprintf(`你是一个知识丰富的 AI 助手。请根据以下提供的相关文档内容，回答用户的问题。如果文档中没有相关信息，请如实告知用户你无法回答该问题。%s`, contextText)),
-		schema.UserMessage(fmt.Sprintf(userQuery)),
-	}
+	message := fitted.Messages
 	response, err := chatModel.Generate(ctx, message)
 	if err != nil {
 		log.Fatalf("生成回答失败: %v", err)
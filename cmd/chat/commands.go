@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/memory"
+	"eino-tutorial/internal/persona"
+	"eino-tutorial/internal/prompts"
+	"eino-tutorial/internal/sampling"
+	"eino-tutorial/internal/tools"
+)
+
+const helpText = `可用命令：
+  /model [profile]             查看或切换模型 profile
+  /system [人设 k=v ... | 文本] 查看或设置系统提示词，人设见 /system list
+  /temp [温度 [top_p] | 预设 | reset]
+                               查看或调整采样参数，预设见 configs/models.yaml
+  /save                        保存当前会话
+  /load [id]                   列出已保存的会话，或加载指定会话
+  /new                         开始新会话
+  /tools [on | off | 名称...]  查看、开启或关闭工具
+  /think [on | off]            查看上次的思考过程，或设置是否实时展开
+  /usage                       查看 token 用量
+  /exit                        退出`
+
+// builtinPersonas 用 persona 包的特征组合的人设；configs/prompts 中带系统消息的模板也可以作为人设，见 loadPersonas
+var builtinPersonas = map[string]*persona.Persona{
+	"assistant": {Traits: map[persona.Dimension]string{persona.Style: "friendly"}},
+	"expert": {
+		Role:   "专家",
+		Traits: map[persona.Dimension]string{persona.Domain: "expert", persona.Style: "professional"},
+	},
+}
+
+// chatPersona 一个可选的人设：persona 包的人设或 configs/prompts 中的模板
+type chatPersona struct {
+	persona  *persona.Persona
+	template *prompts.Template
+}
+
+// render 用 vars 覆盖默认值后渲染系统提示词
+func (p chatPersona) render(ctx context.Context, vars map[string]any) (string, error) {
+	if p.template != nil {
+		return p.template.System(ctx, vars)
+	}
+	return persona.Render(ctx, p.persona, vars)
+}
+
+// defaults 变量的默认值
+func (p chatPersona) defaults() map[string]any {
+	if p.template != nil {
+		return p.template.Defaults()
+	}
+	values := map[string]any{}
+	for dim, name := range p.persona.Traits {
+		if t, err := persona.Default.Lookup(dim, name); err == nil {
+			maps.Copy(values, t.Defaults)
+		}
+	}
+	maps.Copy(values, p.persona.Vars)
+	return values
+}
+
+// loadPersonas 返回内置人设和 configs/prompts 中最新版本带系统消息的模板，模板与内置人设同名时优先；
+// 模板目录加载失败时只使用内置人设
+func loadPersonas() map[string]chatPersona {
+	out := make(map[string]chatPersona, len(builtinPersonas))
+	for name, p := range builtinPersonas {
+		out[name] = chatPersona{persona: p}
+	}
+	registry, err := prompts.Load(prompts.DefaultDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "警告: 加载 %s 失败，只使用内置人设: %v\n", prompts.DefaultDir, err)
+		return out
+	}
+	for _, name := range registry.Names() {
+		t, err := registry.Lookup(name, "")
+		if err != nil {
+			continue
+		}
+		if slices.ContainsFunc(t.Messages, func(m prompts.Message) bool { return m.Role == schema.System }) {
+			out[name] = chatPersona{template: t}
+		}
+	}
+	return out
+}
+
+// renderPersona 用 vars 覆盖默认值后渲染人设的系统提示词
+func (c *chat) renderPersona(ctx context.Context, name string, vars map[string]any) (string, error) {
+	p, ok := c.personas[name]
+	if !ok {
+		return "", fmt.Errorf("未知的人设 %q，可用: %s", name, strings.Join(c.personaNames(), ", "))
+	}
+	system, err := p.render(ctx, vars)
+	if err != nil {
+		return "", fmt.Errorf("渲染人设 %s 失败: %w", name, err)
+	}
+	return system, nil
+}
+
+func (c *chat) personaNames() []string {
+	names := make([]string, 0, len(c.personas))
+	for name := range c.personas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handleCommand 处理斜杠命令，命令出错时只打印错误，不中断对话
+func (c *chat) handleCommand(ctx context.Context, line string) {
+	fields := strings.Fields(line)
+	cmd, args := fields[0], fields[1:]
+
+	var err error
+	switch cmd {
+	case "/model":
+		err = c.cmdModel(ctx, args)
+	case "/system":
+		err = c.cmdSystem(ctx, line, args)
+	case "/temp":
+		err = c.cmdTemp(args)
+	case "/save":
+		if err = c.mem.Save(ctx, c.session); err == nil {
+			fmt.Printf("已保存会话 %s\n", c.session.ID)
+		}
+	case "/load":
+		err = c.cmdLoad(ctx, args)
+	case "/new":
+		c.session = memory.NewSession(c.session.System)
+		fmt.Printf("已开始新会话 %s\n", c.session.ID)
+	case "/tools":
+		err = c.cmdTools(ctx, args)
+	case "/think":
+		c.cmdThink(args)
+	case "/usage":
+		err = c.ledger.Report().WriteTable(os.Stdout)
+	default:
+		fmt.Println(helpText)
+	}
+	if err != nil {
+		fmt.Printf("%s 失败: %v\n", cmd, err)
+	}
+}
+
+func (c *chat) cmdModel(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		for _, name := range c.cfg.Names() {
+			mark := " "
+			if name == c.profile {
+				mark = "*"
+			}
+			p, err := c.cfg.Profile(name)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s %-10s %s/%s\n", mark, name, p.Provider, p.Model)
+		}
+		return nil
+	}
+	if err := c.setModel(ctx, args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("已切换到 %s\n", c.profile)
+	return nil
+}
+
+// cmdSystem 第一个参数是人设名称时按模板渲染，其余参数为 key=value 变量；否则整行作为系统提示词
+func (c *chat) cmdSystem(ctx context.Context, line string, args []string) error {
+	if len(args) == 0 {
+		fmt.Println(c.session.System)
+		return nil
+	}
+	if args[0] == "list" {
+		for _, name := range c.personaNames() {
+			fmt.Printf("%-15s 变量: %s\n", name, formatVars(c.personas[name].defaults()))
+		}
+		return nil
+	}
+
+	system := strings.TrimSpace(strings.TrimPrefix(line, "/system"))
+	if _, ok := c.personas[args[0]]; ok {
+		vars := map[string]any{}
+		for _, kv := range args[1:] {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return fmt.Errorf("变量 %q 应为 key=value 格式", kv)
+			}
+			vars[k] = v
+		}
+		var err error
+		if system, err = c.renderPersona(ctx, args[0], vars); err != nil {
+			return err
+		}
+	}
+	c.session.System = system
+	fmt.Printf("系统提示词已更新：\n%s\n", system)
+	return nil
+}
+
+func formatVars(vars map[string]any) string {
+	if len(vars) == 0 {
+		return "无"
+	}
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%v", k, vars[k])
+	}
+	return strings.Join(parts, " ")
+}
+
+// cmdTemp 参数按当前模型的能力表校验；第一个参数也可以是采样预设的名称，只使用其中的 temperature 和 top_p
+func (c *chat) cmdTemp(args []string) error {
+	if len(args) == 0 {
+		fmt.Printf("temperature=%s top_p=%s\n", formatFloat(c.temperature), formatFloat(c.topP))
+		return nil
+	}
+	if args[0] == "reset" {
+		c.temperature, c.topP = nil, nil
+		fmt.Println("已恢复 profile 的默认采样参数")
+		return nil
+	}
+	if len(args) > 2 {
+		return fmt.Errorf("用法：/temp <温度> [top_p]")
+	}
+
+	var params sampling.Params
+	t, err := parseFloat(args[0])
+	switch {
+	case err != nil && len(args) == 1:
+		// 不是数字时按预设名称处理
+		if params, err = c.cfg.Preset(args[0]); err != nil {
+			return err
+		}
+	case err != nil:
+		return fmt.Errorf("temperature: %w", err)
+	default:
+		params.Temperature = t
+		if len(args) == 2 {
+			if params.TopP, err = parseFloat(args[1]); err != nil {
+				return fmt.Errorf("top_p: %w", err)
+			}
+		}
+	}
+
+	p, err := c.cfg.Profile(c.profile)
+	if err != nil {
+		return err
+	}
+	if err := sampling.Lookup(p.Provider, p.Model).Validate(c.profile, params); err != nil {
+		return err
+	}
+	if params.Temperature != nil {
+		c.temperature = params.Temperature
+	}
+	if params.TopP != nil {
+		c.topP = params.TopP
+	}
+	fmt.Printf("temperature=%s top_p=%s\n", formatFloat(c.temperature), formatFloat(c.topP))
+	return nil
+}
+
+func parseFloat(s string) (*float32, error) {
+	v, err := strconv.ParseFloat(s, 32)
+	if err != nil {
+		return nil, fmt.Errorf("无效的数字 %q", s)
+	}
+	f := float32(v)
+	return &f, nil
+}
+
+func formatFloat(f *float32) string {
+	if f == nil {
+		return "默认"
+	}
+	return strconv.FormatFloat(float64(*f), 'f', -1, 32)
+}
+
+func (c *chat) cmdLoad(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		infos, err := c.mem.List(ctx)
+		if err != nil {
+			return err
+		}
+		if len(infos) == 0 {
+			fmt.Println("还没有保存的会话")
+		}
+		for _, info := range infos {
+			fmt.Println(memory.FormatInfo(info))
+		}
+		return nil
+	}
+	s, err := c.mem.Resume(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	c.session = s
+	fmt.Printf("已加载会话 %s（%d 轮）\n", s.ID, s.Turns())
+	return nil
+}
+
+func (c *chat) cmdTools(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		enabled := map[string]bool{}
+		infos, err := tools.Infos(ctx, c.tools)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			enabled[info.Name] = true
+		}
+		for _, name := range tools.Names() {
+			mark := " "
+			if enabled[name] {
+				mark = "*"
+			}
+			fmt.Printf("%s %s\n", mark, name)
+		}
+		return nil
+	}
+
+	switch args[0] {
+	case "off":
+		c.tools, c.toolsNode = nil, nil
+		if err := c.bindTools(ctx); err != nil {
+			return err
+		}
+		fmt.Println("已关闭工具")
+		return nil
+	case "on":
+		args = nil
+	}
+	if err := c.setTools(ctx, args); err != nil {
+		return err
+	}
+	fmt.Printf("已开启 %d 个工具\n", len(c.tools))
+	return nil
+}
+
+func (c *chat) cmdThink(args []string) {
+	if len(args) > 0 {
+		c.showThinking = args[0] == "on"
+		if c.showThinking {
+			fmt.Println("思考过程将实时展开")
+		} else {
+			fmt.Println("思考过程将折叠显示")
+		}
+		return
+	}
+	if c.lastThought == "" {
+		fmt.Println("上一次回复没有思考过程")
+		return
+	}
+	fmt.Printf("▼ 思考过程\n%s\n▲ 思考结束\n", c.lastThought)
+}
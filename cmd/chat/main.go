@@ -0,0 +1,409 @@
+package main
+
+/*
+交互式对话命令行:
+	go run ./cmd/chat [-profile balanced] [-persona assistant] [-tools calculator,get_weather]
+
+	回复以流式输出，每轮结束自动保存会话（默认 .sessions 目录，MEMORY_STORE=sqlite 时保存到 sessions.db）。
+	开启工具后模型可以调用 internal/tools 中的工具，一轮对话内最多连续调用 maxToolSteps 次。
+	推理模型（如 -profile reasoner）的思考过程默认折叠，/think 查看，/think on 实时展开；思考过程不会发回模型。
+	输入 /help 查看斜杠命令。
+*/
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/budget"
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/memory"
+	"eino-tutorial/internal/sampling"
+	"eino-tutorial/internal/streamx"
+	"eino-tutorial/internal/tools"
+	"eino-tutorial/internal/usage"
+)
+
+// maxToolSteps 一轮对话中模型最多连续调用工具的次数
+const maxToolSteps = 5
+
+// chat 命令行的状态
+type chat struct {
+	cfg     *chatmodel.Config
+	profile string
+	base    model.ToolCallingChatModel
+	// active 绑定了已开启工具的模型，没有开启工具时等于 base
+	active model.ToolCallingChatModel
+
+	temperature *float32
+	topP        *float32
+
+	store   memory.Store
+	mem     *memory.Memory
+	session *memory.Session
+	// budget 按当前模型的上下文窗口裁剪发送的历史，为 nil 时不裁剪
+	budget *budget.Budgeter
+
+	// personas 可选的人设，见 loadPersonas
+	personas map[string]chatPersona
+
+	tools     []tool.BaseTool
+	toolsNode *compose.ToolsNode
+
+	// showThinking 是否实时展开推理模型的思考过程，默认折叠
+	showThinking bool
+	// lastThought 最近一次回复的思考过程，用 /think 查看
+	lastThought string
+
+	ledger *usage.Ledger
+}
+
+func main() {
+	profile := flag.String("profile", "", "模型 profile，默认使用配置文件中的 default")
+	personaName := flag.String("persona", "assistant", "初始人设，见 /system list")
+	toolNames := flag.String("tools", "", "开启的工具，逗号分隔，all 表示全部")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	cfg, err := chatmodel.Default()
+	if err != nil {
+		log.Fatalf("加载模型配置失败: %v", err)
+	}
+	store, err := openStore()
+	if err != nil {
+		log.Fatalf("打开会话存储失败: %v", err)
+	}
+
+	c := &chat{
+		cfg:      cfg,
+		store:    store,
+		personas: loadPersonas(),
+		ledger:   usage.New(&usage.Config{Prices: usage.DefaultPrices()}),
+	}
+	if err := c.setModel(ctx, *profile); err != nil {
+		log.Fatalf("创建 ChatModel 失败: %v", err)
+	}
+	system, err := c.renderPersona(ctx, *personaName, nil)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	c.session = memory.NewSession(system)
+	if *toolNames != "" {
+		names := strings.Split(*toolNames, ",")
+		if *toolNames == "all" {
+			names = nil
+		}
+		if err := c.setTools(ctx, names); err != nil {
+			log.Fatalf("开启工具失败: %v", err)
+		}
+	}
+
+	ctx, cancel := c.ledger.Bind(ctx)
+	defer cancel()
+
+	fmt.Printf("模型 %s，会话 %s。输入 /help 查看命令，/exit 退出。\n", c.profile, c.session.ID)
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for {
+		fmt.Print("\n你: ")
+		if !scanner.Scan() {
+			break
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if line == "exit" || line == "/exit" || line == "/quit" {
+			break
+		}
+		if strings.HasPrefix(line, "/") {
+			c.handleCommand(ctx, line)
+			continue
+		}
+		if err := c.turn(ctx, line); err != nil {
+			fmt.Printf("\n[错误] %v\n", err)
+		}
+	}
+	if len(c.session.Messages) > 0 {
+		if err := c.mem.Save(ctx, c.session); err != nil {
+			log.Printf("保存会话失败: %v", err)
+		}
+	}
+	fmt.Println("对话结束。")
+}
+
+func openStore() (memory.Store, error) {
+	if os.Getenv("MEMORY_STORE") == "sqlite" {
+		return memory.OpenSQLite("sessions.db")
+	}
+	return memory.NewFileStore(".sessions")
+}
+
+// setModel 切换 profile，对话历史保留
+func (c *chat) setModel(ctx context.Context, profile string) error {
+	if profile == "" {
+		profile = os.Getenv(chatmodel.EnvProfile)
+	}
+	if profile == "" {
+		profile = c.cfg.Default
+	}
+	p, err := c.cfg.Profile(profile)
+	if err != nil {
+		return err
+	}
+	// 回答的 token 上限作为预留，没有配置时由 budget 按窗口大小预留
+	b, err := budget.New(&budget.Config{
+		Tokenizer: p.Tokenizer(),
+		Reserve:   p.MaxTokens,
+		Parts:     []budget.Part{{Key: "history", Kind: budget.History}},
+	})
+	if err != nil {
+		// 自定义 provider 没有登记上下文窗口时不裁剪历史，对话仍可继续
+		fmt.Fprintf(os.Stderr, "警告: %v，不裁剪对话历史\n", err)
+		b = nil
+	}
+	cm, err := c.cfg.New(ctx, profile)
+	if err != nil {
+		return err
+	}
+	c.base, c.profile, c.budget = cm, profile, b
+	// /temp 设置的参数按新模型的能力表重新校验，不支持时恢复 profile 的默认值
+	if c.temperature != nil || c.topP != nil {
+		params := sampling.Params{Temperature: c.temperature, TopP: c.topP}
+		if err := sampling.Lookup(p.Provider, p.Model).Validate(profile, params); err != nil {
+			fmt.Printf("/temp 设置的采样参数不适用于 %s，已恢复 profile 的默认值: %v\n", profile, err)
+			c.temperature, c.topP = nil, nil
+		}
+	}
+	// 早期对话由当前模型压缩成摘要
+	c.mem = memory.New(c.store, memory.Summarize(&memory.SummaryConfig{
+		Model: cm,
+		Keep:  memory.LastN(10),
+		Batch: 4,
+	}))
+	return c.bindTools(ctx)
+}
+
+// setTools 开启指定的工具，names 为空时开启全部工具
+func (c *chat) setTools(ctx context.Context, names []string) error {
+	ts, err := tools.Get(names...)
+	if err != nil {
+		return err
+	}
+	node, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{Tools: ts})
+	if err != nil {
+		return fmt.Errorf("创建 ToolsNode 失败: %w", err)
+	}
+	c.tools, c.toolsNode = ts, node
+	return c.bindTools(ctx)
+}
+
+// bindTools 把已开启的工具绑定到当前模型
+func (c *chat) bindTools(ctx context.Context) error {
+	if len(c.tools) == 0 {
+		c.active = c.base
+		return nil
+	}
+	infos, err := tools.Infos(ctx, c.tools)
+	if err != nil {
+		return err
+	}
+	active, err := c.base.WithTools(infos)
+	if err != nil {
+		return fmt.Errorf("绑定工具失败: %w", err)
+	}
+	c.active = active
+	return nil
+}
+
+// options 本次调用的采样参数
+func (c *chat) options() []model.Option {
+	var opts []model.Option
+	if c.temperature != nil {
+		opts = append(opts, model.WithTemperature(*c.temperature))
+	}
+	if c.topP != nil {
+		opts = append(opts, model.WithTopP(*c.topP))
+	}
+	return opts
+}
+
+// turn 处理一轮对话：流式输出回复，模型要求调用工具时执行工具并继续，直到给出最终回复
+func (c *chat) turn(ctx context.Context, input string) error {
+	ctx = usage.WithSession(ctx, c.session.ID)
+	// 出错时撤回本轮的所有消息，保持历史完整
+	mark := len(c.session.Messages)
+	c.session.Append(schema.UserMessage(input))
+
+	for step := 0; ; step++ {
+		msg, err := c.reply(ctx)
+		if err != nil {
+			c.session.Messages = c.session.Messages[:mark]
+			return err
+		}
+		c.session.Append(msg)
+		if len(msg.ToolCalls) == 0 {
+			break
+		}
+		if step >= maxToolSteps {
+			c.session.Messages = c.session.Messages[:mark]
+			return fmt.Errorf("工具调用超过 %d 次仍未给出回复", maxToolSteps)
+		}
+		results, err := c.callTools(ctx, msg)
+		if err != nil {
+			c.session.Messages = c.session.Messages[:mark]
+			return err
+		}
+		c.session.Append(results...)
+	}
+
+	if err := c.mem.Save(ctx, c.session); err != nil {
+		return fmt.Errorf("保存会话失败: %w", err)
+	}
+	return nil
+}
+
+// reply 请求一次模型并流式打印正文
+func (c *chat) reply(ctx context.Context) (*schema.Message, error) {
+	msgs, err := c.mem.Messages(ctx, c.session)
+	if err != nil {
+		return nil, fmt.Errorf("构造上下文失败: %w", err)
+	}
+	if msgs, err = c.fit(ctx, msgs); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	sr, err := c.active.Stream(ctx, msgs, c.options()...)
+	if err != nil {
+		return nil, fmt.Errorf("生成失败: %w", err)
+	}
+	defer sr.Close()
+
+	agg := streamx.NewAggregator(start)
+	th := &thinking{show: c.showThinking}
+	printed := false
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			th.end()
+			return nil, fmt.Errorf("接收流失败: %w", err)
+		}
+		_ = agg.Add(chunk)
+		th.add(chunk.ReasoningContent)
+		if chunk.Content != "" {
+			th.end()
+			if !printed {
+				fmt.Print("\nAI: ")
+				printed = true
+			}
+			fmt.Print(chunk.Content)
+		}
+	}
+	th.end()
+	if printed {
+		fmt.Println()
+	}
+	if th.text.Len() > 0 {
+		c.lastThought = th.text.String()
+	}
+	return agg.Message()
+}
+
+// contextTemplate 系统提示词和摘要原样保留，只有对话历史参与裁剪
+var contextTemplate = prompt.FromMessages(schema.FString,
+	schema.MessagesPlaceholder("system", false),
+	schema.MessagesPlaceholder("history", false),
+)
+
+// fit 历史超出上下文窗口时省略最早的轮次，当前这一轮总是发送
+func (c *chat) fit(ctx context.Context, msgs []*schema.Message) ([]*schema.Message, error) {
+	if c.budget == nil {
+		return msgs, nil
+	}
+	n := 0
+	for n < len(msgs) && msgs[n].Role == schema.System {
+		n++
+	}
+	res, err := c.budget.Fit(ctx, contextTemplate, map[string]any{"system": msgs[:n], "history": msgs[n:]})
+	if err != nil {
+		return nil, fmt.Errorf("构造上下文失败: %w", err)
+	}
+	if d := res.Dropped["history"]; d > 0 {
+		fmt.Printf("\n[上下文约 %d tokens，超出窗口，本次省略最早的 %d 轮对话]\n", c.budget.Count(msgs), d)
+	}
+	return res.Messages, nil
+}
+
+// thinking 在终端中显示思考过程：折叠时只显示进度和字数，展开时以灰色实时输出
+type thinking struct {
+	show  bool
+	text  strings.Builder
+	start time.Time
+	done  bool
+}
+
+func (t *thinking) add(s string) {
+	if s == "" || t.done {
+		return
+	}
+	if t.text.Len() == 0 {
+		t.start = time.Now()
+		if t.show {
+			fmt.Print("\n▼ 思考过程\n\033[90m")
+		}
+	}
+	t.text.WriteString(s)
+	if t.show {
+		fmt.Print(s)
+	} else {
+		fmt.Printf("\r▶ 思考中… %d 字", utf8.RuneCountInString(t.text.String()))
+	}
+}
+
+// end 思考结束，折叠时把进度行替换为摘要
+func (t *thinking) end() {
+	if t.text.Len() == 0 || t.done {
+		return
+	}
+	t.done = true
+	elapsed := time.Since(t.start).Round(100 * time.Millisecond)
+	if t.show {
+		fmt.Printf("\033[0m\n▲ 思考结束（用时 %s）\n", elapsed)
+		return
+	}
+	fmt.Printf("\r▶ 思考过程（%d 字，用时 %s，输入 /think 展开）\n", utf8.RuneCountInString(t.text.String()), elapsed)
+}
+
+// callTools 执行模型要求的工具调用
+func (c *chat) callTools(ctx context.Context, msg *schema.Message) ([]*schema.Message, error) {
+	for _, tc := range msg.ToolCalls {
+		fmt.Printf("[调用工具] %s %s\n", tc.Function.Name, tc.Function.Arguments)
+	}
+	results, err := c.toolsNode.Invoke(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("工具调用失败: %w", err)
+	}
+	for _, r := range results {
+		fmt.Printf("[工具结果] %s\n", r.Content)
+	}
+	return results, nil
+}
@@ -0,0 +1,161 @@
+package main
+
+/*
+Go 代码审查命令行:
+	go run ./cmd/review [-profile code] [-local] [-format markdown|sarif] [-o report.md] 文件或目录...
+
+	先在本地运行 go vet、复杂度统计和 gofmt 检查，再把诊断结果附在审查请求中交给模型，
+	最后把模型的意见并入同一行的本地诊断，输出 Markdown 或 SARIF 报告。-local 只做本地分析，不请求模型。
+	目录会递归查找 .go 文件（跳过 vendor 和以 . 开头的目录），路径相对于当前目录，
+	go vet 在当前目录所在的模块中运行。
+*/
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"eino-tutorial/internal/chatmodel"
+	"eino-tutorial/internal/codeassist"
+	"eino-tutorial/internal/review"
+)
+
+func main() {
+	profile := flag.String("profile", "code", "模型 profile")
+	local := flag.Bool("local", false, "只做本地分析，不请求模型")
+	noVet := flag.Bool("novet", false, "不运行 go vet")
+	maxComplexity := flag.Int("max-complexity", review.DefaultMaxComplexity, "圈复杂度阈值")
+	maxLines := flag.Int("max-lines", review.DefaultMaxLines, "函数行数阈值")
+	format := flag.String("format", "markdown", "报告格式：markdown 或 sarif")
+	output := flag.String("o", "", "报告输出文件，默认输出到标准输出")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		log.Fatal("用法: review [选项] 文件或目录...")
+	}
+	if *format != "markdown" && *format != "sarif" {
+		log.Fatalf("不支持的报告格式: %s", *format)
+	}
+
+	files, err := collect(flag.Args())
+	if err != nil {
+		log.Fatalf("读取文件失败: %v", err)
+	}
+	if len(files) == 0 {
+		log.Fatal("没有找到 Go 文件")
+	}
+
+	ctx := context.Background()
+	// 文件都在当前目录下，go vet 直接在当前模块中运行，可以解析模块内的依赖
+	cfg := &review.Config{Options: review.Options{
+		NoVet:         *noVet,
+		Dir:           ".",
+		MaxComplexity: *maxComplexity,
+		MaxLines:      *maxLines,
+	}}
+	if !*local {
+		cm, err := chatmodel.New(ctx, *profile)
+		if err != nil {
+			log.Fatalf("创建模型失败: %v", err)
+		}
+		if cfg.Assistant, err = codeassist.New(cm); err != nil {
+			log.Fatalf("创建代码助手失败: %v", err)
+		}
+	}
+
+	report, err := review.Review(ctx, cfg, files)
+	if err != nil {
+		log.Fatalf("审查失败: %v", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("创建报告文件失败: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if *format == "sarif" {
+		err = report.WriteSARIF(w)
+	} else {
+		err = report.WriteMarkdown(w)
+	}
+	if err != nil {
+		log.Fatalf("输出报告失败: %v", err)
+	}
+
+	n := report.Count()
+	fmt.Fprintf(os.Stderr, "审查了 %d 个文件：error %d，warning %d，info %d\n",
+		len(files), n[codeassist.SeverityError], n[codeassist.SeverityWarning], n[codeassist.SeverityInfo])
+}
+
+// collect 读取参数中的文件和目录下的 .go 文件，路径转换为相对于当前目录的形式
+func collect(args []string) ([]codeassist.File, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var files []codeassist.File
+	add := func(p string) error {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(wd, abs)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("%s 不在当前目录下", p)
+		}
+		rel = filepath.ToSlash(rel)
+		if seen[rel] {
+			return nil
+		}
+		seen[rel] = true
+		data, err := os.ReadFile(abs)
+		if err != nil {
+			return err
+		}
+		files = append(files, codeassist.File{Path: rel, Content: string(data)})
+		return nil
+	}
+
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if err := add(arg); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		err = filepath.WalkDir(arg, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if p != arg && (d.Name() == "vendor" || strings.HasPrefix(d.Name(), ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(p, ".go") {
+				return add(p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
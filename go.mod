@@ -10,8 +10,13 @@ require (
 	github.com/cloudwego/eino-ext/components/model/deepseek v0.1.1
 	github.com/cloudwego/eino-ext/components/retriever/es8 v0.0.0-20260109062358-b9080dbc7bed
 	github.com/cloudwego/eino-ext/components/retriever/milvus v0.0.0-20260109062358-b9080dbc7bed
+	github.com/cohesion-org/deepseek-go v1.3.2
+	github.com/eino-contrib/jsonschema v1.0.3
 	github.com/elastic/go-elasticsearch/v8 v8.19.1
+	github.com/mattn/go-sqlite3 v1.14.33
 	github.com/milvus-io/milvus-sdk-go/v2 v2.4.2
+	github.com/nikolalohinski/gonja v1.5.3
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -24,9 +29,7 @@ require (
 	github.com/cockroachdb/errors v1.9.1 // indirect
 	github.com/cockroachdb/logtags v0.0.0-20211118104740-dabe8e521a4f // indirect
 	github.com/cockroachdb/redact v1.1.3 // indirect
-	github.com/cohesion-org/deepseek-go v1.3.2 // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
-	github.com/eino-contrib/jsonschema v1.0.3 // indirect
 	github.com/elastic/elastic-transport-go/v8 v8.8.0 // indirect
 	github.com/getsentry/sentry-go v0.12.0 // indirect
 	github.com/go-logr/logr v1.4.2 // indirect
@@ -46,7 +49,6 @@ require (
 	github.com/milvus-io/milvus-proto/go-api/v2 v2.4.10-0.20240819025435-512e3b98866a // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
-	github.com/nikolalohinski/gonja v1.5.3 // indirect
 	github.com/ollama/ollama v0.6.5 // indirect
 	github.com/pelletier/go-toml/v2 v2.2.2 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
@@ -75,5 +77,4 @@ require (
 	google.golang.org/grpc v1.73.0 // indirect
 	google.golang.org/protobuf v1.36.6 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,394 @@
+// Package budget 让模板渲染出的消息放得进模型的上下文窗口。
+//
+// 模板中可以裁剪的变量需要事先声明优先级：
+//
+//	b, _ := budget.New(&budget.Config{
+//		Tokenizer: tokens.Lookup("deepseek", "deepseek-chat"),
+//		Parts: []budget.Part{
+//			{Key: "examples", Kind: budget.FewShot},
+//			{Key: "history", Kind: budget.History},
+//			{Key: "docs", Kind: budget.Context, Format: budget.JoinDocuments},
+//		},
+//	})
+//	res, err := b.Fit(ctx, tpl, vars)
+//
+// 超出预算时依次裁剪少样本示例（丢弃靠后的示例）、对话历史（丢弃最早的轮次，可选生成摘要）
+// 和检索到的上下文（丢弃靠后的文档，最后截断文本），直到放得下为止。
+// 模板中没有声明的部分（系统提示词、当前问题等）从不裁剪，对话历史的最后一轮也总是保留。
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/tokens"
+)
+
+// Kind 可裁剪部分的类别，按裁剪顺序排列
+type Kind int
+
+const (
+	// FewShot 少样本示例，[]*schema.Message，每个示例以用户消息开头；从后往前丢弃
+	FewShot Kind = iota
+	// History 对话历史，[]*schema.Message，按轮次（以用户消息开头）从最早的开始丢弃
+	History
+	// Context 检索到的上下文，[]*schema.Document、[]string 或 string；列表从后往前丢弃，字符串截断末尾
+	Context
+)
+
+func (k Kind) String() string {
+	switch k {
+	case FewShot:
+		return "few-shot"
+	case History:
+		return "history"
+	case Context:
+		return "context"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// ErrOverBudget 裁剪掉全部可裁剪的内容后仍然超出预算
+var ErrOverBudget = errors.New("budget: 超出上下文窗口")
+
+// Part 模板中一个可以裁剪的变量
+type Part struct {
+	// Key 模板变量名
+	Key  string
+	Kind Kind
+	// Format 把裁剪后的文档转换成模板变量，例如拼接成字符串供 FString 模板使用；为 nil 时保持原类型
+	Format func(docs []*schema.Document) string
+}
+
+// SummarizeFunc 把被丢弃的对话历史压缩成一段摘要
+type SummarizeFunc func(ctx context.Context, dropped []*schema.Message) (string, error)
+
+// Config Budgeter 配置
+type Config struct {
+	// Tokenizer 估算 token 数，默认 tokens.Approx
+	Tokenizer *tokens.Tokenizer
+	// Limit 上下文窗口，默认 Tokenizer.ContextWindow
+	Limit int
+	// Reserve 为回答预留的 token 数，默认 Limit 的 1/8
+	Reserve int
+	Parts   []Part
+	// Summarize 不为空时，被丢弃的对话历史会压缩成摘要放在保留的历史之前，必要时多丢弃几轮为摘要腾出空间
+	Summarize SummarizeFunc
+}
+
+// Budgeter 按预算裁剪模板变量，可以并发使用
+type Budgeter struct {
+	cfg    Config
+	budget int
+}
+
+// Result 一次裁剪的结果
+type Result struct {
+	Messages []*schema.Message
+	// Tokens 估算的输入 token 数
+	Tokens int
+	// Budget 输入可用的 token 数（Limit 减去 Reserve）
+	Budget int
+	// Dropped 每个变量被丢弃的条数（字符串为截掉的 token 数）
+	Dropped map[string]int
+	// Summarized 是否用摘要代替了被丢弃的历史
+	Summarized bool
+}
+
+// New 创建 Budgeter
+func New(cfg *Config) (*Budgeter, error) {
+	if cfg == nil {
+		return nil, errors.New("budget: 缺少配置")
+	}
+	c := *cfg
+	if c.Tokenizer == nil {
+		c.Tokenizer = tokens.Approx
+	}
+	if c.Limit <= 0 {
+		c.Limit = c.Tokenizer.ContextWindow
+	}
+	if c.Limit <= 0 {
+		return nil, fmt.Errorf("budget: 未指定 Limit，%s 也没有登记上下文窗口", c.Tokenizer.Name)
+	}
+	if c.Reserve <= 0 {
+		c.Reserve = c.Limit / 8
+	}
+	if c.Reserve >= c.Limit {
+		return nil, fmt.Errorf("budget: Reserve %d 不小于 Limit %d", c.Reserve, c.Limit)
+	}
+	keys := map[string]bool{}
+	for _, p := range c.Parts {
+		if p.Key == "" || keys[p.Key] {
+			return nil, fmt.Errorf("budget: 变量名 %q 为空或重复", p.Key)
+		}
+		if p.Kind < FewShot || p.Kind > Context {
+			return nil, fmt.Errorf("budget: 变量 %s 的类别 %v 无效", p.Key, p.Kind)
+		}
+		keys[p.Key] = true
+	}
+	return &Budgeter{cfg: c, budget: c.Limit - c.Reserve}, nil
+}
+
+// Budget 输入可用的 token 数
+func (b *Budgeter) Budget() int {
+	return b.budget
+}
+
+// Count 估算消息的 token 数
+func (b *Budgeter) Count(msgs []*schema.Message) int {
+	return b.cfg.Tokenizer.Messages(msgs)
+}
+
+// Fit 用 tpl 渲染 vars，超出预算时按优先级裁剪声明过的变量。vars 不会被修改。
+//
+// 裁剪过程中的试渲染不触发 ctx 中设置的回调处理器，只有最终结果会用 ctx 渲染一次；
+// 通过 callbacks.AppendGlobalHandlers 注册的全局处理器无法屏蔽，每次试渲染都会触发
+func (b *Budgeter) Fit(ctx context.Context, tpl prompt.ChatTemplate, vars map[string]any, opts ...prompt.Option) (*Result, error) {
+	work := make(map[string]any, len(vars))
+	for k, v := range vars {
+		work[k] = v
+	}
+	res := &Result{Budget: b.budget, Dropped: map[string]int{}}
+
+	var parts []*part
+	for _, p := range b.cfg.Parts {
+		v, ok := vars[p.Key]
+		if !ok {
+			continue
+		}
+		pt, err := newPart(p, v, b.cfg.Tokenizer)
+		if err != nil {
+			return nil, err
+		}
+		work[p.Key] = pt.value(pt.n)
+		parts = append(parts, pt)
+	}
+
+	quiet := callbacks.InitCallbacks(ctx, nil)
+	fits := func() (bool, error) {
+		msgs, err := tpl.Format(quiet, work, opts...)
+		if err != nil {
+			return false, err
+		}
+		return b.Count(msgs) <= b.budget, nil
+	}
+	// trim 在 [lo, hi] 中找到 fits 的最大保留量，全部裁掉仍然放不下时返回 false
+	trim := func(pt *part, lo, hi int) (bool, error) {
+		work[pt.Key] = pt.value(lo)
+		ok, err := fits()
+		if err != nil || !ok {
+			pt.keep = lo
+			return false, err
+		}
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+			work[pt.Key] = pt.value(mid)
+			if ok, err = fits(); err != nil {
+				return false, err
+			}
+			if ok {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+		pt.keep = lo
+		work[pt.Key] = pt.value(lo)
+		return true, nil
+	}
+
+	ok, err := fits()
+	if err != nil {
+		return nil, fmt.Errorf("budget: 渲染模板失败: %w", err)
+	}
+	for kind := FewShot; !ok && kind <= Context; kind++ {
+		for _, pt := range parts {
+			if pt.Kind != kind || ok {
+				continue
+			}
+			if ok, err = trim(pt, pt.min, pt.keep); err != nil {
+				return nil, fmt.Errorf("budget: 渲染模板失败: %w", err)
+			}
+			if ok && kind == History && b.cfg.Summarize != nil && pt.keep < pt.n {
+				if ok, err = b.summarize(ctx, pt, work, fits, trim); err != nil {
+					return nil, err
+				}
+				res.Summarized = pt.summary != nil
+			}
+		}
+	}
+
+	msgs, err := tpl.Format(ctx, work, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("budget: 渲染模板失败: %w", err)
+	}
+	res.Messages, res.Tokens = msgs, b.Count(msgs)
+	for _, pt := range parts {
+		if pt.keep < pt.n {
+			res.Dropped[pt.Key] = pt.n - pt.keep
+		}
+	}
+	if !ok {
+		return res, fmt.Errorf("%w: 估算 %d tokens，可用 %d", ErrOverBudget, res.Tokens, b.budget)
+	}
+	return res, nil
+}
+
+// summarize 把被丢弃的历史压缩成摘要。摘要放不下时再丢弃几轮为摘要腾出空间并重新生成一次，仍然放不下就不用摘要
+func (b *Budgeter) summarize(ctx context.Context, pt *part, work map[string]any,
+	fits func() (bool, error), trim func(pt *part, lo, hi int) (bool, error)) (bool, error) {
+	for range 2 {
+		text, err := b.cfg.Summarize(ctx, pt.dropped())
+		if err != nil {
+			return false, fmt.Errorf("budget: 生成历史摘要失败: %w", err)
+		}
+		pt.summary = schema.SystemMessage("以下是之前对话的摘要：\n" + strings.TrimSpace(text))
+		work[pt.Key] = pt.value(pt.keep)
+		ok, err := fits()
+		if err != nil || ok {
+			return ok, err
+		}
+		if ok, err = trim(pt, pt.min, pt.keep); err != nil || !ok {
+			break
+		}
+	}
+	pt.summary = nil
+	work[pt.Key] = pt.value(pt.keep)
+	return fits()
+}
+
+// part 一个可裁剪变量的裁剪状态。n 为总条数，keep 为当前保留的条数，min 为至少保留的条数
+type part struct {
+	Part
+	n, keep, min int
+	value        func(keep int) any
+	dropped      func() []*schema.Message
+	summary      *schema.Message
+}
+
+func newPart(p Part, v any, tk *tokens.Tokenizer) (*part, error) {
+	pt := &part{Part: p}
+	defer func() { pt.keep = pt.n }()
+	switch p.Kind {
+	case FewShot, History:
+		msgs, ok := v.([]*schema.Message)
+		if !ok {
+			return nil, fmt.Errorf("budget: 变量 %s 应为 []*schema.Message，实际为 %T", p.Key, v)
+		}
+		starts := turnStarts(msgs)
+		pt.n = len(starts)
+		if p.Kind == FewShot {
+			// 保留前 keep 个示例
+			pt.value = func(keep int) any {
+				if keep == pt.n {
+					return msgs
+				}
+				return msgs[:starts[keep]]
+			}
+			return pt, nil
+		}
+		// 保留最近 keep 轮，最后一轮总是保留
+		pt.min = min(1, pt.n)
+		from := func(keep int) int {
+			if keep == pt.n {
+				return 0
+			}
+			return starts[pt.n-keep]
+		}
+		pt.value = func(keep int) any {
+			kept := msgs[from(keep):]
+			if pt.summary == nil || keep == pt.n {
+				return kept
+			}
+			return append([]*schema.Message{pt.summary}, kept...)
+		}
+		pt.dropped = func() []*schema.Message {
+			return msgs[:from(pt.keep)]
+		}
+		return pt, nil
+	}
+
+	switch v := v.(type) {
+	case string:
+		// 以 token 为单位截断
+		pt.n = tk.Count(v)
+		pt.value = func(keep int) any {
+			if keep == pt.n {
+				return v
+			}
+			return tk.Truncate(v, keep)
+		}
+	case []*schema.Document:
+		pt.n = len(v)
+		pt.value = func(keep int) any {
+			if p.Format != nil {
+				return p.Format(v[:keep])
+			}
+			return v[:keep]
+		}
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("budget: 变量 %s 的类型 %T 无法裁剪", p.Key, v)
+		}
+		pt.n = rv.Len()
+		pt.value = func(keep int) any {
+			return rv.Slice(0, keep).Interface()
+		}
+	}
+	return pt, nil
+}
+
+// turnStarts 每一组消息的起始下标：每条用户消息开始新的一组，第一条用户消息之前的消息单独成组
+func turnStarts(msgs []*schema.Message) []int {
+	var starts []int
+	for i, m := range msgs {
+		if i == 0 || m.Role == schema.User {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+// JoinDocuments 把文档拼接成带编号的文本，可用作 Part.Format
+func JoinDocuments(docs []*schema.Document) string {
+	var sb strings.Builder
+	for i, d := range docs {
+		fmt.Fprintf(&sb, "[%d] %s\n", i+1, strings.TrimSpace(d.Content))
+	}
+	return sb.String()
+}
+
+// Template 返回一个 ChatTemplate：Format 时按预算裁剪后再交给 inner 渲染，裁剪后仍然放不下时返回 ErrOverBudget
+func (b *Budgeter) Template(inner prompt.ChatTemplate) prompt.ChatTemplate {
+	return &budgetTemplate{inner: inner, b: b}
+}
+
+type budgetTemplate struct {
+	inner prompt.ChatTemplate
+	b     *Budgeter
+}
+
+func (t *budgetTemplate) Format(ctx context.Context, vs map[string]any, opts ...prompt.Option) ([]*schema.Message, error) {
+	res, err := t.b.Fit(ctx, t.inner, vs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return res.Messages, nil
+}
+
+func (t *budgetTemplate) GetType() string {
+	return "Budget"
+}
+
+// IsCallbacksEnabled 回调由内部的 ChatTemplate 触发
+func (t *budgetTemplate) IsCallbacksEnabled() bool {
+	return true
+}
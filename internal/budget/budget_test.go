@@ -0,0 +1,181 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/tokens"
+)
+
+// perChar 每个字符算一个 token，没有固定开销，便于手算预期
+var perChar = &tokens.Tokenizer{Name: "per-char", ASCII: 1, CJK: 1, Other: 1}
+
+// turns 生成若干轮对话，每轮为 4 个字符的用户消息和 4 个字符的回答
+func turns(names ...string) []*schema.Message {
+	var msgs []*schema.Message
+	for _, n := range names {
+		text := strings.Repeat(n, 4)
+		msgs = append(msgs, schema.UserMessage(text), schema.AssistantMessage(text, nil))
+	}
+	return msgs
+}
+
+func newBudgeter(t *testing.T, budget int, summarize SummarizeFunc, parts ...Part) *Budgeter {
+	t.Helper()
+	b, err := New(&Config{Tokenizer: perChar, Limit: budget + 1, Reserve: 1, Parts: parts, Summarize: summarize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestFitOrder(t *testing.T) {
+	// 系统 1 + 示例 2×8 + 历史 2×8 + 文档 2×9（"[1] dddd\n"）+ 问题 1 = 52
+	tpl := prompt.FromMessages(schema.FString,
+		schema.SystemMessage("S"),
+		schema.MessagesPlaceholder("examples", true),
+		schema.MessagesPlaceholder("history", true),
+		schema.UserMessage("{docs}Q"),
+	)
+	vars := map[string]any{
+		"examples": turns("a", "b"),
+		"history":  turns("h", "i"),
+		"docs":     []*schema.Document{{Content: "dddd"}, {Content: "eeee"}},
+	}
+	parts := []Part{
+		{Key: "docs", Kind: Context, Format: JoinDocuments},
+		{Key: "history", Kind: History},
+		{Key: "examples", Kind: FewShot},
+	}
+	cases := []struct {
+		budget  int
+		dropped map[string]int
+		tokens  int
+	}{
+		{52, map[string]int{}, 52},
+		{44, map[string]int{"examples": 1}, 44},
+		{36, map[string]int{"examples": 2}, 36},
+		{30, map[string]int{"examples": 2, "history": 1}, 28},
+		// 历史的最后一轮总是保留，接着丢弃文档
+		{19, map[string]int{"examples": 2, "history": 1, "docs": 1}, 19},
+		{10, map[string]int{"examples": 2, "history": 1, "docs": 2}, 10},
+	}
+	for _, c := range cases {
+		res, err := newBudgeter(t, c.budget, nil, parts...).Fit(context.Background(), tpl, vars)
+		if err != nil {
+			t.Errorf("预算 %d: %v", c.budget, err)
+			continue
+		}
+		if res.Tokens != c.tokens || len(res.Dropped) != len(c.dropped) {
+			t.Errorf("预算 %d: tokens=%d dropped=%v，期望 %d %v", c.budget, res.Tokens, res.Dropped, c.tokens, c.dropped)
+			continue
+		}
+		for k, n := range c.dropped {
+			if res.Dropped[k] != n {
+				t.Errorf("预算 %d: dropped=%v，期望 %v", c.budget, res.Dropped, c.dropped)
+			}
+		}
+	}
+	// 裁剪不修改调用方的变量
+	if len(vars["examples"].([]*schema.Message)) != 4 || len(vars["history"].([]*schema.Message)) != 4 {
+		t.Error("vars 被修改")
+	}
+}
+
+func TestFitOverBudget(t *testing.T) {
+	tpl := prompt.FromMessages(schema.FString,
+		schema.SystemMessage("很长的系统提示词"),
+		schema.MessagesPlaceholder("history", false),
+	)
+	b := newBudgeter(t, 12, nil, Part{Key: "history", Kind: History})
+	res, err := b.Fit(context.Background(), tpl, map[string]any{"history": turns("a", "b")})
+	if !errors.Is(err, ErrOverBudget) {
+		t.Fatalf("err = %v，期望 ErrOverBudget", err)
+	}
+	// 仍然返回尽力裁剪后的结果：系统提示词 8 + 最后一轮 8
+	if res == nil || res.Tokens != 16 || res.Dropped["history"] != 1 {
+		t.Errorf("res = %+v", res)
+	}
+}
+
+func TestFitSummarize(t *testing.T) {
+	tpl := prompt.FromMessages(schema.FString,
+		schema.SystemMessage("S"),
+		schema.MessagesPlaceholder("history", false),
+		schema.UserMessage("Q"),
+	)
+	// 摘要消息为 "以下是之前对话的摘要：\nsum"，共 15 个字符
+	var calls [][]*schema.Message
+	summarize := func(_ context.Context, dropped []*schema.Message) (string, error) {
+		calls = append(calls, dropped)
+		return "sum", nil
+	}
+	vars := map[string]any{"history": turns("a", "b", "c")}
+
+	// 丢弃 1 轮后放不下摘要，再丢弃 1 轮并重新生成摘要：1 + 15 + 8 + 1 = 25
+	res, err := newBudgeter(t, 25, summarize, Part{Key: "history", Kind: History}).Fit(context.Background(), tpl, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Summarized || res.Dropped["history"] != 2 || res.Tokens != 25 {
+		t.Fatalf("res = %+v", res)
+	}
+	if len(calls) != 2 || len(calls[1]) != 4 {
+		t.Errorf("Summarize 调用 %d 次，最后一次传入 %d 条消息，期望 2 次、4 条", len(calls), len(calls[len(calls)-1]))
+	}
+	if msg := res.Messages[1]; msg.Role != schema.System || !strings.HasSuffix(msg.Content, "sum") {
+		t.Errorf("摘要应放在保留的历史之前: %+v", msg)
+	}
+
+	// 只剩最后一轮也放不下摘要时不用摘要
+	calls = nil
+	res, err = newBudgeter(t, 20, summarize, Part{Key: "history", Kind: History}).Fit(context.Background(), tpl, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Summarized || res.Dropped["history"] != 2 || res.Tokens != 10 {
+		t.Errorf("res = %+v", res)
+	}
+
+	// 不需要裁剪时不生成摘要
+	calls = nil
+	res, err = newBudgeter(t, 40, summarize, Part{Key: "history", Kind: History}).Fit(context.Background(), tpl, vars)
+	if err != nil || res.Summarized || len(calls) != 0 {
+		t.Errorf("res = %+v, err = %v, Summarize 调用 %d 次", res, err, len(calls))
+	}
+}
+
+func TestFitTruncatesString(t *testing.T) {
+	tpl := prompt.FromMessages(schema.FString,
+		schema.SystemMessage("S"),
+		schema.UserMessage("{docs}"),
+	)
+	b := newBudgeter(t, 7, nil, Part{Key: "docs", Kind: Context})
+	res, err := b.Fit(context.Background(), tpl, map[string]any{"docs": "abcdefghij"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Messages[1].Content; got != "abcdef" || res.Dropped["docs"] != 4 {
+		t.Errorf("截断后 = %q，dropped = %v", got, res.Dropped)
+	}
+}
+
+func TestNewValidates(t *testing.T) {
+	cases := map[string]*Config{
+		"没有上下文窗口": {Tokenizer: perChar},
+		"预留超过窗口":  {Tokenizer: perChar, Limit: 10, Reserve: 10},
+		"变量名重复":   {Tokenizer: perChar, Limit: 10, Parts: []Part{{Key: "a"}, {Key: "a"}}},
+		"类别无效":    {Tokenizer: perChar, Limit: 10, Parts: []Part{{Key: "a", Kind: Kind(9)}}},
+		"缺少配置":    nil,
+	}
+	for name, cfg := range cases {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+}
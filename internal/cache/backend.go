@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// Entry 一条缓存的响应
+type Entry struct {
+	Message   *schema.Message `json:"message"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Backend 缓存存储。Get 在 key 不存在时返回 (nil, nil)
+type Backend interface {
+	Get(ctx context.Context, key string) (*Entry, error)
+	Set(ctx context.Context, key string, e *Entry) error
+	Delete(ctx context.Context, key string) error
+}
+
+// LRU 进程内的 LRU 缓存。Set 和 Get 都复制消息，调用方修改返回的消息（如设置 Extra）不影响缓存中的内容
+type LRU struct {
+	capacity int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry *Entry
+}
+
+var _ Backend = (*LRU)(nil)
+
+// NewLRU 创建最多保存 capacity 条响应的 LRU 缓存，capacity <= 0 时为 1000
+func NewLRU(capacity int) *LRU {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &LRU{capacity: capacity, ll: list.New(), items: map[string]*list.Element{}}
+}
+
+func (c *LRU) Get(_ context.Context, key string) (*Entry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, nil
+	}
+	c.ll.MoveToFront(el)
+	return cloneEntry(el.Value.(*lruItem).entry), nil
+}
+
+func (c *LRU) Set(_ context.Context, key string, e *Entry) error {
+	e = cloneEntry(e)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruItem).entry = e
+		c.ll.MoveToFront(el)
+		return nil
+	}
+	c.items[key] = c.ll.PushFront(&lruItem{key: key, entry: e})
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+	}
+	return nil
+}
+
+func (c *LRU) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.Remove(el)
+		delete(c.items, key)
+	}
+	return nil
+}
+
+func cloneEntry(e *Entry) *Entry {
+	if e == nil {
+		return nil
+	}
+	return &Entry{Message: cloneMessage(e.Message), CreatedAt: e.CreatedAt}
+}
+
+// cloneMessage 复制消息中调用方可能修改的部分：Extra、ResponseMeta、ToolCalls 和多模态内容列表
+func cloneMessage(m *schema.Message) *schema.Message {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	c.Extra = maps.Clone(m.Extra)
+	c.MultiContent = slices.Clone(m.MultiContent)
+	c.UserInputMultiContent = slices.Clone(m.UserInputMultiContent)
+	c.AssistantGenMultiContent = slices.Clone(m.AssistantGenMultiContent)
+	if m.ToolCalls != nil {
+		c.ToolCalls = make([]schema.ToolCall, len(m.ToolCalls))
+		for i, tc := range m.ToolCalls {
+			if tc.Index != nil {
+				index := *tc.Index
+				tc.Index = &index
+			}
+			tc.Extra = maps.Clone(tc.Extra)
+			c.ToolCalls[i] = tc
+		}
+	}
+	if m.ResponseMeta != nil {
+		meta := *m.ResponseMeta
+		if meta.Usage != nil {
+			usage := *meta.Usage
+			meta.Usage = &usage
+		}
+		if meta.LogProbs != nil {
+			logProbs := schema.LogProbs{Content: slices.Clone(meta.LogProbs.Content)}
+			for i := range logProbs.Content {
+				lp := &logProbs.Content[i]
+				lp.Bytes = slices.Clone(lp.Bytes)
+				lp.TopLogProbs = slices.Clone(lp.TopLogProbs)
+			}
+			meta.LogProbs = &logProbs
+		}
+		c.ResponseMeta = &meta
+	}
+	return &c
+}
+
+// Disk 磁盘缓存，每条响应一个 JSON 文件，跨进程复用
+type Disk struct {
+	dir string
+}
+
+var _ Backend = (*Disk)(nil)
+
+// NewDisk 创建以 dir 为目录的磁盘缓存
+func NewDisk(dir string) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建缓存目录失败: %w", err)
+	}
+	return &Disk{dir: dir}, nil
+}
+
+// path 按 key 前两位分子目录，避免单个目录下文件过多
+func (d *Disk) path(key string) string {
+	return filepath.Join(d.dir, key[:2], key+".json")
+}
+
+func (d *Disk) Get(_ context.Context, key string) (*Entry, error) {
+	data, err := os.ReadFile(d.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取缓存失败: %w", err)
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("解析缓存 %s 失败: %w", key, err)
+	}
+	return &e, nil
+}
+
+func (d *Disk) Set(_ context.Context, key string, e *Entry) error {
+	p := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("创建缓存目录失败: %w", err)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("序列化缓存失败: %w", err)
+	}
+	// 先写临时文件再重命名，并发写同一个 key 时不会读到半个文件
+	tmp, err := os.CreateTemp(filepath.Dir(p), key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("写入缓存失败: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入缓存失败: %w", err)
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入缓存失败: %w", err)
+	}
+	return nil
+}
+
+func (d *Disk) Delete(_ context.Context, key string) error {
+	if err := os.Remove(d.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("删除缓存失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestLRUCopiesMessages(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+	index := 0
+	msg := schema.AssistantMessage("你好", []schema.ToolCall{{Index: &index, ID: "call_1", Extra: map[string]any{"k": "v"}}})
+	msg.Extra = map[string]any{"a": 1}
+	msg.ResponseMeta = &schema.ResponseMeta{
+		FinishReason: "stop",
+		Usage:        &schema.TokenUsage{TotalTokens: 10},
+		LogProbs:     &schema.LogProbs{Content: []schema.LogProb{{Token: "你", Bytes: []int64{1}}}},
+	}
+	if err := c.Set(ctx, "k", &Entry{Message: msg}); err != nil {
+		t.Fatal(err)
+	}
+	// 修改传入的消息不影响缓存
+	msg.Extra["a"] = 2
+	msg.ToolCalls[0].Extra["k"] = "changed"
+	*msg.ToolCalls[0].Index = 5
+
+	got, err := c.Get(ctx, "k")
+	if err != nil || got == nil {
+		t.Fatalf("Get = %v, %v", got, err)
+	}
+	// 修改取出的消息也不影响缓存
+	got.Message.Content = "改过"
+	got.Message.Extra["served_by"] = "backup"
+	got.Message.ResponseMeta.FinishReason = "length"
+	got.Message.ResponseMeta.Usage.TotalTokens = 99
+	got.Message.ResponseMeta.LogProbs.Content[0].Bytes[0] = 9
+	got.Message.ToolCalls = append(got.Message.ToolCalls[:0], schema.ToolCall{ID: "call_2"})
+
+	again, _ := c.Get(ctx, "k")
+	m := again.Message
+	if m.Content != "你好" || len(m.Extra) != 1 || m.Extra["a"] != 1 {
+		t.Errorf("Content/Extra 被修改: %q %v", m.Content, m.Extra)
+	}
+	if m.ResponseMeta.FinishReason != "stop" || m.ResponseMeta.Usage.TotalTokens != 10 || m.ResponseMeta.LogProbs.Content[0].Bytes[0] != 1 {
+		t.Errorf("ResponseMeta 被修改: %+v", m.ResponseMeta)
+	}
+	if tc := m.ToolCalls[0]; tc.ID != "call_1" || *tc.Index != 0 || tc.Extra["k"] != "v" {
+		t.Errorf("ToolCalls 被修改: %+v", m.ToolCalls)
+	}
+}
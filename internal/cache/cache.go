@@ -0,0 +1,313 @@
+// Package cache 为 ChatModel 提供响应缓存。
+//
+// 缓存 key 由规范化后的消息、工具定义和生成参数计算（见 Key）。命中时不请求底层模型，
+// Stream 会把缓存的完整响应切成多个分片回放。每次调用都会以 "Cache" 类型触发回调，
+// 回调输出的 Extra["cache"] 为 "hit" / "miss" / "bypass"，可用 Status 读取；
+// 这些回调的 TokenUsage 为 0，未命中时真实用量由底层模型自己的回调上报。
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/modelcb"
+)
+
+// ExtraStatus 回调输出和命中响应 Extra 中记录缓存状态的键
+const ExtraStatus = "cache"
+
+const (
+	StatusHit    = "hit"
+	StatusMiss   = "miss"
+	StatusBypass = "bypass"
+)
+
+// Status 返回回调输出中的缓存状态，不是缓存触发的回调时返回 false
+func Status(output callbacks.CallbackOutput) (string, bool) {
+	o, ok := output.(*model.CallbackOutput)
+	if !ok || o.Extra == nil {
+		return "", false
+	}
+	s, ok := o.Extra[ExtraStatus].(string)
+	return s, ok
+}
+
+// IsHit 响应是否来自缓存
+func IsHit(msg *schema.Message) bool {
+	if msg == nil || msg.Extra == nil {
+		return false
+	}
+	s, _ := msg.Extra[ExtraStatus].(string)
+	return s == StatusHit
+}
+
+type options struct {
+	bypass  bool
+	noStore bool
+}
+
+// WithBypass 本次调用跳过缓存读取，新的响应仍会写入缓存（用于刷新）
+func WithBypass() model.Option {
+	return model.WrapImplSpecificOptFn(func(o *options) { o.bypass = true })
+}
+
+// WithNoStore 本次调用的响应不写入缓存
+func WithNoStore() model.Option {
+	return model.WrapImplSpecificOptFn(func(o *options) { o.noStore = true })
+}
+
+// Config 缓存配置
+type Config struct {
+	Backend Backend
+	// TTL 缓存有效期，0 表示永不过期
+	TTL time.Duration
+	// Namespace 参与 key 计算，用于区分不同模型及其默认参数
+	Namespace string
+	// ChunkSize 命中时 Stream 每个分片的字符数，默认 16
+	ChunkSize int
+}
+
+// Stats 命中统计
+type Stats struct {
+	Hits     int64
+	Misses   int64
+	Bypassed int64
+}
+
+// ChatModel 带缓存的 ChatModel
+type ChatModel struct {
+	inner model.BaseChatModel
+	cfg   Config
+	tools []*schema.ToolInfo
+
+	stats *stats
+}
+
+type stats struct {
+	hits, misses, bypassed atomic.Int64
+}
+
+var _ model.ToolCallingChatModel = (*ChatModel)(nil)
+
+// New 为 inner 增加缓存
+func New(inner model.BaseChatModel, cfg *Config) (*ChatModel, error) {
+	if cfg == nil || cfg.Backend == nil {
+		return nil, errors.New("cache: 缺少缓存存储")
+	}
+	c := *cfg
+	if c.ChunkSize <= 0 {
+		c.ChunkSize = 16
+	}
+	return &ChatModel{inner: inner, cfg: c, stats: &stats{}}, nil
+}
+
+// Stats 返回命中统计，WithTools 得到的模型共享同一份统计
+func (m *ChatModel) Stats() Stats {
+	return Stats{
+		Hits:     m.stats.hits.Load(),
+		Misses:   m.stats.misses.Load(),
+		Bypassed: m.stats.bypassed.Load(),
+	}
+}
+
+func (m *ChatModel) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	key, status, entry := m.lookup(ctx, in, opts)
+
+	return modelcb.GenerateWithOutput(ctx, m.GetType(), in, opts, output(status), func(cbCtx context.Context) (*schema.Message, error) {
+		if entry != nil {
+			return withStatus(entry.Message), nil
+		}
+		// 底层模型使用调用方的 ctx，回调以底层模型自己的身份触发
+		msg, err := m.innerGenerate(ctx, in, opts)
+		if err != nil {
+			return nil, err
+		}
+		m.store(ctx, key, msg, opts)
+		return msg, nil
+	})
+}
+
+func (m *ChatModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	key, status, entry := m.lookup(ctx, in, opts)
+
+	return modelcb.StreamWithOutput(ctx, m.GetType(), in, opts, output(status), func(cbCtx context.Context) (*schema.StreamReader[*schema.Message], error) {
+		if entry != nil {
+			return schema.StreamReaderFromArray(split(withStatus(entry.Message), m.cfg.ChunkSize)), nil
+		}
+
+		sr, err := m.innerStream(ctx, in, opts)
+		if err != nil {
+			return nil, err
+		}
+		// 边转发边收集分片，完整读完后写入缓存
+		out, sw := schema.Pipe[*schema.Message](1)
+		go func() {
+			defer sr.Close()
+			defer sw.Close()
+
+			var chunks []*schema.Message
+			for {
+				chunk, err := sr.Recv()
+				if errors.Is(err, io.EOF) {
+					if msg, err := schema.ConcatMessages(chunks); err == nil {
+						m.store(ctx, key, msg, opts)
+					}
+					return
+				}
+				if err != nil {
+					sw.Send(nil, err)
+					return
+				}
+				chunks = append(chunks, chunk)
+				if closed := sw.Send(chunk, nil); closed {
+					// 调用方提前关闭，响应不完整，不写缓存
+					return
+				}
+			}
+		}()
+		return out, nil
+	})
+}
+
+func (m *ChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	tc, ok := m.inner.(model.ToolCallingChatModel)
+	if !ok {
+		return nil, fmt.Errorf("cache: 底层模型 %T 不支持 WithTools", m.inner)
+	}
+	inner, err := tc.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	nm := *m
+	nm.inner = inner
+	nm.tools = tools
+	return &nm, nil
+}
+
+func (m *ChatModel) GetType() string {
+	return "Cache"
+}
+
+// IsCallbacksEnabled 缓存自己触发回调，未命中时底层模型的回调照常触发
+func (m *ChatModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+// innerGenerate 底层模型没有实现回调时代为触发，保证真实用量能被统计
+func (m *ChatModel) innerGenerate(ctx context.Context, in []*schema.Message, opts []model.Option) (*schema.Message, error) {
+	if components.IsCallbacksEnabled(m.inner) {
+		return m.inner.Generate(ctx, in, opts...)
+	}
+	typ, _ := components.GetType(m.inner)
+	return modelcb.Generate(ctx, typ, in, opts, func(ctx context.Context) (*schema.Message, error) {
+		return m.inner.Generate(ctx, in, opts...)
+	})
+}
+
+func (m *ChatModel) innerStream(ctx context.Context, in []*schema.Message, opts []model.Option) (*schema.StreamReader[*schema.Message], error) {
+	if components.IsCallbacksEnabled(m.inner) {
+		return m.inner.Stream(ctx, in, opts...)
+	}
+	typ, _ := components.GetType(m.inner)
+	return modelcb.Stream(ctx, typ, in, opts, func(ctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+		return m.inner.Stream(ctx, in, opts...)
+	})
+}
+
+// lookup 查询缓存，返回 key、缓存状态和命中的记录
+func (m *ChatModel) lookup(ctx context.Context, in []*schema.Message, opts []model.Option) (string, string, *Entry) {
+	tools := m.tools
+	if o := model.GetCommonOptions(nil, opts...); o.Tools != nil {
+		tools = o.Tools
+	}
+	key := Key(m.cfg.Namespace, in, tools, opts...)
+
+	if model.GetImplSpecificOptions(&options{}, opts...).bypass {
+		m.stats.bypassed.Add(1)
+		return key, StatusBypass, nil
+	}
+
+	entry, err := m.cfg.Backend.Get(ctx, key)
+	if err != nil {
+		log.Printf("cache: 读取缓存失败: %v", err)
+	}
+	if entry != nil && m.cfg.TTL > 0 && time.Since(entry.CreatedAt) > m.cfg.TTL {
+		_ = m.cfg.Backend.Delete(ctx, key)
+		entry = nil
+	}
+	if entry == nil || entry.Message == nil {
+		m.stats.misses.Add(1)
+		return key, StatusMiss, nil
+	}
+	m.stats.hits.Add(1)
+	return key, StatusHit, entry
+}
+
+func (m *ChatModel) store(ctx context.Context, key string, msg *schema.Message, opts []model.Option) {
+	if model.GetImplSpecificOptions(&options{}, opts...).noStore {
+		return
+	}
+	if err := m.cfg.Backend.Set(ctx, key, &Entry{Message: msg, CreatedAt: time.Now()}); err != nil {
+		log.Printf("cache: 写入缓存失败: %v", err)
+	}
+}
+
+// output 缓存回调的输出：用量记为 0，避免与底层模型的回调重复统计
+func output(status string) modelcb.OutputFunc {
+	return func(msg *schema.Message, cfg *model.Config) *model.CallbackOutput {
+		return &model.CallbackOutput{
+			Message:    msg,
+			Config:     cfg,
+			TokenUsage: &model.TokenUsage{},
+			Extra:      map[string]any{ExtraStatus: status},
+		}
+	}
+}
+
+// withStatus 返回标记了命中的副本，避免调用方修改缓存中的消息
+func withStatus(msg *schema.Message) *schema.Message {
+	cm := *msg
+	cm.Extra = make(map[string]any, len(msg.Extra)+1)
+	for k, v := range msg.Extra {
+		cm.Extra[k] = v
+	}
+	cm.Extra[ExtraStatus] = StatusHit
+	return &cm
+}
+
+// split 把完整响应切成流式分片：推理内容、正文按 size 个字符切分，
+// 工具调用和 ResponseMeta 放在最后一个分片，Extra 只放在第一个分片，合并后与原消息一致
+func split(msg *schema.Message, size int) []*schema.Message {
+	var chunks []*schema.Message
+	for _, part := range chunkRunes(msg.ReasoningContent, size) {
+		chunks = append(chunks, &schema.Message{Role: msg.Role, ReasoningContent: part})
+	}
+	for _, part := range chunkRunes(msg.Content, size) {
+		chunks = append(chunks, &schema.Message{Role: msg.Role, Content: part})
+	}
+	last := &schema.Message{Role: msg.Role, Name: msg.Name, ToolCalls: msg.ToolCalls, ResponseMeta: msg.ResponseMeta}
+	chunks = append(chunks, last)
+	chunks[0].Extra = msg.Extra
+	return chunks
+}
+
+func chunkRunes(s string, size int) []string {
+	var out []string
+	r := []rune(s)
+	for len(r) > 0 {
+		n := min(size, len(r))
+		out = append(out, string(r[:n]))
+		r = r[n:]
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// keyMessage 参与计算 key 的消息字段，忽略工具调用 ID 等每次请求都会变化的内容
+type keyMessage struct {
+	Role      schema.RoleType `json:"role"`
+	Name      string          `json:"name,omitempty"`
+	Content   string          `json:"content"`
+	ToolCalls []keyToolCall   `json:"tool_calls,omitempty"`
+}
+
+type keyToolCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type keyTool struct {
+	Name   string          `json:"name"`
+	Desc   string          `json:"desc"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type keyData struct {
+	Namespace  string             `json:"namespace"`
+	Messages   []keyMessage       `json:"messages"`
+	Tools      []keyTool          `json:"tools,omitempty"`
+	Model      *string            `json:"model,omitempty"`
+	Temp       *float32           `json:"temperature,omitempty"`
+	TopP       *float32           `json:"top_p,omitempty"`
+	MaxTokens  *int               `json:"max_tokens,omitempty"`
+	Stop       []string           `json:"stop,omitempty"`
+	ToolChoice *schema.ToolChoice `json:"tool_choice,omitempty"`
+}
+
+// Key 根据规范化后的消息、工具定义和生成参数计算缓存 key。
+//
+// 内容会统一换行符并去掉首尾空白，工具调用参数按 JSON 重新序列化（键有序），
+// 工具调用 ID 不参与计算。namespace 用于区分模型和 profile 中的默认参数。
+func Key(namespace string, in []*schema.Message, tools []*schema.ToolInfo, opts ...model.Option) string {
+	o := model.GetCommonOptions(nil, opts...)
+	d := keyData{
+		Namespace:  namespace,
+		Model:      o.Model,
+		Temp:       o.Temperature,
+		TopP:       o.TopP,
+		MaxTokens:  o.MaxTokens,
+		Stop:       o.Stop,
+		ToolChoice: o.ToolChoice,
+	}
+	for _, m := range in {
+		km := keyMessage{Role: m.Role, Name: m.Name, Content: normalize(m.Content)}
+		for _, tc := range m.ToolCalls {
+			km.ToolCalls = append(km.ToolCalls, keyToolCall{Name: tc.Function.Name, Arguments: canonicalJSON(tc.Function.Arguments)})
+		}
+		d.Messages = append(d.Messages, km)
+	}
+	for _, t := range tools {
+		kt := keyTool{Name: t.Name, Desc: normalize(t.Desc)}
+		if t.ParamsOneOf != nil {
+			if s, err := t.ParamsOneOf.ToJSONSchema(); err == nil && s != nil {
+				kt.Params, _ = json.Marshal(s)
+			}
+		}
+		d.Tools = append(d.Tools, kt)
+	}
+
+	data, _ := json.Marshal(d)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func normalize(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+}
+
+// canonicalJSON 重新序列化 JSON，使键顺序和空白一致；不是合法 JSON 时原样返回
+func canonicalJSON(s string) string {
+	var v any
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return s
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return s
+	}
+	return strings.TrimSpace(buf.String())
+}
@@ -0,0 +1,26 @@
+package chatmodel
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/eino/components/embedding"
+
+	"eino-tutorial/internal/ratelimit"
+)
+
+// LimitEmbedder 使用默认配置中 provider 的 rate_limits 限制 emb，与同一提供方的其它调用共享进程内的配额
+func LimitEmbedder(emb embedding.Embedder, provider string) (embedding.Embedder, error) {
+	cfg, err := Default()
+	if err != nil {
+		return nil, fmt.Errorf("加载模型配置失败: %w", err)
+	}
+	return cfg.LimitEmbedder(emb, provider), nil
+}
+
+// LimitEmbedder 用 ratelimit.Default() 中 provider 的配额包装 emb，没有配置限额的提供方调用时不排队
+func (c *Config) LimitEmbedder(emb embedding.Embedder, provider string) embedding.Embedder {
+	if lim, ok := c.RateLimits[provider]; ok {
+		ratelimit.Default().SetLimits(provider, lim)
+	}
+	return ratelimit.WrapEmbedder(emb, ratelimit.Default(), provider)
+}
@@ -0,0 +1,250 @@
+// Package chatmodel 提供按命名 profile 创建 ChatModel 的统一工厂。
+//
+// 各章节示例不再直接调用 deepseek.NewChatModel，而是：
+//
+//	chatModel, err := chatmodel.New(ctx, "precise")
+//
+// 切换提供方、模型或采样参数只需要修改 configs/models.yaml 或环境变量。
+// 设置 EINO_REPLAY_MODE 后创建的模型会自动套上录制 / 回放层，见 replay 包；
+// 设置 EINO_CACHE 后会套上响应缓存，见 cache 包。
+// 配置了 rate_limits 的提供方，其模型共享进程内同一个限流器，见 ratelimit 包；Embedding 模型用 LimitEmbedder 接入。
+// 采样参数在创建时和每次调用前按模型能力表校验，见 sampling 包。
+// 最外层统一处理推理模型的思考过程：与回答分开，并且不会随历史消息发回模型，见 reasoning 包。
+package chatmodel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino-ext/components/model/deepseek"
+	"github.com/cloudwego/eino/components/model"
+
+	"eino-tutorial/internal/cache"
+	"eino-tutorial/internal/ratelimit"
+	"eino-tutorial/internal/reasoning"
+	"eino-tutorial/internal/replay"
+	"eino-tutorial/internal/retry"
+	"eino-tutorial/internal/sampling"
+)
+
+// ProviderFunc 根据 profile 创建具体提供方的 ChatModel
+type ProviderFunc func(ctx context.Context, p *Profile) (model.ToolCallingChatModel, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]ProviderFunc{
+		"deepseek": newDeepSeek,
+	}
+)
+
+// RegisterProvider 注册一个提供方，同名时覆盖
+func RegisterProvider(name string, fn ProviderFunc) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = fn
+}
+
+// Providers 返回已注册的提供方名称（已排序）
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	defaultOnce sync.Once
+	defaultCfg  *Config
+	defaultErr  error
+)
+
+// Default 返回进程内共享的默认配置，只加载一次
+func Default() (*Config, error) {
+	defaultOnce.Do(func() {
+		defaultCfg, defaultErr = LoadDefaultConfig()
+	})
+	return defaultCfg, defaultErr
+}
+
+// New 使用默认配置创建指定 profile 的 ChatModel，name 为空时使用默认 profile
+func New(ctx context.Context, name string) (model.ToolCallingChatModel, error) {
+	cfg, err := Default()
+	if err != nil {
+		return nil, fmt.Errorf("加载模型配置失败: %w", err)
+	}
+	return cfg.New(ctx, name)
+}
+
+// New 创建指定 profile 的 ChatModel
+func (c *Config) New(ctx context.Context, name string) (model.ToolCallingChatModel, error) {
+	p, err := c.Profile(name)
+	if err != nil {
+		return nil, err
+	}
+	lim, ok := c.RateLimits[p.Provider]
+	if !ok {
+		return NewFromProfile(ctx, p)
+	}
+	return newFromProfile(ctx, p, &lim)
+}
+
+// NewFromProfile 直接根据 profile 创建 ChatModel，不做限流
+func NewFromProfile(ctx context.Context, p *Profile) (model.ToolCallingChatModel, error) {
+	return newFromProfile(ctx, p, nil)
+}
+
+// newFromProfile 依次套上限流、录制回放、缓存、采样参数校验和推理内容处理：回放和缓存命中不占用限流配额
+func newFromProfile(ctx context.Context, p *Profile, lim *ratelimit.Limits) (model.ToolCallingChatModel, error) {
+	target := p.Provider + "/" + p.Model
+	caps := sampling.Lookup(p.Provider, p.Model)
+	if err := caps.Validate(target, p.Params); err != nil {
+		return nil, err
+	}
+
+	cm, err := newProvider(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if lim != nil {
+		// 同一提供方的所有模型共用一份配额
+		ratelimit.Default().SetLimits(p.Provider, *lim)
+		cm = ratelimit.WrapChatModel(cm, ratelimit.Default(), p.Provider)
+	}
+	if cm, err = withReplay(cm, p); err != nil {
+		return nil, err
+	}
+	if cm, err = withCache(cm, p); err != nil {
+		return nil, err
+	}
+	return reasoning.Wrap(sampling.Wrap(cm, caps, target)), nil
+}
+
+func withReplay(cm model.ToolCallingChatModel, p *Profile) (model.ToolCallingChatModel, error) {
+	mode, err := replay.ModeFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if mode == replay.ModeOff {
+		return cm, nil
+	}
+	// 按提供方和模型分目录保存录制记录
+	return replay.New(cm, &replay.Config{
+		Mode: mode,
+		Dir:  filepath.Join(replay.DirFromEnv(), p.Provider, p.Model),
+	})
+}
+
+const (
+	// EnvCache 启用响应缓存：memory 或 disk
+	EnvCache = "EINO_CACHE"
+	// EnvCacheDir 磁盘缓存目录，默认 .cache/eino
+	EnvCacheDir = "EINO_CACHE_DIR"
+	// EnvCacheTTL 缓存有效期，如 1h，默认不过期
+	EnvCacheTTL = "EINO_CACHE_TTL"
+)
+
+var (
+	memoryCacheOnce sync.Once
+	memoryCache     *cache.LRU
+)
+
+func withCache(cm model.ToolCallingChatModel, p *Profile) (model.ToolCallingChatModel, error) {
+	var backend cache.Backend
+	switch kind := os.Getenv(EnvCache); kind {
+	case "":
+		return cm, nil
+	case "memory":
+		// 进程内的模型共享同一个 LRU
+		memoryCacheOnce.Do(func() { memoryCache = cache.NewLRU(0) })
+		backend = memoryCache
+	case "disk":
+		dir := os.Getenv(EnvCacheDir)
+		if dir == "" {
+			dir = filepath.Join(".cache", "eino")
+		}
+		disk, err := cache.NewDisk(dir)
+		if err != nil {
+			return nil, err
+		}
+		backend = disk
+	default:
+		return nil, fmt.Errorf("无效的 %s=%q，可选: memory, disk", EnvCache, kind)
+	}
+
+	var ttl time.Duration
+	if v := os.Getenv(EnvCacheTTL); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %w", EnvCacheTTL, err)
+		}
+		ttl = d
+	}
+	return cache.New(cm, &cache.Config{Backend: backend, TTL: ttl, Namespace: namespace(p)})
+}
+
+// namespace profile 中影响输出的字段，不同模型或默认参数的缓存互不干扰
+func namespace(p *Profile) string {
+	np := *p
+	// 预设已经展开到采样参数中，上下文窗口只影响本地裁剪
+	np.APIKey, np.APIKeyEnv, np.Timeout, np.Preset, np.ContextWindow = "", "", "", "", 0
+	data, _ := json.Marshal(&np)
+	return string(data)
+}
+
+func newProvider(ctx context.Context, p *Profile) (model.ToolCallingChatModel, error) {
+	providersMu.RLock()
+	fn, ok := providers[p.Provider]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未注册的模型提供方 %q，可用: %s", p.Provider, strings.Join(Providers(), ", "))
+	}
+
+	cm, err := fn(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("创建 %s/%s 失败: %w", p.Provider, p.Model, err)
+	}
+	return cm, nil
+}
+
+func newDeepSeek(ctx context.Context, p *Profile) (model.ToolCallingChatModel, error) {
+	timeout, err := p.TimeoutDuration()
+	if err != nil {
+		return nil, fmt.Errorf("解析 timeout 失败: %w", err)
+	}
+
+	cfg := &deepseek.ChatModelConfig{
+		APIKey:    p.ResolveAPIKey(),
+		Model:     p.Model,
+		BaseURL:   p.BaseURL,
+		Timeout:   timeout,
+		MaxTokens: p.MaxTokens,
+		Stop:      p.Stop,
+		// 把 Retry-After 附在错误上，供 retry 包使用
+		HTTPClient: &http.Client{Transport: retry.Transport(nil)},
+	}
+	if p.Temperature != nil {
+		cfg.Temperature = *p.Temperature
+	}
+	if p.TopP != nil {
+		cfg.TopP = *p.TopP
+	}
+	if p.PresencePenalty != nil {
+		cfg.PresencePenalty = *p.PresencePenalty
+	}
+	if p.FrequencyPenalty != nil {
+		cfg.FrequencyPenalty = *p.FrequencyPenalty
+	}
+	return deepseek.NewChatModel(ctx, cfg)
+}
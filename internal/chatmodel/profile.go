@@ -0,0 +1,308 @@
+package chatmodel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"eino-tutorial/internal/ratelimit"
+	"eino-tutorial/internal/sampling"
+	"eino-tutorial/internal/tokens"
+)
+
+const (
+	// EnvConfigPath 指定配置文件路径的环境变量
+	EnvConfigPath = "EINO_MODEL_CONFIG"
+	// EnvProfile 覆盖默认 profile 名称的环境变量
+	EnvProfile = "EINO_MODEL_PROFILE"
+
+	// DefaultConfigPath 未设置 EnvConfigPath 时查找的配置文件（相对于运行目录）
+	DefaultConfigPath = "configs/models.yaml"
+)
+
+// Profile 一组命名的模型配置：使用哪个提供方、哪个模型以及采样参数
+type Profile struct {
+	Provider  string `json:"provider" yaml:"provider"`
+	Model     string `json:"model" yaml:"model"`
+	BaseURL   string `json:"base_url,omitempty" yaml:"base_url,omitempty"`
+	APIKey    string `json:"api_key,omitempty" yaml:"api_key,omitempty"`         // 不建议写在文件里，优先使用 APIKeyEnv
+	APIKeyEnv string `json:"api_key_env,omitempty" yaml:"api_key_env,omitempty"` // 保存 API Key 的环境变量名
+	Timeout   string `json:"timeout,omitempty" yaml:"timeout,omitempty"`         // 例如 "30s"
+	// ContextWindow 上下文窗口的 token 数，0 表示按 tokens 包登记的值
+	ContextWindow int `json:"context_window,omitempty" yaml:"context_window,omitempty"`
+
+	// Preset 采样参数预设，见 sampling 包；下面显式填写的采样参数优先
+	Preset string `json:"preset,omitempty" yaml:"preset,omitempty"`
+	// 采样参数，nil 表示使用提供方默认值
+	sampling.Params `yaml:",inline"`
+}
+
+// LegacyAPIKeyEnvs 改用 profile 之前示例读取的环境变量，APIKeyEnv 没有值时依次查找，兼容已有的设置
+var LegacyAPIKeyEnvs = []string{"API_KEY", "CHAT_MODEL_API_KEY"}
+
+// ResolveAPIKey 返回实际使用的 API Key：依次为 APIKey、APIKeyEnv 指定的环境变量和 LegacyAPIKeyEnvs
+func (p *Profile) ResolveAPIKey() string {
+	if p.APIKey != "" {
+		return p.APIKey
+	}
+	if key := os.Getenv(p.APIKeyEnv); key != "" {
+		return key
+	}
+	for _, env := range LegacyAPIKeyEnvs {
+		if key := os.Getenv(env); key != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+// Tokenizer 返回估算该模型 token 数的参数，ContextWindow 覆盖登记的上下文窗口
+func (p *Profile) Tokenizer() *tokens.Tokenizer {
+	t := tokens.Lookup(p.Provider, p.Model)
+	if p.ContextWindow > 0 {
+		c := *t
+		c.ContextWindow = p.ContextWindow
+		return &c
+	}
+	return t
+}
+
+// TimeoutDuration 解析 Timeout 字段，未配置时返回 0
+func (p *Profile) TimeoutDuration() (time.Duration, error) {
+	if p.Timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(p.Timeout)
+}
+
+// Config 配置文件的内容：默认 profile、所有命名 profile、自定义采样预设和各提供方的限流配置
+type Config struct {
+	Default  string              `json:"default" yaml:"default"`
+	Profiles map[string]*Profile `json:"profiles" yaml:"profiles"`
+	// Presets 自定义的采样预设，与 sampling 包内置的预设同名时优先
+	Presets map[string]sampling.Params `json:"presets,omitempty" yaml:"presets,omitempty"`
+	// RateLimits 按提供方配置的限额，同一提供方的所有模型共享，见 ratelimit 包
+	RateLimits map[string]ratelimit.Limits `json:"rate_limits,omitempty" yaml:"rate_limits,omitempty"`
+}
+
+// ptr 返回 v 的指针，用于填写可选的采样参数
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// Preset 返回指定名称的采样预设，先查找配置文件中的 presets，再查找内置预设
+func (c *Config) Preset(name string) (sampling.Params, error) {
+	if p, ok := c.Presets[name]; ok {
+		return sampling.Params{}.Merge(p), nil
+	}
+	preset, err := sampling.Get(name)
+	if err != nil {
+		return sampling.Params{}, err
+	}
+	return preset.Params, nil
+}
+
+// DefaultConfig 找不到配置文件时使用的内置配置
+func DefaultConfig() *Config {
+	deepseek := func(modelName string) *Profile {
+		return &Profile{
+			Provider:  "deepseek",
+			Model:     modelName,
+			BaseURL:   "https://api.deepseek.com",
+			APIKeyEnv: "DEEPSEEK_API_KEY",
+		}
+	}
+
+	precise := deepseek("deepseek-chat")
+	precise.Preset = "precise"
+
+	balanced := deepseek("deepseek-chat")
+	balanced.Preset = "balanced"
+
+	creative := deepseek("deepseek-chat")
+	creative.Preset = "creative"
+
+	code := deepseek("deepseek-chat")
+	code.Preset = "code"
+
+	translation := deepseek("deepseek-chat")
+	translation.Preset = "translation"
+
+	advanced := deepseek("deepseek-chat")
+	advanced.Preset = "balanced"
+	advanced.Timeout = "30s"
+	advanced.PresencePenalty = ptr[float32](0.6)
+	advanced.FrequencyPenalty = ptr[float32](0.5)
+
+	reasoner := deepseek("deepseek-reasoner")
+	reasoner.Timeout = "120s"
+
+	return &Config{
+		Default: "balanced",
+		Profiles: map[string]*Profile{
+			"precise":     precise,
+			"balanced":    balanced,
+			"creative":    creative,
+			"code":        code,
+			"translation": translation,
+			"advanced":    advanced,
+			"reasoner":    reasoner,
+		},
+		RateLimits: map[string]ratelimit.Limits{
+			"deepseek": {RequestsPerMinute: 60, MaxInFlight: 4},
+			"ark":      {RequestsPerMinute: 120, MaxInFlight: 4},
+		},
+	}
+}
+
+// LoadConfig 从 YAML 或 JSON 文件加载配置，格式由扩展名决定
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, cfg)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	default:
+		return nil, fmt.Errorf("不支持的配置文件格式: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	if len(cfg.Profiles) == 0 {
+		return nil, fmt.Errorf("配置文件 %s 中没有任何 profile", path)
+	}
+	return cfg, nil
+}
+
+// LoadDefaultConfig 按 EnvConfigPath -> DefaultConfigPath -> DefaultConfig 的顺序加载配置
+func LoadDefaultConfig() (*Config, error) {
+	if path := os.Getenv(EnvConfigPath); path != "" {
+		return LoadConfig(path)
+	}
+	cfg, err := LoadConfig(DefaultConfigPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultConfig(), nil
+	}
+	return cfg, err
+}
+
+// Names 返回所有 profile 名称（已排序）
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile 返回叠加了环境变量覆盖之后的 profile 副本，name 为空时使用默认 profile
+//
+// 环境变量覆盖规则（NAME 为大写的 profile 名，"-" 替换为 "_"）：
+//
+//	EINO_MODEL_PROFILE          默认 profile 名称
+//	EINO_<NAME>_PROVIDER        提供方
+//	EINO_<NAME>_MODEL           模型名称
+//	EINO_<NAME>_BASE_URL        接口地址
+//	EINO_<NAME>_API_KEY_ENV     保存 API Key 的环境变量名
+//	EINO_<NAME>_TIMEOUT         超时时间，例如 30s
+//	EINO_<NAME>_PRESET          采样预设
+//	EINO_<NAME>_TEMPERATURE     温度
+//	EINO_<NAME>_TOP_P           核采样
+//	EINO_<NAME>_MAX_TOKENS      最大 Token 数
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = os.Getenv(EnvProfile)
+	}
+	if name == "" {
+		name = c.Default
+	}
+	base, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("未知的模型 profile %q，可用: %s", name, strings.Join(c.Names(), ", "))
+	}
+
+	p := *base
+	p.Stop = append([]string(nil), base.Stop...)
+	if err := applyEnvOverrides(name, &p); err != nil {
+		return nil, fmt.Errorf("profile %q 环境变量覆盖失败: %w", name, err)
+	}
+	if p.Preset != "" {
+		params, err := c.Preset(p.Preset)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+		// profile 中显式填写的参数覆盖预设
+		p.Params = params.Merge(p.Params)
+	}
+	if p.Provider == "" {
+		return nil, fmt.Errorf("profile %q 未指定 provider", name)
+	}
+	return &p, nil
+}
+
+func applyEnvOverrides(name string, p *Profile) error {
+	prefix := "EINO_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+	lookup := func(key string) (string, bool) {
+		v, ok := os.LookupEnv(prefix + key)
+		return v, ok && v != ""
+	}
+
+	if v, ok := lookup("PROVIDER"); ok {
+		p.Provider = v
+	}
+	if v, ok := lookup("MODEL"); ok {
+		p.Model = v
+	}
+	if v, ok := lookup("BASE_URL"); ok {
+		p.BaseURL = v
+	}
+	if v, ok := lookup("API_KEY_ENV"); ok {
+		p.APIKeyEnv = v
+	}
+	if v, ok := lookup("TIMEOUT"); ok {
+		p.Timeout = v
+	}
+	if v, ok := lookup("PRESET"); ok {
+		p.Preset = v
+	}
+
+	floats := map[string]**float32{
+		"TEMPERATURE": &p.Temperature,
+		"TOP_P":       &p.TopP,
+	}
+	for key, field := range floats {
+		v, ok := lookup(key)
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return fmt.Errorf("%s%s=%q: %w", prefix, key, v, err)
+		}
+		*field = ptr(float32(f))
+	}
+
+	if v, ok := lookup("MAX_TOKENS"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%sMAX_TOKENS=%q: %w", prefix, v, err)
+		}
+		p.MaxTokens = n
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package chatmodel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveAPIKeyLegacyEnv(t *testing.T) {
+	t.Setenv("DEEPSEEK_API_KEY", "")
+	t.Setenv("API_KEY", "")
+	t.Setenv("CHAT_MODEL_API_KEY", "legacy")
+	p := &Profile{APIKeyEnv: "DEEPSEEK_API_KEY"}
+	if got := p.ResolveAPIKey(); got != "legacy" {
+		t.Errorf("只设置 CHAT_MODEL_API_KEY 时 = %q", got)
+	}
+
+	t.Setenv("API_KEY", "old")
+	if got := p.ResolveAPIKey(); got != "old" {
+		t.Errorf("API_KEY 应优先于 CHAT_MODEL_API_KEY，得到 %q", got)
+	}
+
+	t.Setenv("DEEPSEEK_API_KEY", "new")
+	if got := p.ResolveAPIKey(); got != "new" {
+		t.Errorf("api_key_env 应优先于旧变量，得到 %q", got)
+	}
+
+	p.APIKey = "inline"
+	if got := p.ResolveAPIKey(); got != "inline" {
+		t.Errorf("api_key 应优先，得到 %q", got)
+	}
+}
+
+func TestAdvancedProfile(t *testing.T) {
+	fileCfg, err := LoadConfig("../../configs/models.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, cfg := range map[string]*Config{"configs/models.yaml": fileCfg, "DefaultConfig": DefaultConfig()} {
+		p, err := cfg.Profile("advanced")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		timeout, err := p.TimeoutDuration()
+		if err != nil || timeout != 30*time.Second {
+			t.Errorf("%s: Timeout = %v, %v", name, timeout, err)
+		}
+		if p.PresencePenalty == nil || *p.PresencePenalty != 0.6 || p.FrequencyPenalty == nil || *p.FrequencyPenalty != 0.5 {
+			t.Errorf("%s: 惩罚参数 = %v / %v", name, p.PresencePenalty, p.FrequencyPenalty)
+		}
+		if p.Temperature == nil || *p.Temperature != 0.7 {
+			t.Errorf("%s: Temperature 应来自 balanced 预设，得到 %v", name, p.Temperature)
+		}
+	}
+}
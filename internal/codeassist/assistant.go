@@ -0,0 +1,71 @@
+// Package codeassist 基于 ChatModel 的代码助手：解释和优化代码片段、审查多个文件并给出结构化的问题列表，
+// 以及按要求重构 Go 文件并返回 unified diff。
+//
+// 重构结果在返回前会用 go/parser 解析、用 gofmt 格式化，不能通过检查时把错误发回模型修正。
+package codeassist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/structured"
+)
+
+// Assistant 代码助手，可以并发使用
+type Assistant struct {
+	model    model.BaseChatModel
+	reviewer *structured.Extractor[review]
+}
+
+// New 用 cm 创建代码助手，建议使用低温度的配置（如 code profile）
+func New(cm model.BaseChatModel) (*Assistant, error) {
+	if cm == nil {
+		return nil, errors.New("codeassist: 缺少 ChatModel")
+	}
+	reviewer, err := structured.New[review](&structured.Config{Model: cm})
+	if err != nil {
+		return nil, fmt.Errorf("codeassist: %w", err)
+	}
+	return &Assistant{model: cm, reviewer: reviewer}, nil
+}
+
+var explainTemplate = prompt.FromMessages(schema.FString,
+	schema.SystemMessage("你是一个专业的代码助手，擅长解释各种编程语言的代码片段。"),
+	schema.UserMessage("请解释以下{language}代码的功能和作用：\n\n```{language}\n{code}\n```"),
+)
+
+var optimizeTemplate = prompt.FromMessages(schema.FString,
+	schema.SystemMessage("你是一个专业的代码助手，擅长优化各种编程语言的代码片段。请你从以下方面进行优化：\n"+
+		"1. 提高代码性能；\n"+
+		"2. 增强代码可读性；\n"+
+		"3. 遵循最佳实践；\n"+
+		"4. 错误处理和边界情况。"),
+	schema.UserMessage("请优化以下{language}代码，提高其性能和可读性：\n\n```{language}\n{code}\n```"),
+)
+
+// ExplainCode 解释代码片段的功能和作用
+func (a *Assistant) ExplainCode(ctx context.Context, code, language string) (string, error) {
+	return a.generate(ctx, explainTemplate, map[string]any{"language": language, "code": code})
+}
+
+// OptimizeCode 给出优化后的代码和说明
+func (a *Assistant) OptimizeCode(ctx context.Context, code, language string) (string, error) {
+	return a.generate(ctx, optimizeTemplate, map[string]any{"language": language, "code": code})
+}
+
+func (a *Assistant) generate(ctx context.Context, tpl prompt.ChatTemplate, vars map[string]any) (string, error) {
+	msgs, err := tpl.Format(ctx, vars)
+	if err != nil {
+		return "", fmt.Errorf("codeassist: 格式化失败: %w", err)
+	}
+	resp, err := a.model.Generate(ctx, msgs)
+	if err != nil {
+		return "", fmt.Errorf("codeassist: 生成失败: %w", err)
+	}
+	return resp.Content, nil
+}
@@ -0,0 +1,152 @@
+package codeassist
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffCells 逐行 LCS 表的上限，超过时把中间不同的部分整体作为删除加新增
+const maxDiffCells = 4_000_000
+
+// diffContext unified diff 中每个改动前后保留的上下文行数
+const diffContext = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type diffOp struct {
+	kind opKind
+	text string
+	// a、b 该行在旧、新文件中的下标（从 0 开始），新增行的 a 和删除行的 b 为插入位置
+	a, b int
+}
+
+// UnifiedDiff 按行比较 a 和 b，返回 unified 格式的差异（与 diff -u 相同），没有差异时返回空串
+func UnifiedDiff(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+		// 向前取上下文，向后合并间隔不超过 2*diffContext 的改动
+		start := max(0, i-diffContext)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != opEqual {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		end = min(len(ops), end+diffContext)
+		writeHunk(&sb, ops[start:end])
+		i = end
+	}
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, ops []diffOp) {
+	var na, nb int
+	for _, op := range ops {
+		if op.kind != opInsert {
+			na++
+		}
+		if op.kind != opDelete {
+			nb++
+		}
+	}
+	// 行号从 1 开始；某一侧没有行时，行号为改动位置之前的那一行
+	sa, sb0 := ops[0].a+1, ops[0].b+1
+	if na == 0 {
+		sa--
+	}
+	if nb == 0 {
+		sb0--
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", sa, na, sb0, nb)
+	for _, op := range ops {
+		sb.WriteByte(byte(op.kind))
+		sb.WriteString(op.text)
+		if !strings.HasSuffix(op.text, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// splitLines 按行切分并保留换行符
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines 去掉相同的首尾后，对中间部分做逐行 LCS
+func diffLines(a, b []string) []diffOp {
+	var ops []diffOp
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		ops = append(ops, diffOp{kind: opEqual, text: a[pre], a: pre, b: pre})
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	ma, mb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+
+	if len(ma)*len(mb) > maxDiffCells {
+		for i, line := range ma {
+			ops = append(ops, diffOp{kind: opDelete, text: line, a: pre + i, b: pre})
+		}
+		for j, line := range mb {
+			ops = append(ops, diffOp{kind: opInsert, text: line, a: pre + len(ma), b: pre + j})
+		}
+	} else {
+		// lcs[i][j] 为 ma[i:] 与 mb[j:] 的最长公共子序列长度
+		w := len(mb) + 1
+		lcs := make([]int32, (len(ma)+1)*w)
+		for i := len(ma) - 1; i >= 0; i-- {
+			for j := len(mb) - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+				} else {
+					lcs[i*w+j] = max(lcs[(i+1)*w+j], lcs[i*w+j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(ma) || j < len(mb) {
+			switch {
+			case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+				ops = append(ops, diffOp{kind: opEqual, text: ma[i], a: pre + i, b: pre + j})
+				i++
+				j++
+			case i < len(ma) && (j == len(mb) || lcs[(i+1)*w+j] >= lcs[i*w+j+1]):
+				ops = append(ops, diffOp{kind: opDelete, text: ma[i], a: pre + i, b: pre + j})
+				i++
+			default:
+				ops = append(ops, diffOp{kind: opInsert, text: mb[j], a: pre + i, b: pre + j})
+				j++
+			}
+		}
+	}
+
+	for k := suf; k > 0; k-- {
+		ops = append(ops, diffOp{kind: opEqual, text: a[len(a)-k], a: len(a) - k, b: len(b) - k})
+	}
+	return ops
+}
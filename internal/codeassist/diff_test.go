@@ -0,0 +1,125 @@
+package codeassist
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	cases := []struct {
+		name, a, b, want string
+	}{
+		{"相同", "a\n", "a\n", ""},
+		{"修改一行", "a\nb\nc\n", "a\nB\nc\n", "--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+		{"新文件", "", "n\n", "--- a/f\n+++ b/f\n@@ -0,0 +1,1 @@\n+n\n"},
+		{"删除全部", "x\n", "", "--- a/f\n+++ b/f\n@@ -1,1 +0,0 @@\n-x\n"},
+		{"末尾没有换行", "x\n", "x\ny", "--- a/f\n+++ b/f\n@@ -1,1 +1,2 @@\n x\n+y\n\\ No newline at end of file\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := UnifiedDiff("a/f", "b/f", c.a, c.b); got != c.want {
+				t.Errorf("UnifiedDiff =\n%s\n期望\n%s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d\n", i))
+	}
+	edit := func(at ...int) string {
+		out := append([]string(nil), lines...)
+		for _, i := range at {
+			out[i-1] = "changed\n"
+		}
+		return strings.Join(out, "")
+	}
+	old := strings.Join(lines, "")
+	// 间隔不超过 2*diffContext 行的改动合并成一个 hunk
+	if n := strings.Count(UnifiedDiff("a", "b", old, edit(2, 8)), "\n@@ "); n != 1 {
+		t.Errorf("相近的改动得到 %d 个 hunk，期望 1 个", n)
+	}
+	if n := strings.Count(UnifiedDiff("a", "b", old, edit(2, 19)), "\n@@ "); n != 2 {
+		t.Errorf("相距较远的改动得到 %d 个 hunk，期望 2 个", n)
+	}
+}
+
+// TestUnifiedDiffApplies 随机生成文件和修改，把 diff 应用到旧文件上应得到新文件
+func TestUnifiedDiffApplies(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	gen := func() string {
+		var sb strings.Builder
+		n := r.IntN(12)
+		for i := 0; i < n; i++ {
+			sb.WriteString(string(rune('a'+r.IntN(3))) + "\n")
+		}
+		if r.IntN(4) == 0 {
+			sb.WriteString("tail")
+		}
+		return sb.String()
+	}
+	for i := 0; i < 2000; i++ {
+		a, b := gen(), gen()
+		diff := UnifiedDiff("a", "b", a, b)
+		got, err := applyDiff(a, diff)
+		if err != nil || got != b {
+			t.Fatalf("应用 diff 失败: %v\n旧: %q\n新: %q\n得到: %q\ndiff:\n%s", err, a, b, got, diff)
+		}
+	}
+}
+
+// applyDiff 按 patch 的规则把 unified diff 应用到 old 上
+func applyDiff(old, diff string) (string, error) {
+	if diff == "" {
+		return old, nil
+	}
+	a := splitLines(old)
+	lines := splitLines(diff)[2:]
+	var out []string
+	pos := 0
+	for i := 0; i < len(lines); {
+		var sa, na, sb, nb int
+		if _, err := fmt.Sscanf(lines[i], "@@ -%d,%d +%d,%d @@", &sa, &na, &sb, &nb); err != nil {
+			return "", fmt.Errorf("无效的 hunk 头 %q", lines[i])
+		}
+		i++
+		start := sa - 1
+		if na == 0 {
+			start = sa
+		}
+		if start < pos || start > len(a) {
+			return "", fmt.Errorf("hunk 起始行 %d 无效", sa)
+		}
+		out = append(out, a[pos:start]...)
+		pos = start
+		for i < len(lines) && !strings.HasPrefix(lines[i], "@@") {
+			line := lines[i]
+			i++
+			text := line[1:]
+			if i < len(lines) && strings.HasPrefix(lines[i], `\ No newline`) {
+				text = strings.TrimSuffix(text, "\n")
+				i++
+			}
+			switch line[0] {
+			case ' ', '-':
+				if pos >= len(a) || a[pos] != text {
+					return "", fmt.Errorf("第 %d 行与上下文不符", pos+1)
+				}
+				if line[0] == ' ' {
+					out = append(out, text)
+				}
+				pos++
+			case '+':
+				out = append(out, text)
+			default:
+				return "", fmt.Errorf("无效的行 %q", line)
+			}
+		}
+	}
+	out = append(out, a[pos:]...)
+	return strings.Join(out, ""), nil
+}
@@ -0,0 +1,108 @@
+package codeassist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// maxRefactorRepairs 重构结果没有通过检查时最多让模型修正的次数
+const maxRefactorRepairs = 2
+
+// Refactoring 重构结果
+type Refactoring struct {
+	Path string
+	// Code gofmt 之后的完整文件
+	Code string
+	// Diff 相对原文件的 unified diff，没有改动时为空
+	Diff string
+	// Summary 模型对改动的说明
+	Summary string
+}
+
+const refactorPrompt = "你是一个 Go 重构专家。请按用户的要求重构文件。\n" +
+	"要求：\n" +
+	"1. 除非用户要求，不要改变代码的行为和导出的 API。\n" +
+	"2. 不要修改与要求无关的代码。\n" +
+	"3. 先用一两句话说明改动，然后在一个 ```go 代码块中输出重构后的完整文件。"
+
+// Refactor 按 instruction 重构一个 Go 文件。
+// 结果在返回前用 go/parser 解析并用 gofmt 格式化，未通过时把错误发回模型修正，最多 maxRefactorRepairs 次
+func (a *Assistant) Refactor(ctx context.Context, file File, instruction string) (*Refactoring, error) {
+	if !strings.HasSuffix(file.Path, ".go") {
+		return nil, fmt.Errorf("codeassist: 只支持重构 Go 文件: %s", file.Path)
+	}
+	msgs := []*schema.Message{
+		schema.SystemMessage(refactorPrompt),
+		schema.UserMessage(fmt.Sprintf("文件 %s：\n```go\n%s\n```\n\n重构要求：%s", file.Path, file.Content, instruction)),
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := a.model.Generate(ctx, msgs)
+		if err != nil {
+			return nil, fmt.Errorf("codeassist: 生成失败: %w", err)
+		}
+		summary, code := splitCode(resp.Content)
+		formatted, err := CheckGo(file.Path, file.Content, code)
+		if err == nil {
+			return &Refactoring{
+				Path:    file.Path,
+				Code:    formatted,
+				Diff:    UnifiedDiff("a/"+file.Path, "b/"+file.Path, file.Content, formatted),
+				Summary: summary,
+			}, nil
+		}
+		if attempt >= maxRefactorRepairs {
+			return nil, fmt.Errorf("codeassist: 修正 %d 次后仍未通过检查: %w", attempt, err)
+		}
+		msgs = append(msgs, resp, schema.UserMessage(fmt.Sprintf("代码没有通过检查：%v\n请修正后重新输出完整文件。", err)))
+	}
+}
+
+// CheckGo 用 go/parser 解析 code 并用 gofmt 格式化，返回格式化后的代码。
+// original 不为空时还会检查包名没有被改掉
+func CheckGo(filename, original, code string) (string, error) {
+	if strings.TrimSpace(code) == "" {
+		return "", errors.New("回答中没有 ```go 代码块")
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, code, parser.AllErrors|parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("语法错误: %w", err)
+	}
+	if original != "" {
+		if of, err := parser.ParseFile(token.NewFileSet(), filename, original, parser.PackageClauseOnly); err == nil &&
+			of.Name.Name != f.Name.Name {
+			return "", fmt.Errorf("包名从 %s 变成了 %s", of.Name.Name, f.Name.Name)
+		}
+	}
+	out, err := format.Source([]byte(code))
+	if err != nil {
+		return "", fmt.Errorf("gofmt 失败: %w", err)
+	}
+	return string(out), nil
+}
+
+var codeBlock = regexp.MustCompile("(?s)```(?:go|golang)?[ \\t]*\\n(.*?)```")
+
+// splitCode 取出回答中最长的代码块，其余文字作为说明
+func splitCode(content string) (summary, code string) {
+	var best []int
+	for _, m := range codeBlock.FindAllStringSubmatchIndex(content, -1) {
+		if best == nil || m[3]-m[2] > best[3]-best[2] {
+			best = m
+		}
+	}
+	if best == nil {
+		return strings.TrimSpace(content), ""
+	}
+	summary = strings.TrimSpace(content[:best[0]] + content[best[1]:])
+	return summary, content[best[2]:best[3]]
+}
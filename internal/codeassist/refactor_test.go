@@ -0,0 +1,104 @@
+package codeassist
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// scripted 依次返回 replies，并记录每次收到的消息
+type scripted struct {
+	replies []string
+	inputs  [][]*schema.Message
+}
+
+func (m *scripted) Generate(_ context.Context, in []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	m.inputs = append(m.inputs, in)
+	reply := m.replies[min(len(m.inputs), len(m.replies))-1]
+	return schema.AssistantMessage(reply, nil), nil
+}
+
+func (m *scripted) Stream(context.Context, []*schema.Message, ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	panic("不应调用 Stream")
+}
+
+func TestSplitCode(t *testing.T) {
+	content := "把循环提取成函数。\n```go\nshort\n```\n完整文件：\n```golang\npackage main\n\nfunc main() {}\n```\n以上。"
+	summary, code := splitCode(content)
+	if code != "package main\n\nfunc main() {}\n" {
+		t.Errorf("code = %q，期望最长的代码块", code)
+	}
+	if !strings.HasPrefix(summary, "把循环提取成函数。") || !strings.HasSuffix(summary, "以上。") || strings.Contains(summary, "package main") {
+		t.Errorf("summary = %q", summary)
+	}
+
+	summary, code = splitCode("  没有代码  ")
+	if summary != "没有代码" || code != "" {
+		t.Errorf("没有代码块时 = %q, %q", summary, code)
+	}
+}
+
+func TestCheckGo(t *testing.T) {
+	original := "package util\n"
+	got, err := CheckGo("a.go", original, "package util\nfunc  F( ) int {return 1}\n")
+	if err != nil || got != "package util\n\nfunc F() int { return 1 }\n" {
+		t.Errorf("CheckGo = %q, %v", got, err)
+	}
+	cases := map[string]string{
+		"没有代码": "",
+		"语法错误": "package util\nfunc F() {\n",
+		"包名":   "package other\n",
+	}
+	for name, code := range cases {
+		if _, err := CheckGo("a.go", original, code); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+	if _, err := CheckGo("a.go", "", "package other\n"); err != nil {
+		t.Errorf("没有原文件时不检查包名: %v", err)
+	}
+}
+
+func TestRefactorRepairs(t *testing.T) {
+	file := File{Path: "util/util.go", Content: "package util\n\nfunc F() int { return 1 }\n"}
+	m := &scripted{replies: []string{
+		"改名。\n```go\npackage util\n\nfunc G() int {\n```",
+		"```go\npackage helper\n\nfunc G() int { return 1 }\n```",
+		"把 F 改名为 G。\n```go\npackage util\n\nfunc G() int { return 1 }\n```",
+	}}
+	a, err := New(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := a.Refactor(context.Background(), file, "把 F 改名为 G")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.inputs) != 3 {
+		t.Fatalf("调用模型 %d 次，期望 3 次", len(m.inputs))
+	}
+	// 每次修正都带上之前的回答和检查错误
+	last := m.inputs[2]
+	if n := len(last); n != 6 || !strings.Contains(last[3].Content, "语法错误") || !strings.Contains(last[5].Content, "包名") {
+		t.Errorf("第三次请求的消息不符: %d 条", n)
+	}
+	if res.Summary != "把 F 改名为 G。" || !strings.Contains(res.Code, "func G()") {
+		t.Errorf("res = %+v", res)
+	}
+	if !strings.Contains(res.Diff, "--- a/util/util.go\n+++ b/util/util.go\n") || !strings.Contains(res.Diff, "+func G() int { return 1 }\n") {
+		t.Errorf("Diff =\n%s", res.Diff)
+	}
+
+	// 修正次数用完仍未通过时返回错误
+	m = &scripted{replies: []string{"没有代码"}}
+	a, _ = New(m)
+	if _, err := a.Refactor(context.Background(), file, "重构"); err == nil || len(m.inputs) != maxRefactorRepairs+1 {
+		t.Errorf("err = %v，调用 %d 次", err, len(m.inputs))
+	}
+	if _, err := a.Refactor(context.Background(), File{Path: "main.py"}, "重构"); err == nil {
+		t.Error("非 Go 文件应返回错误")
+	}
+}
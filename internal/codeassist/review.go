@@ -0,0 +1,141 @@
+package codeassist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// File 待审查或重构的文件
+type File struct {
+	Path    string
+	Content string
+}
+
+// Severity 问题的严重程度
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Finding 审查发现的一个问题
+type Finding struct {
+	File string `json:"file" jsonschema:"description=文件路径，与输入中的路径一致"`
+	// Line 从 1 开始，0 表示针对整个文件
+	Line       int      `json:"line" jsonschema:"description=问题所在的行号（代码左侧标注的行号）；针对整个文件时为 0,minimum=0"`
+	Severity   Severity `json:"severity" jsonschema:"description=严重程度,enum=error,enum=warning,enum=info"`
+	Category   string   `json:"category" jsonschema:"description=问题类别，如 bug、performance、security、style"`
+	Message    string   `json:"message" jsonschema:"description=问题描述"`
+	Suggestion string   `json:"suggestion,omitempty" jsonschema:"description=具体的修改建议"`
+}
+
+// review 审查结果
+type review struct {
+	Findings []Finding `json:"findings" jsonschema:"description=发现的问题，没有问题时为空数组"`
+}
+
+const reviewPrompt = `你是一个资深的代码审查专家。请审查用户提供的文件，找出错误、潜在的 bug、性能问题、安全问题和不符合惯用法的写法。
+要求：
+1. 行号使用代码左侧标注的行号。
+2. 每个问题给出具体的修改建议。
+3. 只报告确实存在的问题，没有问题时返回空列表。`
+
+// ReviewFiles 一次审查多个文件，返回按文件和行号排序的问题列表。
+// 模型给出的路径与输入不一致时按文件名匹配，仍然匹配不上的问题会被丢弃；超出文件范围的行号改为 0。
+// notes 是附加给模型的参考资料，例如本地静态分析的诊断结果
+func (a *Assistant) ReviewFiles(ctx context.Context, files []File, notes ...string) ([]Finding, error) {
+	if len(files) == 0 {
+		return nil, errors.New("codeassist: 没有需要审查的文件")
+	}
+	var sb strings.Builder
+	sb.WriteString("请审查以下文件：\n")
+	for _, f := range files {
+		fmt.Fprintf(&sb, "\n文件 %s：\n```\n%s```\n", f.Path, numberLines(f.Content))
+	}
+	for _, note := range notes {
+		if note = strings.TrimSpace(note); note != "" {
+			fmt.Fprintf(&sb, "\n%s\n", note)
+		}
+	}
+
+	r, err := a.reviewer.Generate(ctx, []*schema.Message{
+		schema.SystemMessage(reviewPrompt),
+		schema.UserMessage(sb.String()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("codeassist: 审查失败: %w", err)
+	}
+	return normalize(r.Findings, files), nil
+}
+
+// numberLines 在每行前加上行号，方便模型引用
+func numberLines(code string) string {
+	lines := splitLines(code)
+	width := len(fmt.Sprint(len(lines)))
+	var sb strings.Builder
+	for i, line := range lines {
+		fmt.Fprintf(&sb, "%*d| %s", width, i+1, line)
+		if !strings.HasSuffix(line, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// normalize 校正路径、行号和严重程度，去掉重复的问题并排序
+func normalize(findings []Finding, files []File) []Finding {
+	order := map[string]int{}
+	lines := map[string]int{}
+	byBase := map[string]string{}
+	for i, f := range files {
+		order[f.Path] = i
+		lines[f.Path] = len(splitLines(f.Content))
+		byBase[path.Base(f.Path)] = f.Path
+	}
+
+	type key struct {
+		file    string
+		line    int
+		message string
+	}
+	seen := map[key]bool{}
+	out := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		if _, ok := order[f.File]; !ok {
+			p, ok := byBase[path.Base(f.File)]
+			if !ok {
+				continue
+			}
+			f.File = p
+		}
+		if f.Line < 0 || f.Line > lines[f.File] {
+			f.Line = 0
+		}
+		switch f.Severity {
+		case SeverityError, SeverityWarning, SeverityInfo:
+		default:
+			f.Severity = SeverityWarning
+		}
+		k := key{f.File, f.Line, strings.TrimSpace(f.Message)}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, f)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].File != out[j].File {
+			return order[out[i].File] < order[out[j].File]
+		}
+		return out[i].Line < out[j].Line
+	})
+	return out
+}
@@ -0,0 +1,41 @@
+package codeassist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	files := []File{
+		{Path: "internal/a/x.go", Content: "1\n2\n3\n"},
+		{Path: "b.go", Content: "1\n"},
+	}
+	findings := []Finding{
+		{File: "b.go", Line: 5, Severity: "critical", Message: "行号超出范围"},
+		{File: "x.go", Line: 3, Severity: SeverityError, Message: "按文件名匹配"},
+		{File: "/abs/internal/a/x.go", Line: 1, Severity: SeverityInfo, Message: "绝对路径也按文件名匹配"},
+		{File: "internal/a/x.go", Line: 3, Severity: SeverityError, Message: " 按文件名匹配 "},
+		{File: "other/c.go", Line: 1, Severity: SeverityError, Message: "不存在的文件"},
+		{File: "b.go", Line: -1, Severity: SeverityInfo, Message: "负数行号"},
+	}
+	want := []Finding{
+		{File: "internal/a/x.go", Line: 1, Severity: SeverityInfo, Message: "绝对路径也按文件名匹配"},
+		{File: "internal/a/x.go", Line: 3, Severity: SeverityError, Message: "按文件名匹配"},
+		{File: "b.go", Line: 0, Severity: SeverityWarning, Message: "行号超出范围"},
+		{File: "b.go", Line: 0, Severity: SeverityInfo, Message: "负数行号"},
+	}
+	if got := normalize(findings, files); !reflect.DeepEqual(got, want) {
+		t.Errorf("normalize =\n%+v\n期望\n%+v", got, want)
+	}
+}
+
+func TestNumberLines(t *testing.T) {
+	code := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj"
+	got := numberLines(code)
+	if want := " 1| a\n"; got[:len(want)] != want {
+		t.Errorf("numberLines 开头 = %q", got[:len(want)])
+	}
+	if want := "10| j\n"; got[len(got)-len(want):] != want {
+		t.Errorf("numberLines 结尾 = %q", got[len(got)-len(want):])
+	}
+}
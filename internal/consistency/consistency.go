@@ -0,0 +1,259 @@
+// Package consistency 实现思维链的自洽性（self-consistency）解码：
+// 以较高的温度并发采样 N 条推理路径，用 Extractor 取出每条路径的最终答案，按多数或加权投票得到答案和一致率。
+//
+// 可选的 Verifier 在投票前检查每条路径，例如 Arithmetic 用本地求值器核对推理中的算式，
+// 没有通过检查的路径不参与投票。
+package consistency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/reasoning"
+)
+
+// 默认值
+const (
+	DefaultN           = 5
+	DefaultTemperature = 0.7
+)
+
+// ErrNoAnswer 没有任何一条推理路径给出可用的答案
+var ErrNoAnswer = errors.New("consistency: 没有可用的答案")
+
+// Config 自洽性解码配置
+type Config struct {
+	Model model.BaseChatModel
+	// N 采样次数，默认 DefaultN
+	N int
+	// Temperature 采样温度，默认 DefaultTemperature；温度太低时各条路径几乎相同，投票没有意义
+	Temperature *float32
+	// Concurrency 同时进行的请求数，默认等于 N
+	Concurrency int
+	// Extractor 取出最终答案，默认 FinalAnswer
+	Extractor Extractor
+	// Equal 判断两个归一化后的答案是否相同，默认 SameAnswer
+	Equal func(a, b string) bool
+	// Weight 每条路径的票数，nil 时每条一票，即多数投票
+	Weight func(*Sample) float64
+	// Verifier 投票前检查每条路径，可以为 nil
+	Verifier Verifier
+	// Options 每次请求附加的选项
+	Options []model.Option
+}
+
+// Sample 一条推理路径
+type Sample struct {
+	Index   int
+	Message *schema.Message
+	// Answer 归一化后的最终答案，没有取到时为空
+	Answer string
+	Weight float64
+	// Err 请求失败、没有取到答案或没有通过检查的原因，为空表示参与了投票
+	Err string
+}
+
+// Content 推理模型的思考过程加上回答，供 Extractor 之外的检查使用
+func (s *Sample) Content() string {
+	if s.Message == nil {
+		return ""
+	}
+	if r := reasoning.Of(s.Message); r != "" {
+		return r + "\n" + s.Message.Content
+	}
+	return s.Message.Content
+}
+
+// Result 投票结果
+type Result struct {
+	Answer string
+	// Agreement 得票最多的答案的票数占全部有效票数的比例
+	Agreement float64
+	// Votes 答案 -> 票数
+	Votes   map[string]float64
+	Samples []*Sample
+}
+
+// Valid 参与投票的路径数
+func (r *Result) Valid() int {
+	n := 0
+	for _, s := range r.Samples {
+		if s.Err == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Runner 自洽性解码器，可以并发使用
+type Runner struct {
+	cfg Config
+}
+
+// New 创建自洽性解码器
+func New(cfg *Config) (*Runner, error) {
+	if cfg == nil || cfg.Model == nil {
+		return nil, errors.New("consistency: 缺少 Model")
+	}
+	c := *cfg
+	if c.N <= 0 {
+		c.N = DefaultN
+	}
+	if c.Temperature == nil {
+		t := float32(DefaultTemperature)
+		c.Temperature = &t
+	}
+	if c.Concurrency <= 0 || c.Concurrency > c.N {
+		c.Concurrency = c.N
+	}
+	if c.Extractor == nil {
+		c.Extractor = FinalAnswer
+	}
+	if c.Equal == nil {
+		c.Equal = SameAnswer
+	}
+	return &Runner{cfg: c}, nil
+}
+
+// Run 对同一组消息采样 N 次并投票。单次请求失败只记录在 Sample.Err 中；
+// 全部失败时返回第一个错误，都没有可用答案时返回 ErrNoAnswer，此时 Result 中仍有全部路径
+func (r *Runner) Run(ctx context.Context, msgs []*schema.Message) (*Result, error) {
+	opts := append([]model.Option{model.WithTemperature(*r.cfg.Temperature)}, r.cfg.Options...)
+	samples := make([]*Sample, r.cfg.N)
+	errs := make([]error, r.cfg.N)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, r.cfg.Concurrency)
+	for i := range samples {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			samples[i], errs[i] = r.sample(ctx, i, msgs, opts)
+		}()
+	}
+	wg.Wait()
+
+	res := vote(samples, r.cfg.Equal)
+	if res.Answer != "" {
+		return res, nil
+	}
+	for _, err := range errs {
+		if err == nil {
+			return res, ErrNoAnswer
+		}
+	}
+	return res, fmt.Errorf("consistency: 全部请求失败: %w", errs[0])
+}
+
+func (r *Runner) sample(ctx context.Context, i int, msgs []*schema.Message, opts []model.Option) (*Sample, error) {
+	s := &Sample{Index: i}
+	resp, err := r.cfg.Model.Generate(ctx, msgs, opts...)
+	if err != nil {
+		s.Err = err.Error()
+		return s, err
+	}
+	s.Message = resp
+
+	answer, ok := r.cfg.Extractor.Extract(resp.Content)
+	if s.Answer = Normalize(answer); !ok || s.Answer == "" {
+		s.Err = "没有取到答案"
+		return s, nil
+	}
+	if r.cfg.Verifier != nil {
+		if err := r.cfg.Verifier.Verify(ctx, s); err != nil {
+			s.Err = "未通过检查: " + err.Error()
+			return s, nil
+		}
+	}
+	s.Weight = 1
+	if r.cfg.Weight != nil {
+		s.Weight = r.cfg.Weight(s)
+	}
+	if s.Weight <= 0 || math.IsNaN(s.Weight) {
+		s.Err = "权重为 0"
+		s.Weight = 0
+	}
+	return s, nil
+}
+
+// vote 把相同的答案归为一组累加票数，每组以最先出现的写法作为答案；票数相同时取最先出现的组
+func vote(samples []*Sample, equal func(a, b string) bool) *Result {
+	res := &Result{Votes: map[string]float64{}, Samples: samples}
+	var answers []string
+	total := 0.0
+	for _, s := range samples {
+		if s.Err != "" {
+			continue
+		}
+		i := slices.IndexFunc(answers, func(a string) bool { return equal(a, s.Answer) })
+		if i < 0 {
+			i = len(answers)
+			answers = append(answers, s.Answer)
+		}
+		res.Votes[answers[i]] += s.Weight
+		total += s.Weight
+	}
+	for _, a := range answers {
+		if res.Answer == "" || res.Votes[a] > res.Votes[res.Answer] {
+			res.Answer = a
+		}
+	}
+	if res.Answer != "" {
+		res.Agreement = res.Votes[res.Answer] / total
+	}
+	return res
+}
+
+// SameAnswer 两个答案都是数（可以是分数或算式）时按小数位数较少的一方容许四舍五入误差，
+// 如 "1.71"、"1.714" 和 "12/7" 相同；否则比较字符串
+func SameAnswer(a, b string) bool {
+	if a == b {
+		return true
+	}
+	x, errA := Eval(ungroup(a))
+	y, errB := Eval(ungroup(b))
+	if errA != nil || errB != nil {
+		return false
+	}
+	written := a
+	if decimals(b) < decimals(a) {
+		written = b
+	}
+	return closeTo(value{v: x}, y, written)
+}
+
+// Normalize 归一化答案以便比较：去掉首尾空白和句末标点、合并空白；数字统一写法，如 "1,200.0" 写成 "1200"，
+// 只去掉千位分隔符，"2,3" 这样的列表保持不变
+func Normalize(answer string) string {
+	s := strings.Join(strings.Fields(answer), " ")
+	s = strings.TrimRight(s, "。.！!")
+	if f, err := strconv.ParseFloat(ungroup(s), 64); err == nil {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return s
+}
+
+// LogProbWeight 用回答的平均 token 对数概率换算成票数（几何平均概率），
+// 需要模型返回 logprobs；没有时每条一票
+func LogProbWeight(s *Sample) float64 {
+	if s.Message == nil || s.Message.ResponseMeta == nil || s.Message.ResponseMeta.LogProbs == nil ||
+		len(s.Message.ResponseMeta.LogProbs.Content) == 0 {
+		return 1
+	}
+	sum := 0.0
+	for _, p := range s.Message.ResponseMeta.LogProbs.Content {
+		sum += p.LogProb
+	}
+	return math.Exp(sum / float64(len(s.Message.ResponseMeta.LogProbs.Content)))
+}
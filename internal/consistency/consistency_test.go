@@ -0,0 +1,60 @@
+package consistency
+
+import (
+	"testing"
+)
+
+func TestVote(t *testing.T) {
+	samples := []*Sample{
+		{Answer: "1.71", Weight: 1},
+		{Answer: "3", Weight: 1},
+		{Answer: "12/7", Weight: 1},
+		{Answer: "1.714", Weight: 0.5},
+		{Answer: "3", Weight: 1},
+		{Answer: "99", Weight: 5, Err: "没有通过检查"},
+	}
+	res := vote(samples, SameAnswer)
+	if res.Answer != "1.71" {
+		t.Errorf("Answer = %q，期望 1.71", res.Answer)
+	}
+	if res.Votes["1.71"] != 2.5 || res.Votes["3"] != 2 || len(res.Votes) != 2 {
+		t.Errorf("Votes = %v", res.Votes)
+	}
+	if want := 2.5 / 4.5; res.Agreement != want {
+		t.Errorf("Agreement = %v，期望 %v", res.Agreement, want)
+	}
+	if res.Valid() != 5 {
+		t.Errorf("Valid = %d", res.Valid())
+	}
+
+	// 票数相同时取最先出现的答案；严格比较时写法不同的答案各自成组
+	tie := vote([]*Sample{{Answer: "B", Weight: 1}, {Answer: "A", Weight: 1}}, func(a, b string) bool { return a == b })
+	if tie.Answer != "B" {
+		t.Errorf("平票时 Answer = %q，期望 B", tie.Answer)
+	}
+
+	if empty := vote([]*Sample{{Err: "请求失败"}}, SameAnswer); empty.Answer != "" || empty.Agreement != 0 {
+		t.Errorf("没有有效路径时 = %+v", empty)
+	}
+}
+
+func TestSameAnswer(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.71", "1.714", true},
+		{"1.71", "12/7", true},
+		{"3", "3.0", true},
+		{"3", "3.4", false},
+		{"1,200", "1200", true},
+		{"2,3", "23", false},
+		{"北京", "北京", true},
+		{"北京", "上海", false},
+	}
+	for _, c := range cases {
+		if got := SameAnswer(c.a, c.b); got != c.want {
+			t.Errorf("SameAnswer(%q, %q) = %v，期望 %v", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package consistency
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Extractor 从一条回答中取出最终答案
+type Extractor interface {
+	Extract(content string) (answer string, ok bool)
+}
+
+// ExtractorFunc 把函数转换为 Extractor
+type ExtractorFunc func(content string) (string, bool)
+
+// Extract 调用 f
+func (f ExtractorFunc) Extract(content string) (string, bool) {
+	return f(content)
+}
+
+// finalAnswer 匹配“最终答案：...”、“答案应该是 ...”、“The answer is ...”等写法，英文标记要求是完整的单词
+var finalAnswer = regexp.MustCompile(`(?im)(?:最终答案|答案|结论|\bfinal answer\b|\banswer\b)\s*` +
+	`(?:应该是|应当是|应该为|应为|就是|是|为|(?:is|should be|would be)\b)?\s*[:：]?\s*(.+)$`)
+
+// FinalAnswer 取最后一处“最终答案：”“答案是”之类标记后的内容，去掉 Markdown 加粗；
+// 没有标记时取最后一个非空行
+var FinalAnswer Extractor = ExtractorFunc(func(content string) (string, bool) {
+	if m := finalAnswer.FindAllStringSubmatch(content, -1); len(m) > 0 {
+		return cleanAnswer(m[len(m)-1][1]), true
+	}
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	last := cleanAnswer(lines[len(lines)-1])
+	return last, last != ""
+})
+
+func cleanAnswer(s string) string {
+	s = strings.ReplaceAll(s, "**", "")
+	s = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(s), "#>-*:："))
+	return s
+}
+
+// number 匹配整数、小数和分数，允许千位分隔符；"2,3" 这样不是千位分组的逗号不算在数中
+var number = regexp.MustCompile(`-?(?:\d{1,3}(?:,\d{3})+\b(?:\.\d+)?|\d+(?:\.\d+)?(?:/\d+)?)`)
+
+// grouped 整个字符串是带千位分隔符的数，如 "1,200" 和 "-12,345.6"
+var grouped = regexp.MustCompile(`^-?\d{1,3}(?:,\d{3})+(?:\.\d+)?$`)
+
+// ungroup 去掉数中的千位分隔符，其他写法原样返回，如 "2,3" 不变
+func ungroup(s string) string {
+	if grouped.MatchString(s) {
+		return strings.ReplaceAll(s, ",", "")
+	}
+	return s
+}
+
+// Number 取最终答案中的第一个数，没有答案标记或标记后没有数时取全文最后一个数；
+// 分数按值比较，如 "3 小时"、"3.0h" 和 "6/2" 都取到 "3"
+var Number Extractor = ExtractorFunc(func(content string) (string, bool) {
+	var n string
+	if m := finalAnswer.FindAllStringSubmatch(content, -1); len(m) > 0 {
+		n = number.FindString(m[len(m)-1][1])
+	}
+	if n == "" {
+		nums := number.FindAllString(content, -1)
+		if len(nums) == 0 {
+			return "", false
+		}
+		n = nums[len(nums)-1]
+	}
+	if strings.Contains(n, "/") {
+		if v, err := Eval(n); err == nil {
+			return formatNumber(v), true
+		}
+	}
+	return n, true
+})
+
+// Pattern 取 re 最后一处匹配的第一个分组，没有分组时取整个匹配
+func Pattern(re *regexp.Regexp) Extractor {
+	return ExtractorFunc(func(content string) (string, bool) {
+		m := re.FindAllStringSubmatch(content, -1)
+		if len(m) == 0 {
+			return "", false
+		}
+		last := m[len(m)-1]
+		if len(last) > 1 {
+			return last[1], true
+		}
+		return last[0], true
+	})
+}
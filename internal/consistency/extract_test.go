@@ -0,0 +1,88 @@
+package consistency
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFinalAnswer(t *testing.T) {
+	cases := []struct {
+		content, want string
+	}{
+		{"先算总速度。\n最终答案：3 小时", "3 小时"},
+		{"答案是 **42**", "42"},
+		{"Step 1...\nFinal Answer: 12", "12"},
+		{"答案：A\n检查后修改。\n答案：B", "B"},
+		{"没有标记\n\n最后一行是 7\n", "最后一行是 7"},
+		{"So the answer is 42.", "42."},
+		{"The final answer is: 12", "12"},
+		{"所以答案应该是 3 小时", "3 小时"},
+		{"综上，答案为 5", "5"},
+		// answers 不是完整的单词，没有标记时取最后一行
+		{"Two answers exist\n7", "7"},
+	}
+	for _, c := range cases {
+		got, ok := FinalAnswer.Extract(c.content)
+		if !ok || got != c.want {
+			t.Errorf("FinalAnswer(%q) = %q, %v，期望 %q", c.content, got, ok, c.want)
+		}
+	}
+	if _, ok := FinalAnswer.Extract("  \n "); ok {
+		t.Error("空回答不应取到答案")
+	}
+}
+
+func TestNumber(t *testing.T) {
+	cases := []struct {
+		content, want string
+	}{
+		{"60 + 80 = 140\n最终答案：3 小时", "3"},
+		{"最终答案：6/2", "3"},
+		{"最终答案：约 1,200 元", "1,200"},
+		{"一共 60 + 80 = 140 公里", "140"},
+		{"答案：-2.5", "-2.5"},
+		{"The answer is 1,234,567.", "1,234,567"},
+		{"答案是 2,3", "2"},
+	}
+	for _, c := range cases {
+		got, ok := Number.Extract(c.content)
+		if !ok || got != c.want {
+			t.Errorf("Number(%q) = %q, %v，期望 %q", c.content, got, ok, c.want)
+		}
+	}
+	if _, ok := Number.Extract("没有数字"); ok {
+		t.Error("没有数字时不应取到答案")
+	}
+}
+
+func TestPattern(t *testing.T) {
+	choice := Pattern(regexp.MustCompile(`选项\s*([A-D])`))
+	if got, ok := choice.Extract("排除选项 A，选项 C 正确"); !ok || got != "C" {
+		t.Errorf("Pattern = %q, %v", got, ok)
+	}
+	whole := Pattern(regexp.MustCompile(`\d+`))
+	if got, ok := whole.Extract("1 和 2"); !ok || got != "2" {
+		t.Errorf("Pattern 无分组 = %q, %v", got, ok)
+	}
+	if _, ok := choice.Extract("无"); ok {
+		t.Error("没有匹配时不应取到答案")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{" 1,200.0 ", "1200"},
+		{"-12,345.50", "-12345.5"},
+		{"3。", "3"},
+		{"2,3", "2,3"},
+		{"1,2345", "1,2345"},
+		{"北京  上海", "北京 上海"},
+	}
+	for _, c := range cases {
+		if got := Normalize(c.in); got != c.want {
+			t.Errorf("Normalize(%q) = %q，期望 %q", c.in, got, c.want)
+		}
+	}
+}
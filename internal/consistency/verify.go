@@ -0,0 +1,290 @@
+package consistency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Verifier 在投票前检查一条推理路径，返回非 nil 表示该路径不参与投票，错误内容记录在 Sample.Err 中
+type Verifier interface {
+	Verify(ctx context.Context, s *Sample) error
+}
+
+// VerifierFunc 把函数转换为 Verifier
+type VerifierFunc func(ctx context.Context, s *Sample) error
+
+// Verify 调用 f
+func (f VerifierFunc) Verify(ctx context.Context, s *Sample) error {
+	return f(ctx, s)
+}
+
+// Arithmetic 用本地求值器检查算术题：推理中形如 "60 + 80 = 140" 的每个算式都要成立，
+// Expr 不为空时答案还要等于 Expr 的值。比较时容许四舍五入误差：左边带小数的操作数按末位的一半估计误差并随运算传递，
+// 右边的结果再加上按其小数位数的误差
+type Arithmetic struct {
+	// Expr 题目的标准算式，如 "240 / (60 + 80)"，可以为空
+	Expr string
+}
+
+// Verify 检查算式和答案
+func (v *Arithmetic) Verify(_ context.Context, s *Sample) error {
+	for _, line := range strings.Split(s.Content(), "\n") {
+		if err := checkLine(line); err != nil {
+			return err
+		}
+	}
+	if v.Expr == "" {
+		return nil
+	}
+	want, err := Eval(v.Expr)
+	if err != nil {
+		return fmt.Errorf("标准算式无效: %w", err)
+	}
+	got, err := Eval(ungroup(s.Answer))
+	if err != nil {
+		return fmt.Errorf("答案 %q 不是数", s.Answer)
+	}
+	if !closeTo(value{v: want}, got, s.Answer) {
+		return fmt.Errorf("答案 %s 与 %s = %s 不符", s.Answer, v.Expr, formatNumber(want))
+	}
+	return nil
+}
+
+// checkLine 检查一行中的每个等式：取 "=" 右边开头的算式，与左边末尾能解析的最长的、含运算符的算式比较
+func checkLine(line string) error {
+	line = strings.NewReplacer("＝", "=", "（", "(", "）", ")").Replace(line)
+	parts := strings.Split(line, "=")
+	for i := 0; i+1 < len(parts); i++ {
+		right := strings.TrimSpace(headExpr(parts[i+1]))
+		r, err := Eval(right)
+		if err != nil {
+			continue
+		}
+		left, l, ok := evalSuffix(tailExpr(parts[i]))
+		if ok && !closeTo(l, r, right) {
+			return fmt.Errorf("算式 %s = %s 不成立，应为 %s", left, right, formatNumber(l.v))
+		}
+	}
+	return nil
+}
+
+// evalSuffix 从前往后在空格处截断 s，返回第一个能求值且含运算符的后缀，
+// 这样 "2. 60 + 80" 中的列表序号不会被当成算式的一部分
+func evalSuffix(s string) (string, value, bool) {
+	for j := 0; j < len(s); j++ {
+		if s[j] == ' ' || j > 0 && s[j-1] != ' ' {
+			continue
+		}
+		expr := strings.TrimSpace(strings.TrimLeft(s[j:], "+*/×÷"))
+		// 列表项开头的 "- " 不是负号
+		if strings.HasPrefix(expr, "- ") || !hasOperator(expr) {
+			continue
+		}
+		if v, err := eval(expr); err == nil {
+			return expr, v, true
+		}
+	}
+	return "", value{}, false
+}
+
+func isExprChar(r rune) bool {
+	return r >= '0' && r <= '9' || strings.ContainsRune(".+-*/×÷^() ", r)
+}
+
+// tailExpr 取 s 末尾由数字、运算符和括号组成的部分
+func tailExpr(s string) string {
+	rs := []rune(s)
+	i := len(rs)
+	for i > 0 && isExprChar(rs[i-1]) {
+		i--
+	}
+	return string(rs[i:])
+}
+
+// headExpr 取 s 开头由数字、运算符和括号组成的部分
+func headExpr(s string) string {
+	rs := []rune(s)
+	i := 0
+	for i < len(rs) && isExprChar(rs[i]) {
+		i++
+	}
+	return strings.TrimRight(string(rs[:i]), "+-*/×÷^( ")
+}
+
+// hasOperator s 中有位于两个操作数之间的运算符
+func hasOperator(s string) bool {
+	s = strings.TrimSpace(s)
+	return len(s) > 1 && strings.ContainsAny(s[1:], "+-*/×÷^")
+}
+
+// closeTo 容许 want 自身的误差加上按 written 的小数位数的四舍五入误差；written 是整数、分数或算式时不加
+func closeTo(want value, got float64, written string) bool {
+	tol := want.e + 1e-9*math.Max(1, math.Abs(want.v))
+	if d := decimals(strings.TrimSpace(written)); d > 0 && d < math.MaxInt16 {
+		tol += 0.5 * math.Pow10(-d)
+	}
+	return math.Abs(want.v-got) <= tol
+}
+
+// decimals 小数位数，不是普通小数（如分数）时视为精确值
+func decimals(s string) int {
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return math.MaxInt16
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Eval 计算四则运算表达式，支持 + - * / ^（乘方）、× ÷、括号和一元负号
+func Eval(expr string) (float64, error) {
+	v, err := eval(expr)
+	return v.v, err
+}
+
+// value 求值结果 v 及其误差上限 e：带小数的数按末位的一半计误差（如 0.71 为 0.005），整数视为精确值
+type value struct {
+	v, e float64
+}
+
+func eval(expr string) (value, error) {
+	p := &parser{s: strings.NewReplacer("×", "*", "÷", "/").Replace(expr)}
+	v, err := p.expr()
+	if err != nil {
+		return value{}, err
+	}
+	if p.peek() != 0 {
+		return value{}, fmt.Errorf("consistency: 表达式 %q 在第 %d 个字符处无法解析", expr, p.i+1)
+	}
+	if math.IsInf(v.v, 0) || math.IsNaN(v.v) || math.IsNaN(v.e) {
+		return value{}, fmt.Errorf("consistency: 表达式 %q 的结果无效", expr)
+	}
+	return v, nil
+}
+
+// parser 递归下降求值，忽略记号之间的空白：expr = term {(+|-) term}；term = factor {(*|/) factor}；factor = unary [^ factor]
+type parser struct {
+	s string
+	i int
+}
+
+// peek 跳过空白，返回下一个字符，到结尾时返回 0
+func (p *parser) peek() byte {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+	if p.i < len(p.s) {
+		return p.s[p.i]
+	}
+	return 0
+}
+
+func (p *parser) expr() (value, error) {
+	v, err := p.term()
+	for err == nil && (p.peek() == '+' || p.peek() == '-') {
+		op := p.peek()
+		p.i++
+		var r value
+		if r, err = p.term(); op == '+' {
+			v = value{v.v + r.v, v.e + r.e}
+		} else {
+			v = value{v.v - r.v, v.e + r.e}
+		}
+	}
+	return v, err
+}
+
+func (p *parser) term() (value, error) {
+	v, err := p.factor()
+	for err == nil && (p.peek() == '*' || p.peek() == '/') {
+		op := p.peek()
+		p.i++
+		var r value
+		if r, err = p.factor(); err != nil {
+			break
+		}
+		if op == '*' {
+			v = value{v.v * r.v, math.Abs(v.v)*r.e + math.Abs(r.v)*v.e + v.e*r.e}
+		} else if r.v == 0 {
+			return value{}, errors.New("consistency: 除数为 0")
+		} else if math.Abs(r.v) <= r.e {
+			// 除数的误差范围包含 0，商没有上限
+			v = value{v.v / r.v, math.Inf(1)}
+		} else {
+			v = value{v.v / r.v, (math.Abs(v.v)*r.e + math.Abs(r.v)*v.e) / (math.Abs(r.v) * (math.Abs(r.v) - r.e))}
+		}
+	}
+	return v, err
+}
+
+func (p *parser) factor() (value, error) {
+	v, err := p.unary()
+	if err != nil || p.peek() != '^' {
+		return v, err
+	}
+	p.i++
+	x, err := p.factor()
+	if err != nil {
+		return value{}, err
+	}
+	pow := math.Pow(v.v, x.v)
+	e := 0.0
+	if v.e > 0 || x.e > 0 {
+		// 取底数和指数在误差范围内的各个组合，误差为与 pow 的最大差值
+		for _, b := range []float64{v.v - v.e, v.v + v.e} {
+			for _, n := range []float64{x.v - x.e, x.v + x.e} {
+				e = math.Max(e, math.Abs(math.Pow(b, n)-pow))
+			}
+		}
+	}
+	return value{pow, e}, nil
+}
+
+func (p *parser) unary() (value, error) {
+	switch p.peek() {
+	case '-':
+		p.i++
+		v, err := p.unary()
+		return value{-v.v, v.e}, err
+	case '+':
+		p.i++
+		return p.unary()
+	case '(':
+		p.i++
+		v, err := p.expr()
+		if err != nil {
+			return value{}, err
+		}
+		if p.peek() != ')' {
+			return value{}, errors.New("consistency: 缺少右括号")
+		}
+		p.i++
+		return v, nil
+	}
+	start := p.i
+	for p.i < len(p.s) && (p.s[p.i] >= '0' && p.s[p.i] <= '9' || p.s[p.i] == '.') {
+		p.i++
+	}
+	if start == p.i {
+		return value{}, fmt.Errorf("consistency: 第 %d 个字符处缺少数字", p.i+1)
+	}
+	text := p.s[start:p.i]
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return value{}, err
+	}
+	e := 0.0
+	if d := decimals(text); d > 0 {
+		e = 0.5 * math.Pow10(-d)
+	}
+	return value{v, e}, nil
+}
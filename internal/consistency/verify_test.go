@@ -0,0 +1,88 @@
+package consistency
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestEval(t *testing.T) {
+	cases := []struct {
+		expr string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"12 / 4 / 3", 1},
+		{"2 ^ 3 ^ 2", 512},
+		{"-3 + 5", 2},
+		{"2 * -3", -6},
+		{"240 ÷ (60 + 80)", 240.0 / 140},
+		{"1.5 × 4", 6},
+		{" 42 ", 42},
+	}
+	for _, c := range cases {
+		got, err := Eval(c.expr)
+		if err != nil || math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("Eval(%q) = %v, %v，期望 %v", c.expr, got, err, c.want)
+		}
+	}
+
+	for _, expr := range []string{"", "1 +", "(1 + 2", "1 2", "abc", "1 / 0", "1 / (2 - 2)"} {
+		if v, err := Eval(expr); err == nil {
+			t.Errorf("Eval(%q) = %v，期望返回错误", expr, v)
+		}
+	}
+}
+
+func TestCheckLine(t *testing.T) {
+	cases := []struct {
+		line string
+		ok   bool
+	}{
+		{"60 + 80 = 140", true},
+		{"2. 60 + 80 = 140 公里", true},
+		{"240 / 140 = 1.71 小时", true},
+		{"240 / 140 ≈ 1.71，约等于 1.7", true},
+		// 左边的 0.71 已经四舍五入，真实值 0.714 × 60 = 42.86
+		{"0.71 × 60 = 42.86 分钟", true},
+		{"0.71 × 60 = 42.6 分钟", true},
+		{"（1.5 + 2.25）× 2 = 7.5", true},
+		{"速度是 60 km/h，没有算式", true},
+		{"60 + 80 = 150", false},
+		{"240 / 140 = 1.8", false},
+		{"0.71 × 60 = 45 分钟", false},
+		{"- 3 × 4 = 12", true},
+		{"总共 3 × 4 = 13 个", false},
+	}
+	for _, c := range cases {
+		err := checkLine(c.line)
+		if (err == nil) != c.ok {
+			t.Errorf("checkLine(%q) = %v，期望成立: %v", c.line, err, c.ok)
+		}
+	}
+}
+
+func TestArithmeticVerify(t *testing.T) {
+	v := &Arithmetic{Expr: "240 / (60 + 80)"}
+	cases := []struct {
+		content, answer string
+		ok              bool
+	}{
+		{"60 + 80 = 140\n240 / 140 = 1.71\n最终答案：1.71 小时", "1.71", true},
+		{"60 + 80 = 140\n最终答案：12/7", "12/7", true},
+		{"60 + 80 = 140\n最终答案：1.8", "1.8", false},
+		{"60 + 80 = 150\n最终答案：1.71", "1.71", false},
+		{"最终答案：很快", "很快", false},
+	}
+	for _, c := range cases {
+		s := &Sample{Message: schema.AssistantMessage(c.content, nil), Answer: c.answer}
+		err := v.Verify(context.Background(), s)
+		if (err == nil) != c.ok {
+			t.Errorf("Verify(%q) = %v，期望通过: %v", c.content, err, c.ok)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+// Package continuation 在输出因长度限制被截断时自动续写。
+//
+// 模型返回 finish_reason 为 "length" 时，包装器把已经生成的内容作为 assistant 消息、
+// 再追加一条续写请求发给模型，把各段输出拼接成一条完整的消息。续写开头与上一段结尾重复的部分足够长时会被去掉。
+// 续写次数达到上限仍被截断时，返回消息的 FinishReason 仍为 "length"，调用方可以据此判断。
+package continuation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// FinishReasonLength 因达到 MaxTokens 而停止
+const FinishReasonLength = "length"
+
+// ExtraContinuations 消息 Extra 中记录续写次数的键
+const ExtraContinuations = "continuations"
+
+// DefaultPrompt 默认的续写请求
+const DefaultPrompt = "你的回答因为长度限制被截断了。请从中断的地方直接继续输出，不要重复已经输出的内容，也不要添加任何说明。"
+
+// overlapWindow 检查续写与上一段重复的最大字符数
+const overlapWindow = 200
+
+// 重复部分至少 minOverlap 个字符才去掉；从一行或一句话开头开始的重复至少 minAlignedOverlap 个字符。
+// 更短的重复多半是正常内容，例如 "价格是 100" 之后续写 "0 元"
+const (
+	minOverlap        = 10
+	minAlignedOverlap = 4
+)
+
+// Config 续写配置
+type Config struct {
+	// MaxContinuations 最多续写的次数，默认 3
+	MaxContinuations int
+	// Prompt 续写请求，默认 DefaultPrompt
+	Prompt string
+}
+
+type options struct {
+	maxContinuations *int
+}
+
+// WithMaxContinuations 本次调用最多续写的次数，0 表示不续写
+func WithMaxContinuations(n int) model.Option {
+	return model.WrapImplSpecificOptFn(func(o *options) { o.maxContinuations = &n })
+}
+
+// ChatModel 自动续写的 ChatModel
+type ChatModel struct {
+	inner model.BaseChatModel
+	cfg   Config
+}
+
+var _ model.ToolCallingChatModel = (*ChatModel)(nil)
+
+// New 为 inner 增加自动续写，cfg 可以为 nil
+func New(inner model.BaseChatModel, cfg *Config) *ChatModel {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.MaxContinuations <= 0 {
+		c.MaxContinuations = 3
+	}
+	if c.Prompt == "" {
+		c.Prompt = DefaultPrompt
+	}
+	return &ChatModel{inner: inner, cfg: c}
+}
+
+// Continuations 返回消息经过的续写次数
+func Continuations(msg *schema.Message) int {
+	if msg == nil {
+		return 0
+	}
+	n, _ := msg.Extra[ExtraContinuations].(int)
+	return n
+}
+
+func (m *ChatModel) maxContinuations(opts []model.Option) int {
+	o := model.GetImplSpecificOptions(&options{}, opts...)
+	if o.maxContinuations != nil {
+		return *o.maxContinuations
+	}
+	return m.cfg.MaxContinuations
+}
+
+// next 返回续写请求的消息：原始输入、已生成的内容和续写请求
+func (m *ChatModel) next(in []*schema.Message, content string) []*schema.Message {
+	msgs := make([]*schema.Message, 0, len(in)+2)
+	msgs = append(msgs, in...)
+	return append(msgs, schema.AssistantMessage(content, nil), schema.UserMessage(m.cfg.Prompt))
+}
+
+func (m *ChatModel) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	limit := m.maxContinuations(opts)
+
+	msg, err := m.inner.Generate(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	out := *msg
+	total := usageOf(msg)
+
+	n := 0
+	for ; n < limit && Truncated(&out); n++ {
+		piece, err := m.inner.Generate(ctx, m.next(in, out.Content), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("continuation: 第 %d 次续写失败: %w", n+1, err)
+		}
+		out.Content += trimOverlap(out.Content, piece.Content)
+		out.ReasoningContent += piece.ReasoningContent
+		out.ResponseMeta = piece.ResponseMeta
+		total = addUsage(total, usageOf(piece))
+	}
+
+	if n > 0 {
+		out.Extra = withContinuations(out.Extra, n)
+		if out.ResponseMeta != nil {
+			meta := *out.ResponseMeta
+			meta.Usage = total
+			out.ResponseMeta = &meta
+		}
+	}
+	return &out, nil
+}
+
+func (m *ChatModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	limit := m.maxContinuations(opts)
+
+	sr, err := m.inner.Stream(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer sw.Close()
+
+		var (
+			content strings.Builder
+			total   *schema.TokenUsage
+			last    *schema.ResponseMeta
+		)
+		for n := 0; ; n++ {
+			// 第一段之后的分片去掉与已输出内容重复的开头
+			var trim *overlapTrimmer
+			if n > 0 {
+				trim = &overlapTrimmer{prev: content.String()}
+			}
+			meta, ok := m.forward(sr, sw, &content, trim)
+			if !ok {
+				return
+			}
+			if meta != nil {
+				last = meta
+				total = addUsage(total, meta.Usage)
+			}
+
+			if n >= limit || last == nil || last.FinishReason != FinishReasonLength {
+				sw.Send(final(last, total, n), nil)
+				return
+			}
+			if sr, err = m.inner.Stream(ctx, m.next(in, content.String()), opts...); err != nil {
+				sw.Send(nil, fmt.Errorf("continuation: 第 %d 次续写失败: %w", n+1, err))
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// forward 转发一段输出，返回这一段的 ResponseMeta。
+// 各段的用量和结束原因从分片中去掉，最后由 final 统一输出合计值；ok 为 false 表示出错或调用方已关闭
+func (m *ChatModel) forward(sr *schema.StreamReader[*schema.Message], sw *schema.StreamWriter[*schema.Message],
+	content *strings.Builder, trim *overlapTrimmer) (meta *schema.ResponseMeta, ok bool) {
+	defer sr.Close()
+
+	send := func(chunk *schema.Message) bool {
+		content.WriteString(chunk.Content)
+		return !sw.Send(chunk, nil)
+	}
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			sw.Send(nil, err)
+			return nil, false
+		}
+
+		c := *chunk
+		if c.ResponseMeta != nil {
+			meta = mergeMeta(meta, c.ResponseMeta)
+			rm := *c.ResponseMeta
+			rm.Usage, rm.FinishReason = nil, ""
+			c.ResponseMeta = &rm
+		}
+		if trim != nil {
+			c.Content = trim.feed(c.Content)
+		}
+		if !send(&c) {
+			return nil, false
+		}
+	}
+	if trim != nil {
+		if rest := trim.flush(); rest != "" {
+			if !send(&schema.Message{Role: schema.Assistant, Content: rest}) {
+				return nil, false
+			}
+		}
+	}
+	return meta, true
+}
+
+// final 最后一个分片：合计用量、最后一段的结束原因和续写次数
+func final(last *schema.ResponseMeta, total *schema.TokenUsage, n int) *schema.Message {
+	msg := &schema.Message{Role: schema.Assistant, ResponseMeta: &schema.ResponseMeta{Usage: total}}
+	if last != nil {
+		msg.ResponseMeta.FinishReason = last.FinishReason
+	}
+	if n > 0 {
+		msg.Extra = withContinuations(nil, n)
+	}
+	return msg
+}
+
+func (m *ChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	tc, ok := m.inner.(model.ToolCallingChatModel)
+	if !ok {
+		return nil, fmt.Errorf("continuation: 底层模型 %T 不支持 WithTools", m.inner)
+	}
+	inner, err := tc.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatModel{inner: inner, cfg: m.cfg}, nil
+}
+
+func (m *ChatModel) GetType() string {
+	return "Continuation"
+}
+
+// IsCallbacksEnabled 每一段请求都由底层模型触发回调，用量按实际请求次数统计
+func (m *ChatModel) IsCallbacksEnabled() bool {
+	return components.IsCallbacksEnabled(m.inner)
+}
+
+// Truncated 消息是否因长度被截断；带工具调用的输出不续写
+func Truncated(msg *schema.Message) bool {
+	return msg.ResponseMeta != nil && msg.ResponseMeta.FinishReason == FinishReasonLength && len(msg.ToolCalls) == 0
+}
+
+func usageOf(msg *schema.Message) *schema.TokenUsage {
+	if msg == nil || msg.ResponseMeta == nil {
+		return nil
+	}
+	return msg.ResponseMeta.Usage
+}
+
+// addUsage 返回 a、b 之和，不修改参数
+func addUsage(a, b *schema.TokenUsage) *schema.TokenUsage {
+	if b == nil {
+		return a
+	}
+	sum := &schema.TokenUsage{}
+	if a != nil {
+		*sum = *a
+	}
+	sum.PromptTokens += b.PromptTokens
+	sum.PromptTokenDetails.CachedTokens += b.PromptTokenDetails.CachedTokens
+	sum.CompletionTokens += b.CompletionTokens
+	sum.CompletionTokensDetails.ReasoningTokens += b.CompletionTokensDetails.ReasoningTokens
+	sum.TotalTokens += b.TotalTokens
+	return sum
+}
+
+// mergeMeta 合并同一段输出中多个分片的 ResponseMeta，用量取最后出现的值
+func mergeMeta(acc, m *schema.ResponseMeta) *schema.ResponseMeta {
+	out := &schema.ResponseMeta{}
+	if acc != nil {
+		*out = *acc
+	}
+	if m.FinishReason != "" {
+		out.FinishReason = m.FinishReason
+	}
+	if m.Usage != nil {
+		out.Usage = m.Usage
+	}
+	return out
+}
+
+func withContinuations(extra map[string]any, n int) map[string]any {
+	out := make(map[string]any, len(extra)+1)
+	for k, v := range extra {
+		out[k] = v
+	}
+	out[ExtraContinuations] = n
+	return out
+}
+
+// overlap prev 结尾与 next 开头重复的最大长度（字节），只检查 overlapWindow 个字符以内，
+// 太短的重复不算，见 minOverlap
+func overlap(prev, next string) int {
+	runes := []rune(prev)
+	start := max(0, len(runes)-overlapWindow)
+	for i := start; i < len(runes); i++ {
+		n := len(runes) - i
+		if n < minAlignedOverlap || n < minOverlap && !sentenceStart(runes, i) {
+			continue
+		}
+		if suffix := string(runes[i:]); strings.HasPrefix(next, suffix) {
+			return len(suffix)
+		}
+	}
+	return 0
+}
+
+// sentenceStart runes[i] 是否在一行或一句话的开头
+func sentenceStart(runes []rune, i int) bool {
+	return i == 0 || strings.ContainsRune("\n。！？；.!?;", runes[i-1])
+}
+
+// trimOverlap 去掉续写开头与上一段结尾重复的部分
+func trimOverlap(prev, next string) string {
+	return next[overlap(prev, next):]
+}
+
+// overlapTrimmer 流式地去掉续写开头的重复部分：先缓存开头，足够判断后再输出
+type overlapTrimmer struct {
+	prev string
+	buf  strings.Builder
+	done bool
+}
+
+func (t *overlapTrimmer) feed(s string) string {
+	if t.done {
+		return s
+	}
+	t.buf.WriteString(s)
+	if t.buf.Len() < len(t.prev) && t.buf.Len() < overlapWindow*4 {
+		// 缓存的内容可能仍是上一段结尾的一部分
+		if strings.HasSuffix(t.prev, t.buf.String()) || partOfTail(t.prev, t.buf.String()) {
+			return ""
+		}
+	}
+	return t.flush()
+}
+
+// flush 输出缓存中去掉重复后的内容
+func (t *overlapTrimmer) flush() string {
+	if t.done {
+		return ""
+	}
+	t.done = true
+	return trimOverlap(t.prev, t.buf.String())
+}
+
+// partOfTail s 是否是 prev 结尾某个位置开始的前缀，也就是后续分片仍可能与结尾完全重合
+func partOfTail(prev, s string) bool {
+	tail := prev
+	if r := []rune(prev); len(r) > overlapWindow {
+		tail = string(r[len(r)-overlapWindow:])
+	}
+	for i := range tail {
+		rest := tail[i:]
+		if len(s) < len(rest) && strings.HasPrefix(rest, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package continuation
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// scripted 依次返回 pieces，除最后一段外都标记为因长度截断
+type scripted struct {
+	pieces []string
+	calls  int
+}
+
+func (m *scripted) message() *schema.Message {
+	i := m.calls
+	m.calls++
+	reason := "stop"
+	if i < len(m.pieces)-1 {
+		reason = FinishReasonLength
+	}
+	msg := schema.AssistantMessage(m.pieces[i], nil)
+	msg.ResponseMeta = &schema.ResponseMeta{FinishReason: reason}
+	return msg
+}
+
+func (m *scripted) Generate(context.Context, []*schema.Message, ...model.Option) (*schema.Message, error) {
+	return m.message(), nil
+}
+
+// Stream 把每段按字符拆成分片，最后一个分片带 FinishReason
+func (m *scripted) Stream(context.Context, []*schema.Message, ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg := m.message()
+	var chunks []*schema.Message
+	for _, r := range msg.Content {
+		chunks = append(chunks, schema.AssistantMessage(string(r), nil))
+	}
+	chunks = append(chunks, &schema.Message{Role: schema.Assistant, ResponseMeta: msg.ResponseMeta})
+	return schema.StreamReaderFromArray(chunks), nil
+}
+
+func TestOverlap(t *testing.T) {
+	cases := []struct {
+		name, prev, next, want string
+	}{
+		{"短重复保留", "价格是 100", "0 元。", "0 元。"},
+		{"单个字符", "hello", "o world", "o world"},
+		{"长重复去掉", "第一段内容，这里是一句比较长的话", "这里是一句比较长的话，后续内容", "，后续内容"},
+		{"整行重复去掉", "第一行\n第二行内容", "第二行内容\n第三行", "\n第三行"},
+		{"整句重复去掉", "结论如下。答案是三", "答案是三小时。", "小时。"},
+		{"没有重复", "abc", "def", "def"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := trimOverlap(c.prev, c.next); got != c.want {
+				t.Errorf("trimOverlap(%q, %q) = %q，期望 %q", c.prev, c.next, got, c.want)
+			}
+		})
+	}
+}
+
+func TestGenerateKeepsShortOverlap(t *testing.T) {
+	cm := New(&scripted{pieces: []string{"价格是 100", "0 元。"}}, nil)
+	msg, err := cm.Generate(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Content != "价格是 1000 元。" {
+		t.Errorf("Content = %q", msg.Content)
+	}
+	if Continuations(msg) != 1 {
+		t.Errorf("Continuations = %d", Continuations(msg))
+	}
+}
+
+func TestStreamOverlap(t *testing.T) {
+	cases := []struct {
+		pieces []string
+		want   string
+	}{
+		{[]string{"价格是 100", "0 元。"}, "价格是 1000 元。"},
+		{[]string{"开头部分，这里是一句比较长的话", "这里是一句比较长的话，然后继续。"}, "开头部分，这里是一句比较长的话，然后继续。"},
+	}
+	for _, c := range cases {
+		cm := New(&scripted{pieces: c.pieces}, nil)
+		sr, err := cm.Stream(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sb strings.Builder
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			sb.WriteString(chunk.Content)
+		}
+		if sb.String() != c.want {
+			t.Errorf("Stream(%q) = %q，期望 %q", c.pieces, sb.String(), c.want)
+		}
+	}
+}
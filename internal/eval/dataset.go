@@ -0,0 +1,76 @@
+// Package eval 对比多个提示词模板在同一数据集上的表现。
+//
+// 数据集是 JSONL 文件，每行一个用例：模板变量以及期望输出、正则、JSON Schema 或评分标准（按需填写）：
+//
+//	{"id": "refund", "vars": {"text": "退款一直没到账"}, "expected": "负面", "pattern": "负面", "rubric": "..."}
+//
+// Run 用每个模板变体渲染每个用例并请求模型（可以是 replay 回放模型），再用 ExactMatch、Regex、
+// JSONSchema、Judge 等指标打分。Report 汇总各变体的平均分，并给出每个用例的输出差异。
+package eval
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudwego/eino/components/prompt"
+)
+
+// Case 一个评测用例
+type Case struct {
+	ID string `json:"id"`
+	// Vars 渲染模板的变量
+	Vars map[string]any `json:"vars"`
+	// Expected 期望输出，ExactMatch 使用
+	Expected string `json:"expected,omitempty"`
+	// Pattern 输出需要匹配的正则，Regex 使用
+	Pattern string `json:"pattern,omitempty"`
+	// Schema 输出中的 JSON 需要满足的 JSON Schema，JSONSchema 使用
+	Schema json.RawMessage `json:"schema,omitempty"`
+	// Rubric 评分标准，Judge 使用
+	Rubric string `json:"rubric,omitempty"`
+}
+
+// Variant 参与对比的一个模板
+type Variant struct {
+	Name     string
+	Template prompt.ChatTemplate
+}
+
+// LoadDataset 读取 JSONL 数据集，空行和 # 开头的行会被忽略；没有 id 的用例按行号命名
+func LoadDataset(path string) ([]*Case, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("eval: %w", err)
+	}
+	defer f.Close()
+
+	var cases []*Case
+	ids := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		c := &Case{}
+		if err := json.Unmarshal([]byte(text), c); err != nil {
+			return nil, fmt.Errorf("eval: %s 第 %d 行: %w", path, line, err)
+		}
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("line-%d", line)
+		}
+		if ids[c.ID] {
+			return nil, fmt.Errorf("eval: %s 第 %d 行: 用例 %s 重复", path, line, c.ID)
+		}
+		ids[c.ID] = true
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("eval: 读取 %s 失败: %w", path, err)
+	}
+	return cases, nil
+}
@@ -0,0 +1,77 @@
+package eval
+
+import (
+	"strings"
+)
+
+// maxDiffCells 逐字对比的规模上限（两段文本字符数的乘积），超过时按行对比
+const maxDiffCells = 4_000_000
+
+// Diff 返回从 a 到 b 的差异，[-...-] 为 a 中删除的部分，{+...+} 为 b 中新增的部分。
+// 中文没有空格分词，所以按字符对比；文本较长时按行对比
+func Diff(a, b string) string {
+	if a == b {
+		return a
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra)*len(rb) <= maxDiffCells {
+		return diffTokens(runeTokens(ra), runeTokens(rb))
+	}
+	return diffTokens(strings.SplitAfter(a, "\n"), strings.SplitAfter(b, "\n"))
+}
+
+func runeTokens(rs []rune) []string {
+	out := make([]string, len(rs))
+	for i, r := range rs {
+		out[i] = string(r)
+	}
+	return out
+}
+
+// diffTokens 按最长公共子序列对比两组片段
+func diffTokens(a, b []string) string {
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out, del, ins strings.Builder
+	flush := func() {
+		if del.Len() > 0 {
+			out.WriteString("[-" + del.String() + "-]")
+			del.Reset()
+		}
+		if ins.Len() > 0 {
+			out.WriteString("{+" + ins.String() + "+}")
+			ins.Reset()
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			out.WriteString(a[i])
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			ins.WriteString(b[j])
+			j++
+		default:
+			del.WriteString(a[i])
+			i++
+		}
+	}
+	flush()
+	return out.String()
+}
@@ -0,0 +1,33 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		a, b, want string
+	}{
+		{"相同", "相同", "相同"},
+		{"今天天气很好", "今天天气不好", "今天天气[-很-]{+不+}好"},
+		{"abc", "abxc", "ab{+x+}c"},
+		{"删除一段文字", "删除文字", "删除[-一段-]文字"},
+		{"", "新增", "{+新增+}"},
+	}
+	for _, c := range cases {
+		if got := Diff(c.a, c.b); got != c.want {
+			t.Errorf("Diff(%q, %q) = %q，期望 %q", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestDiffLongTextByLine(t *testing.T) {
+	line := strings.Repeat("长", 1500) + "\n"
+	a := line + "旧的一行\n" + line
+	b := line + "新的一行\n" + line
+	want := line + "[-旧的一行\n-]{+新的一行\n+}" + line
+	if got := Diff(a, b); got != want {
+		t.Errorf("按行对比结果不符:\n%s", got)
+	}
+}
@@ -0,0 +1,80 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExactMatch(t *testing.T) {
+	ctx := context.Background()
+	c := &Case{ID: "c", Expected: " 负面 "}
+	cases := []struct {
+		m      *ExactMatch
+		output string
+		want   float64
+	}{
+		{&ExactMatch{}, "负面\n", 1},
+		{&ExactMatch{}, "正面", 0},
+		{&ExactMatch{}, "NEGATIVE", 0},
+		{&ExactMatch{Extract: func(s string) string { return strings.TrimPrefix(s, "情感：") }}, "情感：负面", 1},
+	}
+	for _, tc := range cases {
+		s, err := tc.m.Score(ctx, c, tc.output)
+		if err != nil || s.Value != tc.want {
+			t.Errorf("Score(%q) = %+v, %v，期望 %v", tc.output, s, err, tc.want)
+		}
+	}
+	s, _ := (&ExactMatch{IgnoreCase: true}).Score(ctx, &Case{Expected: "Positive"}, "positive")
+	if s.Value != 1 {
+		t.Errorf("IgnoreCase 时应匹配: %+v", s)
+	}
+	if s, _ := (&ExactMatch{}).Score(ctx, &Case{}, "任意"); !s.Skipped {
+		t.Errorf("没有 Expected 时应跳过: %+v", s)
+	}
+}
+
+func TestRegex(t *testing.T) {
+	ctx := context.Background()
+	m := &Regex{}
+	if s, err := m.Score(ctx, &Case{Pattern: `^\d+ 元$`}, "100 元"); err != nil || s.Value != 1 {
+		t.Errorf("应匹配: %+v, %v", s, err)
+	}
+	if s, err := m.Score(ctx, &Case{Pattern: `^\d+ 元$`}, "一百元"); err != nil || s.Value != 0 || s.Detail == "" {
+		t.Errorf("不匹配时应得 0 分并说明原因: %+v, %v", s, err)
+	}
+	if s, _ := m.Score(ctx, &Case{}, "任意"); !s.Skipped {
+		t.Errorf("没有 Pattern 时应跳过: %+v", s)
+	}
+	// 正则无效属于数据集配置错误，返回错误
+	if _, err := m.Score(ctx, &Case{ID: "bad", Pattern: `(`}, "x"); err == nil {
+		t.Error("无效的正则应返回错误")
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	ctx := context.Background()
+	schema := json.RawMessage(`{"type": "object", "properties": {"label": {"type": "string", "enum": ["正面", "负面"]}}, "required": ["label"]}`)
+	c := &Case{ID: "c", Schema: schema}
+	cases := []struct {
+		output string
+		want   float64
+	}{
+		{`{"label": "负面"}`, 1},
+		{"结果如下：\n```json\n{\"label\": \"正面\"}\n```", 1},
+		{`{"label": "中性"}`, 0},
+		{`{}`, 0},
+		{"没有 JSON", 0},
+	}
+	m := &JSONSchema{}
+	for _, tc := range cases {
+		s, err := m.Score(ctx, c, tc.output)
+		if err != nil || s.Value != tc.want {
+			t.Errorf("Score(%q) = %+v, %v，期望 %v", tc.output, s, err, tc.want)
+		}
+	}
+	if _, err := m.Score(ctx, &Case{ID: "bad", Schema: json.RawMessage(`[`)}, "{}"); err == nil {
+		t.Error("无效的 schema 应返回错误")
+	}
+}
@@ -0,0 +1,198 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Aggregate 一个变体在一个指标上的汇总
+type Aggregate struct {
+	// Mean 参与评分的用例的平均分
+	Mean float64 `json:"mean"`
+	// Count 参与评分的用例数（不含跳过和出错的用例）
+	Count int `json:"count"`
+	// Errors 评分出错的用例数
+	Errors int `json:"errors,omitempty"`
+}
+
+// Summary 一个变体的汇总
+type Summary struct {
+	Variant string               `json:"variant"`
+	Metrics map[string]Aggregate `json:"metrics"`
+	Errors  int                  `json:"errors"`
+	// Latency 平均耗时
+	Latency time.Duration `json:"latency"`
+	Tokens  int           `json:"tokens"`
+}
+
+// Report 评测报告
+type Report struct {
+	Variants  []string   `json:"variants"`
+	Metrics   []string   `json:"metrics"`
+	Cases     []*Case    `json:"cases"`
+	Results   []*Result  `json:"results"`
+	Summaries []*Summary `json:"summaries"`
+}
+
+func newReport(cfg *Config, cases []*Case, results []*Result) *Report {
+	r := &Report{Cases: cases, Results: results}
+	for _, m := range cfg.Metrics {
+		r.Metrics = append(r.Metrics, m.Name())
+	}
+	for _, v := range cfg.Variants {
+		r.Variants = append(r.Variants, v.Name)
+		s := &Summary{Variant: v.Name, Metrics: map[string]Aggregate{}}
+		var latency time.Duration
+		var n int
+		for _, res := range results {
+			if res.Variant != v.Name {
+				continue
+			}
+			if res.Error != "" {
+				s.Errors++
+				continue
+			}
+			n++
+			latency += res.Latency
+			s.Tokens += res.Tokens
+			for name, score := range res.Scores {
+				if score.Skipped {
+					continue
+				}
+				a := s.Metrics[name]
+				if score.Error != "" {
+					a.Errors++
+					s.Metrics[name] = a
+					continue
+				}
+				a.Mean = (a.Mean*float64(a.Count) + score.Value) / float64(a.Count+1)
+				a.Count++
+				s.Metrics[name] = a
+			}
+		}
+		if n > 0 {
+			s.Latency = latency / time.Duration(n)
+		}
+		r.Summaries = append(r.Summaries, s)
+	}
+	return r
+}
+
+// Best 返回在 metric 上平均分最高的变体，没有变体参与该指标时返回空串
+func (r *Report) Best(metric string) string {
+	best, bestMean := "", -1.0
+	for _, s := range r.Summaries {
+		if a, ok := s.Metrics[metric]; ok && a.Count > 0 && a.Mean > bestMean {
+			best, bestMean = s.Variant, a.Mean
+		}
+	}
+	return best
+}
+
+// WriteJSON 以缩进 JSON 输出报告
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
+
+// WriteTable 以表格输出各变体的平均分，"-" 表示没有用例参与该指标
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "变体\t%s\t出错\t平均耗时\ttokens\t\n", strings.Join(r.Metrics, "\t"))
+	for _, s := range r.Summaries {
+		fmt.Fprintf(tw, "%s\t", s.Variant)
+		for _, m := range r.Metrics {
+			a := s.Metrics[m]
+			switch {
+			case a.Count > 0 && a.Errors > 0:
+				fmt.Fprintf(tw, "%.2f (%d, %d 出错)\t", a.Mean, a.Count, a.Errors)
+			case a.Count > 0:
+				fmt.Fprintf(tw, "%.2f (%d)\t", a.Mean, a.Count)
+			case a.Errors > 0:
+				fmt.Fprintf(tw, "- (%d 出错)\t", a.Errors)
+			default:
+				fmt.Fprint(tw, "-\t")
+			}
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d\t\n", s.Errors, s.Latency.Round(time.Millisecond), s.Tokens)
+	}
+	return tw.Flush()
+}
+
+// WriteCases 逐个用例输出各变体的得分和输出，第一个变体作为基准完整输出，
+// 其它变体输出与基准的差异：[-...-] 为基准中有而该变体没有的部分，{+...+} 为该变体新增的部分
+func (r *Report) WriteCases(w io.Writer) error {
+	byCase := map[string]map[string]*Result{}
+	for _, res := range r.Results {
+		if byCase[res.Case] == nil {
+			byCase[res.Case] = map[string]*Result{}
+		}
+		byCase[res.Case][res.Variant] = res
+	}
+
+	var b strings.Builder
+	for _, c := range r.Cases {
+		results := byCase[c.ID]
+		fmt.Fprintf(&b, "=== %s ===\n", c.ID)
+		if c.Expected != "" {
+			fmt.Fprintf(&b, "期望: %s\n", c.Expected)
+		}
+
+		var base *Result
+		for _, v := range r.Variants {
+			res := results[v]
+			if res == nil {
+				continue
+			}
+			fmt.Fprintf(&b, "--- %s", v)
+			if res.Error != "" {
+				fmt.Fprintf(&b, "  错误: %s\n", res.Error)
+				continue
+			}
+			fmt.Fprintf(&b, "  %s\n", formatScores(r.Metrics, res.Scores))
+			for _, m := range r.Metrics {
+				if s := res.Scores[m]; s.Error != "" {
+					fmt.Fprintf(&b, "    %s: %s\n", m, s.Error)
+				} else if !s.Skipped && s.Detail != "" {
+					fmt.Fprintf(&b, "    %s: %s\n", m, s.Detail)
+				}
+			}
+			if base == nil {
+				base = res
+				fmt.Fprintf(&b, "%s\n", indent(res.Output))
+			} else {
+				fmt.Fprintf(&b, "    （与 %s 的差异）\n%s\n", base.Variant, indent(Diff(base.Output, res.Output)))
+			}
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatScores(metrics []string, scores map[string]Score) string {
+	parts := make([]string, 0, len(metrics))
+	for _, m := range metrics {
+		s, ok := scores[m]
+		switch {
+		case !ok:
+		case s.Skipped:
+			parts = append(parts, m+"=-")
+		case s.Error != "":
+			parts = append(parts, m+"=出错")
+		default:
+			parts = append(parts, fmt.Sprintf("%s=%.2f", m, s.Value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func indent(s string) string {
+	return "    " + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n    ")
+}
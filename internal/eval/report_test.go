@@ -0,0 +1,46 @@
+package eval
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewReportAggregates(t *testing.T) {
+	cfg := &Config{
+		Variants: []Variant{{Name: "a"}, {Name: "b"}},
+		Metrics:  []Metric{&ExactMatch{}, &Regex{}},
+	}
+	results := []*Result{
+		{Case: "1", Variant: "a", Latency: 100 * time.Millisecond, Tokens: 10, Scores: map[string]Score{"exact": {Value: 1}, "regex": skip}},
+		{Case: "2", Variant: "a", Latency: 300 * time.Millisecond, Tokens: 20, Scores: map[string]Score{"exact": {Value: 0}, "regex": {Value: 1}}},
+		{Case: "3", Variant: "a", Error: "生成失败"},
+		{Case: "1", Variant: "b", Latency: 200 * time.Millisecond, Scores: map[string]Score{"exact": {Value: 1}, "regex": {Error: "评审请求失败"}}},
+	}
+	r := newReport(cfg, nil, results)
+	if len(r.Summaries) != 2 || r.Metrics[0] != "exact" || r.Metrics[1] != "regex" {
+		t.Fatalf("report = %+v", r)
+	}
+
+	a := r.Summaries[0]
+	if a.Errors != 1 || a.Latency != 200*time.Millisecond || a.Tokens != 30 {
+		t.Errorf("变体 a: errors=%d latency=%s tokens=%d", a.Errors, a.Latency, a.Tokens)
+	}
+	if e := a.Metrics["exact"]; e.Count != 2 || math.Abs(e.Mean-0.5) > 1e-9 {
+		t.Errorf("变体 a exact = %+v，期望两个用例平均 0.5", e)
+	}
+	if re := a.Metrics["regex"]; re.Count != 1 || re.Mean != 1 {
+		t.Errorf("跳过的用例不应计入平均分: %+v", re)
+	}
+
+	b := r.Summaries[1]
+	if re := b.Metrics["regex"]; re.Count != 0 || re.Errors != 1 {
+		t.Errorf("评分出错的用例应单独计数: %+v", re)
+	}
+	if got := r.Best("exact"); got != "b" {
+		t.Errorf("Best(exact) = %q，期望 b", got)
+	}
+	if got := r.Best("regex"); got != "a" {
+		t.Errorf("Best(regex) = %q，期望 a", got)
+	}
+}
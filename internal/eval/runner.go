@@ -0,0 +1,124 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+)
+
+// Config 评测配置
+type Config struct {
+	// Model 被评测的模型
+	Model    model.BaseChatModel
+	Variants []Variant
+	Metrics  []Metric
+	// Concurrency 同时进行的请求数，默认 4
+	Concurrency int
+}
+
+// Result 一个变体在一个用例上的结果
+type Result struct {
+	Case    string           `json:"case"`
+	Variant string           `json:"variant"`
+	Output  string           `json:"output"`
+	Error   string           `json:"error,omitempty"`
+	Scores  map[string]Score `json:"scores,omitempty"`
+	Latency time.Duration    `json:"latency"`
+	Tokens  int              `json:"tokens,omitempty"`
+}
+
+// Run 用每个变体跑一遍全部用例并打分。
+// 单个用例渲染或请求失败只记录在 Result.Error 中，评审模型请求失败记录在 Score.Error 中，都不中断评测；
+// 指标本身配置错误（如正则无效）时返回错误
+func Run(ctx context.Context, cfg *Config, cases []*Case) (*Report, error) {
+	if cfg == nil || cfg.Model == nil {
+		return nil, errors.New("eval: 缺少 Model")
+	}
+	if len(cfg.Variants) == 0 || len(cfg.Metrics) == 0 {
+		return nil, errors.New("eval: 至少需要一个变体和一个指标")
+	}
+	names := map[string]bool{}
+	for _, v := range cfg.Variants {
+		if v.Name == "" || v.Template == nil || names[v.Name] {
+			return nil, fmt.Errorf("eval: 变体 %q 缺少名称、模板或名称重复", v.Name)
+		}
+		names[v.Name] = true
+	}
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*Result, len(cases)*len(cfg.Variants))
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+	for i, c := range cases {
+		for j, v := range cfg.Variants {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				r, err := runOne(ctx, cfg, c, v)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+				results[i*len(cfg.Variants)+j] = r
+			}()
+		}
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return newReport(cfg, cases, results), nil
+}
+
+func runOne(ctx context.Context, cfg *Config, c *Case, v Variant) (*Result, error) {
+	r := &Result{Case: c.ID, Variant: v.Name}
+	msgs, err := v.Template.Format(ctx, c.Vars)
+	if err != nil {
+		r.Error = "渲染失败: " + err.Error()
+		return r, nil
+	}
+	start := time.Now()
+	resp, err := cfg.Model.Generate(ctx, msgs)
+	r.Latency = time.Since(start)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		r.Error = "生成失败: " + err.Error()
+		return r, nil
+	}
+	r.Output = resp.Content
+	if resp.ResponseMeta != nil && resp.ResponseMeta.Usage != nil {
+		r.Tokens = resp.ResponseMeta.Usage.TotalTokens
+	}
+
+	r.Scores = make(map[string]Score, len(cfg.Metrics))
+	for _, m := range cfg.Metrics {
+		s, err := m.Score(ctx, c, r.Output)
+		if err != nil {
+			return nil, fmt.Errorf("eval: 用例 %s 变体 %s 指标 %s: %w", c.ID, v.Name, m.Name(), err)
+		}
+		r.Scores[m.Name()] = s
+	}
+	return r, nil
+}
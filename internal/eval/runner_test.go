@@ -0,0 +1,77 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+)
+
+// fakeModel 原样返回最后一条消息；err 不为 nil 时返回该错误
+type fakeModel struct {
+	err error
+}
+
+func (m *fakeModel) Generate(_ context.Context, in []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return schema.AssistantMessage(in[len(in)-1].Content, nil), nil
+}
+
+func (m *fakeModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func TestRunJudgeFailureKeepsResults(t *testing.T) {
+	judge, err := NewJudge(&fakeModel{err: errors.New("429 Too Many Requests")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{
+		Model: &fakeModel{},
+		Variants: []Variant{
+			{Name: "plain", Template: prompt.FromMessages(schema.FString, schema.UserMessage("{text}"))},
+		},
+		Metrics: []Metric{&ExactMatch{}, judge},
+	}
+	cases := []*Case{
+		{ID: "1", Vars: map[string]any{"text": "负面"}, Expected: "负面", Rubric: "回答情感倾向"},
+		{ID: "2", Vars: map[string]any{"text": "正面"}, Expected: "负面", Rubric: "回答情感倾向"},
+	}
+	report, err := Run(context.Background(), cfg, cases)
+	if err != nil {
+		t.Fatalf("评审请求失败不应中断评测: %v", err)
+	}
+	s := report.Summaries[0]
+	if e := s.Metrics["exact"]; e.Count != 2 || e.Mean != 0.5 {
+		t.Errorf("exact = %+v", e)
+	}
+	if j := s.Metrics["judge"]; j.Count != 0 || j.Errors != 2 {
+		t.Errorf("judge = %+v，期望两个用例评分出错", j)
+	}
+	for _, r := range report.Results {
+		if r.Scores["judge"].Error == "" {
+			t.Errorf("用例 %s 应记录评审错误: %+v", r.Case, r.Scores["judge"])
+		}
+	}
+}
+
+func TestRunInvalidMetricAborts(t *testing.T) {
+	cfg := &Config{
+		Model:    &fakeModel{},
+		Variants: []Variant{{Name: "plain", Template: prompt.FromMessages(schema.FString, schema.UserMessage("{text}"))}},
+		Metrics:  []Metric{&Regex{}},
+	}
+	_, err := Run(context.Background(), cfg, []*Case{{ID: "1", Vars: map[string]any{"text": "x"}, Pattern: "("}})
+	if err == nil {
+		t.Error("无效的正则应中断评测")
+	}
+}
@@ -0,0 +1,303 @@
+// Package fallback 提供多后端故障转移与路由的 ChatModel。
+//
+// 一个 ChatModel 持有有序的后端列表：当前后端返回可重试错误（限流、5xx、网络错误）或超时时，
+// 依次尝试下一个后端。路由规则可以按请求特征（是否带工具、上下文长度、是否流式）选择后端顺序。
+//
+// 实际提供服务的后端名称会写入响应的 Extra["served_by"]（见 ServedBy），
+// 同时后端触发的回调中 RunInfo.Name 为后端名称。
+package fallback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/modelcb"
+	"eino-tutorial/internal/retry"
+	"eino-tutorial/internal/streamx"
+)
+
+// ExtraServedBy 响应 Extra 中记录实际后端名称的键
+const ExtraServedBy = "served_by"
+
+// ServedBy 返回实际提供响应的后端名称
+func ServedBy(msg *schema.Message) (string, bool) {
+	if msg == nil || msg.Extra == nil {
+		return "", false
+	}
+	name, ok := msg.Extra[ExtraServedBy].(string)
+	return name, ok
+}
+
+// Backend 一个后端模型
+type Backend struct {
+	Name  string
+	Model model.ToolCallingChatModel
+	// Timeout 单次尝试的超时时间；流式调用只限制首个分片的等待时间。0 表示不限制
+	Timeout time.Duration
+}
+
+// Config 故障转移配置
+type Config struct {
+	// Backends 默认按顺序尝试的后端
+	Backends []*Backend
+	// Rules 路由规则，使用第一条匹配的规则；都不匹配时使用 Backends 的顺序
+	Rules []*Rule
+	// ShouldFallback 判断错误是否切换到下一个后端，默认 retry.IsRetryable
+	ShouldFallback func(err error) bool
+	// OnFallback 切换后端时调用，可用于打印日志
+	OnFallback func(from string, err error)
+}
+
+// Error 所有后端都失败时返回的错误
+type Error struct {
+	// Errs 每个后端的错误
+	Errs map[string]error
+	// Order 按尝试顺序记录的后端名称
+	Order []string
+}
+
+func (e *Error) Error() string {
+	last := e.Order[len(e.Order)-1]
+	return fmt.Sprintf("所有后端均失败 %v，最后一个 %s: %v", e.Order, last, e.Errs[last])
+}
+
+func (e *Error) Unwrap() []error {
+	errs := make([]error, 0, len(e.Order))
+	for _, name := range e.Order {
+		errs = append(errs, e.Errs[name])
+	}
+	return errs
+}
+
+// ChatModel 故障转移 / 路由 ChatModel
+type ChatModel struct {
+	backends map[string]*Backend
+	order    []string
+	rules    []*Rule
+	should   func(err error) bool
+	onFall   func(from string, err error)
+	tools    []*schema.ToolInfo
+}
+
+var _ model.ToolCallingChatModel = (*ChatModel)(nil)
+
+// New 创建故障转移 ChatModel
+func New(cfg *Config) (*ChatModel, error) {
+	if cfg == nil || len(cfg.Backends) == 0 {
+		return nil, errors.New("fallback: 至少需要一个后端")
+	}
+	m := &ChatModel{
+		backends: make(map[string]*Backend, len(cfg.Backends)),
+		rules:    cfg.Rules,
+		should:   cfg.ShouldFallback,
+		onFall:   cfg.OnFallback,
+	}
+	if m.should == nil {
+		m.should = retry.IsRetryable
+	}
+	for _, b := range cfg.Backends {
+		if b.Name == "" || b.Model == nil {
+			return nil, errors.New("fallback: 后端必须有名称和模型")
+		}
+		if _, dup := m.backends[b.Name]; dup {
+			return nil, fmt.Errorf("fallback: 重复的后端名称 %q", b.Name)
+		}
+		m.backends[b.Name] = b
+		m.order = append(m.order, b.Name)
+	}
+	for _, r := range cfg.Rules {
+		if len(r.Backends) == 0 {
+			return nil, fmt.Errorf("fallback: 规则 %q 没有指定后端", r.Name)
+		}
+		for _, name := range r.Backends {
+			if _, ok := m.backends[name]; !ok {
+				return nil, fmt.Errorf("fallback: 规则 %q 引用了未知后端 %q", r.Name, name)
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m *ChatModel) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	req := m.request(in, false, opts)
+	var out *schema.Message
+	err := m.try(ctx, req, func(ctx context.Context, b *Backend) error {
+		if b.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, b.Timeout)
+			defer cancel()
+		}
+		msg, err := generate(ctx, b, in, opts)
+		if err != nil {
+			return err
+		}
+		out = withServedBy(msg, b.Name)
+		return nil
+	})
+	return out, err
+}
+
+func (m *ChatModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	req := m.request(in, true, opts)
+	var out *schema.StreamReader[*schema.Message]
+	err := m.try(ctx, req, func(ctx context.Context, b *Backend) error {
+		// 超时只限制首个分片，所以用定时器取消而不是 WithTimeout，并以 errFirstChunkTimeout 作为取消原因
+		ctx, cancel := context.WithCancelCause(ctx)
+		stop := func() { cancel(nil) }
+		var timer *time.Timer
+		if b.Timeout > 0 {
+			timer = time.AfterFunc(b.Timeout, func() { cancel(errFirstChunkTimeout) })
+		}
+		sr, err := stream(ctx, b, in, opts)
+		if err != nil {
+			err = firstChunkErr(ctx, b, err)
+			stop()
+			return err
+		}
+		// 首个分片之前失败才切换后端；之后的错误原样交给调用方
+		sr, err = streamx.Peek(sr, stop)
+		if timer != nil {
+			timer.Stop()
+		}
+		if err != nil {
+			return firstChunkErr(ctx, b, err)
+		}
+
+		first := true
+		out = schema.StreamReaderWithConvert(sr, func(msg *schema.Message) (*schema.Message, error) {
+			// Extra 中的字符串在合并分片时会被拼接，所以只在第一个分片上标记
+			if first {
+				first = false
+				return withServedBy(msg, b.Name), nil
+			}
+			return msg, nil
+		})
+		return nil
+	})
+	return out, err
+}
+
+// errFirstChunkTimeout 流式调用等待首个分片超时时 ctx 的取消原因
+var errFirstChunkTimeout = errors.New("等待首个分片超时")
+
+// firstChunkErr 把首个分片超时引起的取消换成超时错误，这样 ShouldFallback 会切换到下一个后端，
+// 而不是当作调用方取消
+func firstChunkErr(ctx context.Context, b *Backend, err error) error {
+	if errors.Is(context.Cause(ctx), errFirstChunkTimeout) {
+		return fmt.Errorf("后端 %s 超过 %s 没有返回首个分片: %w", b.Name, b.Timeout, context.DeadlineExceeded)
+	}
+	return err
+}
+
+func (m *ChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	nm := *m
+	nm.tools = tools
+	nm.backends = make(map[string]*Backend, len(m.backends))
+	for name, b := range m.backends {
+		bm, err := b.Model.WithTools(tools)
+		if err != nil {
+			return nil, fmt.Errorf("后端 %s 绑定工具失败: %w", name, err)
+		}
+		nb := *b
+		nb.Model = bm
+		nm.backends[name] = &nb
+	}
+	return &nm, nil
+}
+
+func (m *ChatModel) GetType() string {
+	return "Fallback"
+}
+
+// IsCallbacksEnabled 回调由各后端以自己的名称触发
+func (m *ChatModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+// Route 返回请求对应的后端尝试顺序
+func (m *ChatModel) Route(req *Request) []string {
+	for _, r := range m.rules {
+		if r.Match != nil && r.Match(req) {
+			return r.Backends
+		}
+	}
+	return m.order
+}
+
+func (m *ChatModel) request(in []*schema.Message, stream bool, opts []model.Option) *Request {
+	tools := m.tools
+	if o := model.GetCommonOptions(nil, opts...); o.Tools != nil {
+		tools = o.Tools
+	}
+	return &Request{Messages: in, Tools: tools, Stream: stream}
+}
+
+// try 按路由顺序尝试后端
+func (m *ChatModel) try(ctx context.Context, req *Request, call func(ctx context.Context, b *Backend) error) error {
+	fe := &Error{Errs: map[string]error{}}
+	order := m.Route(req)
+	for i, name := range order {
+		b := m.backends[name]
+		err := call(backendCtx(ctx, b), b)
+		if err == nil {
+			return nil
+		}
+		fe.Errs[name] = err
+		fe.Order = append(fe.Order, name)
+
+		// 调用方 ctx 结束或错误不可恢复（认证失败、参数错误）时不再切换
+		if ctx.Err() != nil || !m.should(err) {
+			return fe
+		}
+		if m.onFall != nil && i < len(order)-1 {
+			m.onFall(name, err)
+		}
+	}
+	return fe
+}
+
+// backendCtx 以后端名称作为 RunInfo.Name，回调处理器可以据此区分后端
+func backendCtx(ctx context.Context, b *Backend) context.Context {
+	typ, _ := components.GetType(b.Model)
+	return callbacks.ReuseHandlers(ctx, &callbacks.RunInfo{
+		Name:      b.Name,
+		Type:      typ,
+		Component: components.ComponentOfChatModel,
+	})
+}
+
+func generate(ctx context.Context, b *Backend, in []*schema.Message, opts []model.Option) (*schema.Message, error) {
+	if components.IsCallbacksEnabled(b.Model) {
+		return b.Model.Generate(ctx, in, opts...)
+	}
+	return modelcb.Generate(ctx, b.Name, in, opts, func(ctx context.Context) (*schema.Message, error) {
+		return b.Model.Generate(ctx, in, opts...)
+	})
+}
+
+func stream(ctx context.Context, b *Backend, in []*schema.Message, opts []model.Option) (*schema.StreamReader[*schema.Message], error) {
+	if components.IsCallbacksEnabled(b.Model) {
+		return b.Model.Stream(ctx, in, opts...)
+	}
+	return modelcb.Stream(ctx, b.Name, in, opts, func(ctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+		return b.Model.Stream(ctx, in, opts...)
+	})
+}
+
+func withServedBy(msg *schema.Message, name string) *schema.Message {
+	if msg == nil {
+		return nil
+	}
+	if msg.Extra == nil {
+		msg.Extra = map[string]any{}
+	}
+	msg.Extra[ExtraServedBy] = name
+	return msg
+}
@@ -0,0 +1,105 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// fake 返回固定回答；stall 为 true 时流式调用在首个分片之前一直等到 ctx 结束
+type fake struct {
+	reply string
+	stall bool
+}
+
+func (f *fake) Generate(context.Context, []*schema.Message, ...model.Option) (*schema.Message, error) {
+	return schema.AssistantMessage(f.reply, nil), nil
+}
+
+func (f *fake) Stream(ctx context.Context, _ []*schema.Message, _ ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	if !f.stall {
+		return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage(f.reply, nil)}), nil
+	}
+	sr, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer sw.Close()
+		<-ctx.Done()
+		sw.Send(nil, ctx.Err())
+	}()
+	return sr, nil
+}
+
+func (f *fake) WithTools([]*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return f, nil
+}
+
+func TestStreamFirstChunkTimeoutFallsBack(t *testing.T) {
+	var fellFrom string
+	m, err := New(&Config{
+		Backends: []*Backend{
+			{Name: "slow", Model: &fake{reply: "慢", stall: true}, Timeout: 20 * time.Millisecond},
+			{Name: "fast", Model: &fake{reply: "快"}},
+		},
+		OnFallback: func(from string, err error) {
+			fellFrom = from
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("切换原因 = %v，期望超时错误", err)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr, err := m.Stream(context.Background(), []*schema.Message{schema.UserMessage("你好")})
+	if err != nil {
+		t.Fatalf("Stream 返回错误: %v", err)
+	}
+	defer sr.Close()
+
+	var sb strings.Builder
+	var servedBy string
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name, ok := ServedBy(chunk); ok {
+			servedBy = name
+		}
+		sb.WriteString(chunk.Content)
+	}
+	if sb.String() != "快" || servedBy != "fast" {
+		t.Errorf("内容 = %q，served_by = %q，期望由 fast 提供", sb.String(), servedBy)
+	}
+	if fellFrom != "slow" {
+		t.Errorf("OnFallback from = %q，期望 slow", fellFrom)
+	}
+}
+
+func TestStreamCallerCancelDoesNotFallBack(t *testing.T) {
+	m, err := New(&Config{
+		Backends: []*Backend{
+			{Name: "slow", Model: &fake{stall: true}, Timeout: time.Minute},
+			{Name: "fast", Model: &fake{reply: "快"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = m.Stream(ctx, []*schema.Message{schema.UserMessage("你好")})
+	var fe *Error
+	if !errors.As(err, &fe) || len(fe.Order) != 1 {
+		t.Errorf("调用方 ctx 到期时不应切换后端，得到 %v", err)
+	}
+}
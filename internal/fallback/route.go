@@ -0,0 +1,64 @@
+package fallback
+
+import (
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/tokens"
+)
+
+// Request 路由时可用的请求特征
+type Request struct {
+	Messages []*schema.Message
+	// Tools 本次调用可用的工具（WithTools 绑定的或通过 model.WithTools 传入的）
+	Tools []*schema.ToolInfo
+	// Stream 是否为流式调用
+	Stream bool
+}
+
+// ApproxTokens 粗略估算输入的 token 数，见 tokens.Approx
+func (r *Request) ApproxTokens() int {
+	return tokens.Messages(r.Messages)
+}
+
+// Matcher 判断请求是否匹配某条路由规则
+type Matcher func(req *Request) bool
+
+// Rule 路由规则：匹配的请求按 Backends 的顺序尝试
+type Rule struct {
+	Name     string
+	Match    Matcher
+	Backends []string
+}
+
+// HasTools 匹配带工具的请求
+func HasTools() Matcher {
+	return func(req *Request) bool {
+		return len(req.Tools) > 0
+	}
+}
+
+// LongContext 匹配估算 token 数超过 threshold 的请求
+func LongContext(threshold int) Matcher {
+	return func(req *Request) bool {
+		return req.ApproxTokens() > threshold
+	}
+}
+
+// IsStream 匹配流式请求
+func IsStream() Matcher {
+	return func(req *Request) bool {
+		return req.Stream
+	}
+}
+
+// All 所有条件都满足时匹配
+func All(ms ...Matcher) Matcher {
+	return func(req *Request) bool {
+		for _, m := range ms {
+			if !m(req) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,315 @@
+// Package fewshot 从带标注的示例库中为每个问题动态选择 few-shot 示例。
+//
+// Selector 在创建时用 Embedder 计算所有示例输入的向量，Select 按与问题的余弦相似度（或最大边际相关性 MMR）
+// 选出最多 K 个示例，总 token 数不超过 MaxTokens。选出的示例以 user / assistant 消息对的形式
+// 通过 Template 注入 ChatTemplate 中的消息占位符：
+//
+//	template := prompt.FromMessages(schema.FString,
+//		schema.SystemMessage("..."),
+//		schema.MessagesPlaceholder("examples", true),
+//		schema.UserMessage("{text}"),
+//	)
+//	tpl := selector.Template(template, "text")
+package fewshot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/memory"
+	"eino-tutorial/internal/tokens"
+)
+
+// Strategy 选择策略
+type Strategy string
+
+const (
+	// Similarity 按与问题的相似度从高到低选择
+	Similarity Strategy = "similarity"
+	// MMR 最大边际相关性：兼顾与问题的相似度和示例之间的差异，避免选出几乎相同的示例
+	MMR Strategy = "mmr"
+)
+
+// DefaultKey 默认的消息占位符名称
+const DefaultKey = "examples"
+
+// Example 一条标注示例
+type Example struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+// Messages 示例对应的 user / assistant 消息对
+func (e Example) Messages() []*schema.Message {
+	return []*schema.Message{schema.UserMessage(e.Input), schema.AssistantMessage(e.Output, nil)}
+}
+
+// Config Selector 配置
+type Config struct {
+	// Embedder 计算示例和问题的向量，必填
+	Embedder embedding.Embedder
+	// K 最多选择的示例数，默认 3
+	K int
+	// Strategy 默认 Similarity
+	Strategy Strategy
+	// Lambda MMR 中相似度的权重（0~1），越小越看重多样性，0 表示只看多样性；nil 时默认 0.5
+	Lambda *float64
+	// MaxTokens 选中示例的 token 总数上限，0 表示不限制；放不下的示例会被跳过
+	MaxTokens int
+	// MinScore 相似度低于该值的示例不选，默认不过滤
+	MinScore float64
+	// Estimator 估算示例的 token 数，默认 tokens.Messages
+	Estimator memory.Estimator
+	// Key Template 注入示例时使用的占位符名称，默认 "examples"
+	Key string
+}
+
+// Selector 示例选择器，可以并发使用
+type Selector struct {
+	cfg    Config
+	lambda float64
+
+	mu       sync.RWMutex
+	examples []Example
+	vectors  [][]float64
+	tokens   []int
+}
+
+// Scored 选中的示例及其与问题的相似度
+type Scored struct {
+	Example
+	Score float64
+}
+
+// New 创建选择器并计算示例的向量
+func New(ctx context.Context, cfg *Config, examples ...Example) (*Selector, error) {
+	if cfg == nil || cfg.Embedder == nil {
+		return nil, errors.New("fewshot: 缺少 Embedder")
+	}
+	c := *cfg
+	if c.K <= 0 {
+		c.K = 3
+	}
+	switch c.Strategy {
+	case "":
+		c.Strategy = Similarity
+	case Similarity, MMR:
+	default:
+		return nil, fmt.Errorf("fewshot: 未知的选择策略 %q", c.Strategy)
+	}
+	lambda := 0.5
+	if c.Lambda != nil {
+		lambda = *c.Lambda
+		if lambda < 0 || lambda > 1 || math.IsNaN(lambda) {
+			return nil, fmt.Errorf("fewshot: Lambda 应在 0~1 之间，实际为 %v", lambda)
+		}
+	}
+	if c.Estimator == nil {
+		c.Estimator = tokens.Messages
+	}
+	if c.Key == "" {
+		c.Key = DefaultKey
+	}
+
+	s := &Selector{cfg: c, lambda: lambda}
+	if err := s.Add(ctx, examples...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Add 向示例库中添加示例
+func (s *Selector) Add(ctx context.Context, examples ...Example) error {
+	if len(examples) == 0 {
+		return nil
+	}
+	inputs := make([]string, len(examples))
+	tokens := make([]int, len(examples))
+	for i, e := range examples {
+		inputs[i] = e.Input
+		tokens[i] = s.cfg.Estimator(e.Messages())
+	}
+	vectors, err := s.cfg.Embedder.EmbedStrings(ctx, inputs)
+	if err != nil {
+		return fmt.Errorf("fewshot: 计算示例向量失败: %w", err)
+	}
+	if len(vectors) != len(examples) {
+		return fmt.Errorf("fewshot: Embedder 返回 %d 个向量，应为 %d 个", len(vectors), len(examples))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.examples = append(s.examples, examples...)
+	s.vectors = append(s.vectors, vectors...)
+	s.tokens = append(s.tokens, tokens...)
+	return nil
+}
+
+// Len 示例库中的示例数
+func (s *Selector) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.examples)
+}
+
+// Select 为 query 选择示例，按相似度从高到低排列
+func (s *Selector) Select(ctx context.Context, query string) ([]Scored, error) {
+	vectors, err := s.cfg.Embedder.EmbedStrings(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("fewshot: 计算问题向量失败: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("fewshot: Embedder 返回 %d 个向量，应为 1 个", len(vectors))
+	}
+	q := vectors[0]
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scores := make([]float64, len(s.examples))
+	var candidates []int
+	for i, v := range s.vectors {
+		scores[i] = Cosine(q, v)
+		if scores[i] >= s.cfg.MinScore || s.cfg.MinScore == 0 {
+			candidates = append(candidates, i)
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return scores[candidates[a]] > scores[candidates[b]]
+	})
+
+	var picked []int
+	if s.cfg.Strategy == MMR {
+		picked = s.mmr(candidates, scores)
+	} else {
+		picked = s.fit(candidates)
+	}
+	sort.SliceStable(picked, func(a, b int) bool {
+		return scores[picked[a]] > scores[picked[b]]
+	})
+
+	out := make([]Scored, len(picked))
+	for i, idx := range picked {
+		out[i] = Scored{Example: s.examples[idx], Score: scores[idx]}
+	}
+	return out, nil
+}
+
+// fit 按顺序选择放得进预算的示例
+func (s *Selector) fit(candidates []int) []int {
+	var picked []int
+	used := 0
+	for _, i := range candidates {
+		if len(picked) == s.cfg.K {
+			break
+		}
+		if !s.fits(used, i) {
+			continue
+		}
+		picked = append(picked, i)
+		used += s.tokens[i]
+	}
+	return picked
+}
+
+// mmr 每次选择 λ·相似度 − (1−λ)·与已选示例的最大相似度 最高、且放得进预算的示例
+func (s *Selector) mmr(candidates []int, scores []float64) []int {
+	var picked []int
+	used := 0
+	rest := append([]int(nil), candidates...)
+	for len(picked) < s.cfg.K && len(rest) > 0 {
+		best, bestScore := -1, math.Inf(-1)
+		for j, i := range rest {
+			if !s.fits(used, i) {
+				continue
+			}
+			redundancy := 0.0
+			for _, p := range picked {
+				redundancy = max(redundancy, Cosine(s.vectors[i], s.vectors[p]))
+			}
+			score := s.lambda*scores[i] - (1-s.lambda)*redundancy
+			if score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+		if best < 0 {
+			break
+		}
+		picked = append(picked, rest[best])
+		used += s.tokens[rest[best]]
+		rest = append(rest[:best], rest[best+1:]...)
+	}
+	return picked
+}
+
+func (s *Selector) fits(used, i int) bool {
+	return s.cfg.MaxTokens <= 0 || used+s.tokens[i] <= s.cfg.MaxTokens
+}
+
+// Messages 把示例转换为 user / assistant 消息对
+func Messages(examples []Scored) []*schema.Message {
+	msgs := make([]*schema.Message, 0, 2*len(examples))
+	for _, e := range examples {
+		msgs = append(msgs, e.Messages()...)
+	}
+	return msgs
+}
+
+// Cosine 余弦相似度，长度不同或有零向量时返回 0
+func Cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// LoadExamples 从 JSONL 文件读取示例，每行一个 {"input": ..., "output": ...}，空行和 # 开头的行会被忽略
+func LoadExamples(path string) ([]Example, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("fewshot: %w", err)
+	}
+	defer f.Close()
+
+	var examples []Example
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var e Example
+		if err := json.Unmarshal([]byte(text), &e); err != nil {
+			return nil, fmt.Errorf("fewshot: %s 第 %d 行: %w", path, line, err)
+		}
+		if e.Input == "" || e.Output == "" {
+			return nil, fmt.Errorf("fewshot: %s 第 %d 行缺少 input 或 output", path, line)
+		}
+		examples = append(examples, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("fewshot: 读取 %s 失败: %w", path, err)
+	}
+	return examples, nil
+}
@@ -0,0 +1,143 @@
+package fewshot
+
+import (
+	"context"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+var examples = []Example{
+	{Input: "这家餐厅的菜很好吃", Output: "正面"},
+	{Input: "这家餐厅的菜真好吃", Output: "正面"},
+	{Input: "快递太慢了，包装也破了", Output: "负面"},
+	{Input: "电影剧情一般", Output: "中性"},
+}
+
+// runeCount 用示例输入的字数作为 token 数，方便构造预算
+func runeCount(msgs []*schema.Message) int {
+	return utf8.RuneCountInString(msgs[0].Content)
+}
+
+func newSelector(t *testing.T, cfg Config) *Selector {
+	t.Helper()
+	cfg.Embedder = &NGramEmbedder{}
+	if cfg.Estimator == nil {
+		cfg.Estimator = runeCount
+	}
+	s, err := New(context.Background(), &cfg, examples...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func inputs(scored []Scored) []string {
+	out := make([]string, len(scored))
+	for i, s := range scored {
+		out[i] = s.Input
+	}
+	return out
+}
+
+func selectInputs(t *testing.T, s *Selector, query string) []string {
+	t.Helper()
+	scored, err := s.Select(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(scored); i++ {
+		if scored[i].Score > scored[i-1].Score {
+			t.Errorf("结果应按相似度从高到低排列: %+v", scored)
+		}
+	}
+	return inputs(scored)
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSelectSimilarityVsMMR(t *testing.T) {
+	const query = "这家餐厅的菜好吃吗"
+
+	sim := selectInputs(t, newSelector(t, Config{K: 2}), query)
+	if want := []string{examples[0].Input, examples[1].Input}; !equal(sim, want) {
+		t.Errorf("Similarity 选择 %q，期望 %q", sim, want)
+	}
+
+	// MMR 选了第一条餐厅示例后，几乎相同的第二条会因为冗余被跳过
+	lambda := 0.3
+	mmr := selectInputs(t, newSelector(t, Config{K: 2, Strategy: MMR, Lambda: &lambda}), query)
+	if len(mmr) != 2 || mmr[0] != examples[0].Input || mmr[1] == examples[1].Input {
+		t.Errorf("MMR 选择 %q，不应同时选中两条几乎相同的示例", mmr)
+	}
+
+	// λ=0 只看多样性，同样不会选中重复的示例
+	zero := 0.0
+	div := selectInputs(t, newSelector(t, Config{K: 2, Strategy: MMR, Lambda: &zero}), query)
+	if len(div) != 2 || (contains(div, examples[0].Input) && contains(div, examples[1].Input)) {
+		t.Errorf("λ=0 选择 %q，不应同时选中两条几乎相同的示例", div)
+	}
+
+	// λ=1 退化为按相似度选择
+	one := 1.0
+	if got := selectInputs(t, newSelector(t, Config{K: 2, Strategy: MMR, Lambda: &one}), query); !equal(got, sim) {
+		t.Errorf("λ=1 选择 %q，期望与 Similarity 相同 %q", got, sim)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSelectMaxTokens(t *testing.T) {
+	// 预算 16：第一条（9 字）放得下，第二条（9 字）和快递（11 字）放不下被跳过，电影（6 字）还能放下
+	for _, strategy := range []Strategy{Similarity, MMR} {
+		got := selectInputs(t, newSelector(t, Config{K: 3, Strategy: strategy, MaxTokens: 16}), "这家餐厅的菜好吃吗")
+		if len(got) != 2 || got[0] != examples[0].Input || got[1] != examples[3].Input {
+			t.Errorf("%s 在预算 16 下选择 %q，期望 [%q %q]", strategy, got, examples[0].Input, examples[3].Input)
+		}
+	}
+}
+
+func TestSelectMinScore(t *testing.T) {
+	got := selectInputs(t, newSelector(t, Config{K: 4, MinScore: 0.3}), "这家餐厅的菜好吃吗")
+	if want := []string{examples[0].Input, examples[1].Input}; !equal(got, want) {
+		t.Errorf("MinScore 0.3 选择 %q，期望 %q", got, want)
+	}
+	got = selectInputs(t, newSelector(t, Config{K: 4, MinScore: 0.99}), "完全无关的问题")
+	if len(got) != 0 {
+		t.Errorf("没有示例达到 MinScore 时应返回空，得到 %q", got)
+	}
+}
+
+func TestNewValidatesLambda(t *testing.T) {
+	for _, v := range []float64{-0.1, 1.5} {
+		lambda := v
+		_, err := New(context.Background(), &Config{Embedder: &NGramEmbedder{}, Strategy: MMR, Lambda: &lambda})
+		if err == nil {
+			t.Errorf("Lambda=%v 应返回错误", v)
+		}
+	}
+	if _, err := New(context.Background(), &Config{Embedder: &NGramEmbedder{}, Strategy: "random"}); err == nil {
+		t.Error("未知策略应返回错误")
+	}
+	if _, err := New(context.Background(), &Config{}); err == nil {
+		t.Error("缺少 Embedder 应返回错误")
+	}
+}
@@ -0,0 +1,112 @@
+package fewshot
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Template 返回一个 ChatTemplate：Format 时用 vs[queryVar] 选择示例，以 Config.Key 为名放入变量后交给 inner。
+// inner 中需要有同名的消息占位符；调用方已经提供了该变量时不再选择
+func (s *Selector) Template(inner prompt.ChatTemplate, queryVar string) prompt.ChatTemplate {
+	return &exampleTemplate{inner: inner, s: s, queryVar: queryVar}
+}
+
+type exampleTemplate struct {
+	inner    prompt.ChatTemplate
+	s        *Selector
+	queryVar string
+}
+
+func (t *exampleTemplate) Format(ctx context.Context, vs map[string]any, opts ...prompt.Option) ([]*schema.Message, error) {
+	key := t.s.cfg.Key
+	if _, ok := vs[key]; ok {
+		return t.inner.Format(ctx, vs, opts...)
+	}
+	query, ok := vs[t.queryVar].(string)
+	if !ok {
+		return nil, fmt.Errorf("fewshot: 变量 %s 应为字符串，实际为 %T", t.queryVar, vs[t.queryVar])
+	}
+	examples, err := t.s.Select(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(vs)+1)
+	for k, v := range vs {
+		values[k] = v
+	}
+	values[key] = Messages(examples)
+	return t.inner.Format(ctx, values, opts...)
+}
+
+func (t *exampleTemplate) GetType() string {
+	return "FewShot"
+}
+
+// IsCallbacksEnabled 回调由内部的 ChatTemplate 触发
+func (t *exampleTemplate) IsCallbacksEnabled() bool {
+	return true
+}
+
+// NGramEmbedder 把文本的字符 n-gram 哈希到固定维度的向量，不调用任何模型。
+// 只反映字面上的相似，适合没有 Embedding 模型时演示和测试
+type NGramEmbedder struct {
+	// N n-gram 长度，默认 2
+	N int
+	// Dim 向量维度，默认 512
+	Dim int
+}
+
+var _ embedding.Embedder = (*NGramEmbedder)(nil)
+
+func (e *NGramEmbedder) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	n, dim := e.N, e.Dim
+	if n <= 0 {
+		n = 2
+	}
+	if dim <= 0 {
+		dim = 512
+	}
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		out[i] = ngramVector(text, n, dim)
+	}
+	return out, nil
+}
+
+func ngramVector(text string, n, dim int) []float64 {
+	// 忽略标点和空白，英文不区分大小写
+	var runes []rune
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, r)
+		}
+	}
+	v := make([]float64, dim)
+	for size := 1; size <= n; size++ {
+		for i := 0; i+size <= len(runes); i++ {
+			h := fnv.New32a()
+			h.Write([]byte(string(runes[i : i+size])))
+			v[h.Sum32()%uint32(dim)]++
+		}
+	}
+	var norm float64
+	for _, x := range v {
+		norm += x * x
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range v {
+			v[i] /= norm
+		}
+	}
+	return v
+}
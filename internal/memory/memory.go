@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// Memory 组合存储与窗口策略
+type Memory struct {
+	store    Store
+	strategy Strategy
+}
+
+// New 创建对话记忆，strategy 为 nil 时发送全部历史
+func New(store Store, strategy Strategy) *Memory {
+	if strategy == nil {
+		strategy = KeepAll()
+	}
+	return &Memory{store: store, strategy: strategy}
+}
+
+// Messages 返回本次调用模型要发送的消息：系统提示词、早期对话摘要和窗口内的历史。
+//
+// 滚动摘要策略可能会更新 s.Summary，调用方需要在之后保存会话。
+func (m *Memory) Messages(ctx context.Context, s *Session) ([]*schema.Message, error) {
+	start, err := m.strategy.Window(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if start < s.Summarized || start > len(s.Messages) {
+		return nil, fmt.Errorf("memory: 窗口起点 %d 越界", start)
+	}
+
+	msgs := make([]*schema.Message, 0, len(s.Messages)-start+2)
+	if s.System != "" {
+		msgs = append(msgs, schema.SystemMessage(s.System))
+	}
+	if s.Summary != "" {
+		msgs = append(msgs, schema.SystemMessage("以下是之前对话的摘要：\n"+s.Summary))
+	}
+	return append(msgs, s.Messages[start:]...), nil
+}
+
+// Save 保存会话
+func (m *Memory) Save(ctx context.Context, s *Session) error {
+	return m.store.Save(ctx, s)
+}
+
+// Resume 加载已保存的会话
+func (m *Memory) Resume(ctx context.Context, id string) (*Session, error) {
+	return m.store.Load(ctx, id)
+}
+
+// Fork 从已保存的会话复制出一个新会话并保存
+func (m *Memory) Fork(ctx context.Context, id string) (*Session, error) {
+	s, err := m.store.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ns := s.Fork()
+	if err := m.store.Save(ctx, ns); err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
+
+// List 列出已保存的会话
+func (m *Memory) List(ctx context.Context) ([]*SessionInfo, error) {
+	return m.store.List(ctx)
+}
+
+// Delete 删除会话
+func (m *Memory) Delete(ctx context.Context, id string) error {
+	return m.store.Delete(ctx, id)
+}
+
+// FormatInfo 会话列表中一项的单行描述
+func FormatInfo(info *SessionInfo) string {
+	line := fmt.Sprintf("%s  %s  %d 轮  %s", info.ID, info.UpdatedAt.Format(time.DateTime), info.Turns, info.Title)
+	if info.Parent != "" {
+		line += fmt.Sprintf("（fork 自 %s）", info.Parent)
+	}
+	return line
+}
@@ -0,0 +1,117 @@
+// Package memory 提供可持久化、有上限的多轮对话记忆。
+//
+// Session 保存完整的对话历史，Store 负责持久化（文件或 SQLite），
+// Strategy 决定每次调用模型时发送哪一段历史（最近 N 轮、token 预算、滚动摘要）。
+package memory
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// Session 一个会话
+type Session struct {
+	ID string `json:"id"`
+	// Parent fork 来源的会话 ID
+	Parent string `json:"parent,omitempty"`
+	// System 系统提示词，每次调用都会发送
+	System   string            `json:"system,omitempty"`
+	Messages []*schema.Message `json:"messages"`
+	// Summary 早期对话的摘要
+	Summary string `json:"summary,omitempty"`
+	// Summarized Messages 中已经并入 Summary 的消息数
+	Summarized int       `json:"summarized,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// SessionInfo 会话列表中的一项
+type SessionInfo struct {
+	ID        string    `json:"id"`
+	Parent    string    `json:"parent,omitempty"`
+	Title     string    `json:"title"`
+	Turns     int       `json:"turns"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewSession 创建一个新会话
+func NewSession(system string) *Session {
+	now := time.Now()
+	return &Session{ID: newID(now), System: system, CreatedAt: now, UpdatedAt: now}
+}
+
+func newID(now time.Time) string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return now.Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+// Append 追加消息
+func (s *Session) Append(msgs ...*schema.Message) {
+	s.Messages = append(s.Messages, msgs...)
+	s.UpdatedAt = time.Now()
+}
+
+// Turns 对话轮数（以用户消息计）
+func (s *Session) Turns() int {
+	n := 0
+	for _, m := range s.Messages {
+		if m.Role == schema.User {
+			n++
+		}
+	}
+	return n
+}
+
+// Title 以第一条用户消息作为标题
+func (s *Session) Title() string {
+	for _, m := range s.Messages {
+		if m.Role == schema.User {
+			return truncate(m.Content, 30)
+		}
+	}
+	return "（空会话）"
+}
+
+// Info 返回会话列表项
+func (s *Session) Info() *SessionInfo {
+	return &SessionInfo{ID: s.ID, Parent: s.Parent, Title: s.Title(), Turns: s.Turns(), UpdatedAt: s.UpdatedAt}
+}
+
+// Fork 复制出一个新会话，之后两个会话互不影响
+func (s *Session) Fork() *Session {
+	now := time.Now()
+	ns := *s
+	ns.ID = newID(now)
+	ns.Parent = s.ID
+	ns.Messages = make([]*schema.Message, len(s.Messages))
+	for i, m := range s.Messages {
+		cm := *m
+		ns.Messages[i] = &cm
+	}
+	ns.CreatedAt, ns.UpdatedAt = now, now
+	return &ns
+}
+
+// turnStarts 返回每一轮对话（用户消息）在 msgs 中的起始下标
+func turnStarts(msgs []*schema.Message, from int) []int {
+	var starts []int
+	for i := from; i < len(msgs); i++ {
+		if msgs[i].Role == schema.User {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n]) + "..."
+}
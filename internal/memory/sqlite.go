@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLiteStore 基于 SQLite 的会话存储（需要 CGO）
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+var _ Store = (*SQLiteStore)(nil)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	id         TEXT PRIMARY KEY,
+	parent     TEXT NOT NULL DEFAULT '',
+	title      TEXT NOT NULL,
+	turns      INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL,
+	data       BLOB NOT NULL
+)`
+
+// OpenSQLite 打开（不存在时创建）path 处的数据库
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("初始化数据库失败: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Close 关闭数据库
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Save(ctx context.Context, sess *Session) error {
+	data, err := json.Marshal(sess)
+	if err != nil {
+		return fmt.Errorf("序列化会话失败: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+INSERT INTO sessions (id, parent, title, turns, updated_at, data) VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET title = excluded.title, turns = excluded.turns,
+	updated_at = excluded.updated_at, data = excluded.data`,
+		sess.ID, sess.Parent, sess.Title(), sess.Turns(), sess.UpdatedAt.UnixNano(), data)
+	if err != nil {
+		return fmt.Errorf("写入会话失败: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Load(ctx context.Context, id string) (*Session, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM sessions WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取会话失败: %w", err)
+	}
+	var sess Session
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, fmt.Errorf("解析会话 %s 失败: %w", id, err)
+	}
+	return &sess, nil
+}
+
+func (s *SQLiteStore) List(ctx context.Context) ([]*SessionInfo, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, parent, title, turns, updated_at FROM sessions ORDER BY updated_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("查询会话失败: %w", err)
+	}
+	defer rows.Close()
+
+	var infos []*SessionInfo
+	for rows.Next() {
+		var (
+			info    SessionInfo
+			updated int64
+		)
+		if err := rows.Scan(&info.ID, &info.Parent, &info.Title, &info.Turns, &updated); err != nil {
+			return nil, fmt.Errorf("查询会话失败: %w", err)
+		}
+		info.UpdatedAt = time.Unix(0, updated)
+		infos = append(infos, &info)
+	}
+	return infos, rows.Err()
+}
+
+func (s *SQLiteStore) Delete(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除会话失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return nil
+}
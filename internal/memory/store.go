@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound 会话不存在
+var ErrNotFound = errors.New("memory: 会话不存在")
+
+// Store 会话存储
+type Store interface {
+	Save(ctx context.Context, s *Session) error
+	Load(ctx context.Context, id string) (*Session, error)
+	// List 按更新时间倒序列出会话
+	List(ctx context.Context) ([]*SessionInfo, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// FileStore 每个会话一个 JSON 文件
+type FileStore struct {
+	dir string
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore 创建以 dir 为目录的文件存储
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建会话目录失败: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (f *FileStore) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("memory: 非法的会话 ID %q", id)
+	}
+	return filepath.Join(f.dir, id+".json"), nil
+}
+
+func (f *FileStore) Save(_ context.Context, s *Session) error {
+	p, err := f.path(s.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化会话失败: %w", err)
+	}
+	// 先写临时文件再重命名，避免写到一半退出导致文件损坏
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("写入会话失败: %w", err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return fmt.Errorf("写入会话失败: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) Load(_ context.Context, id string) (*Session, error) {
+	p, err := f.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取会话失败: %w", err)
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("解析会话 %s 失败: %w", id, err)
+	}
+	return &s, nil
+}
+
+func (f *FileStore) List(ctx context.Context) ([]*SessionInfo, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取会话目录失败: %w", err)
+	}
+	var infos []*SessionInfo
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok {
+			continue
+		}
+		s, err := f.Load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, s.Info())
+	}
+	sortInfos(infos)
+	return infos, nil
+}
+
+func (f *FileStore) Delete(_ context.Context, id string) error {
+	p, err := f.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return fmt.Errorf("删除会话失败: %w", err)
+	}
+	return nil
+}
+
+func sortInfos(infos []*SessionInfo) {
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].UpdatedAt.After(infos[j].UpdatedAt)
+	})
+}
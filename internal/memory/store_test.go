@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestStores(t *testing.T) {
+	t.Run("FileStore", func(t *testing.T) {
+		store, err := NewFileStore(filepath.Join(t.TempDir(), "sessions"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		testStore(t, store)
+
+		if err := store.Save(context.Background(), &Session{ID: "../escape"}); err == nil {
+			t.Error("包含路径分隔符的会话 ID 应返回错误")
+		}
+	})
+	t.Run("SQLiteStore", func(t *testing.T) {
+		store, err := OpenSQLite(filepath.Join(t.TempDir(), "sessions.db"))
+		if err != nil {
+			t.Skipf("SQLite 不可用: %v", err)
+		}
+		defer store.Close()
+		testStore(t, store)
+	})
+}
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	mem := New(store, nil)
+
+	old := conversation(1)
+	old.UpdatedAt = time.Now().Add(-time.Hour)
+	s := conversation(2)
+	s.Summary, s.Summarized = "摘要", 2
+	for _, sess := range []*Session{old, s} {
+		if err := mem.Save(ctx, sess); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := mem.Resume(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := contents(loaded.Messages); !equal(got, contents(s.Messages)) || loaded.Summary != "摘要" || loaded.Summarized != 2 || loaded.System != s.System {
+		t.Errorf("读回的会话与保存的不一致: %+v", loaded)
+	}
+
+	// Fork 出的会话与原会话互不影响
+	forked, err := mem.Fork(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forked.Messages[0].Content = "改过的问题"
+	forked.Append(schema.UserMessage("新问题"))
+	if err := mem.Save(ctx, forked); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, _ = mem.Resume(ctx, s.ID); loaded.Messages[0].Content != "问题1" || len(loaded.Messages) != 4 {
+		t.Errorf("修改 fork 不应影响原会话: %q", contents(loaded.Messages))
+	}
+
+	infos, err := mem.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 3 || infos[0].ID != forked.ID || infos[2].ID != old.ID {
+		t.Fatalf("List 应按更新时间倒序: %+v", infos)
+	}
+	if infos[0].Parent != s.ID || infos[0].Turns != 3 || infos[0].Title != "改过的问题" {
+		t.Errorf("fork 的列表项 = %+v", infos[0])
+	}
+
+	if err := mem.Delete(ctx, old.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mem.Resume(ctx, old.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("读取已删除的会话应返回 ErrNotFound，得到 %v", err)
+	}
+	if err := mem.Delete(ctx, old.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("重复删除应返回 ErrNotFound，得到 %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/tokens"
+)
+
+// Strategy 窗口策略：返回本次发送的历史在 s.Messages 中的起始下标。
+//
+// 起始下标不小于 s.Summarized，并且落在某一轮对话（用户消息）的开头；最新一轮总是会发送。
+type Strategy interface {
+	Window(ctx context.Context, s *Session) (int, error)
+}
+
+// StrategyFunc 函数形式的 Strategy
+type StrategyFunc func(ctx context.Context, s *Session) (int, error)
+
+func (f StrategyFunc) Window(ctx context.Context, s *Session) (int, error) {
+	return f(ctx, s)
+}
+
+// KeepAll 发送全部未摘要的历史
+func KeepAll() Strategy {
+	return StrategyFunc(func(_ context.Context, s *Session) (int, error) {
+		return s.Summarized, nil
+	})
+}
+
+// LastN 只发送最近 n 轮对话
+func LastN(n int) Strategy {
+	return StrategyFunc(func(_ context.Context, s *Session) (int, error) {
+		starts := turnStarts(s.Messages, s.Summarized)
+		if n <= 0 || len(starts) <= n {
+			return s.Summarized, nil
+		}
+		return starts[len(starts)-n], nil
+	})
+}
+
+// Estimator 估算消息的 token 数
+type Estimator func(msgs []*schema.Message) int
+
+// TokenBudget 从最新一轮往前，发送估算 token 数不超过 budget 的若干轮。est 为 nil 时使用 tokens.Messages
+func TokenBudget(budget int, est Estimator) Strategy {
+	if est == nil {
+		est = tokens.Messages
+	}
+	return StrategyFunc(func(_ context.Context, s *Session) (int, error) {
+		starts := turnStarts(s.Messages, s.Summarized)
+		if len(starts) == 0 {
+			return s.Summarized, nil
+		}
+		start, end, used := len(s.Messages), len(s.Messages), 0
+		for i := len(starts) - 1; i >= 0; i-- {
+			used += est(s.Messages[starts[i]:end])
+			if used > budget && start != len(s.Messages) {
+				break
+			}
+			start, end = starts[i], starts[i]
+		}
+		// 第一轮之前的消息（如开场的助手消息）只在全部放得下时发送
+		if start == starts[0] && est(s.Messages[s.Summarized:start])+used <= budget {
+			start = s.Summarized
+		}
+		return start, nil
+	})
+}
+
+// SummaryConfig 滚动摘要配置
+type SummaryConfig struct {
+	// Model 生成摘要的模型
+	Model model.BaseChatModel
+	// Keep 原样保留的近期历史，默认 LastN(6)
+	Keep Strategy
+	// Batch 窗口外积累了多少轮才生成一次摘要，默认 4；未达到时这些轮次照常发送
+	Batch int
+}
+
+// Summarize 窗口外的早期对话交给模型压缩成摘要，摘要会随会话一起保存
+func Summarize(cfg *SummaryConfig) Strategy {
+	keep, batch := cfg.Keep, cfg.Batch
+	if keep == nil {
+		keep = LastN(6)
+	}
+	if batch <= 0 {
+		batch = 4
+	}
+	return StrategyFunc(func(ctx context.Context, s *Session) (int, error) {
+		start, err := keep.Window(ctx, s)
+		if err != nil {
+			return 0, err
+		}
+		if len(turnStarts(s.Messages[:start], s.Summarized)) < batch {
+			return s.Summarized, nil
+		}
+
+		summary, err := summarize(ctx, cfg.Model, s.Summary, s.Messages[s.Summarized:start])
+		if err != nil {
+			return 0, fmt.Errorf("生成对话摘要失败: %w", err)
+		}
+		s.Summary, s.Summarized = summary, start
+		return start, nil
+	})
+}
+
+// Summarizer 返回用 cm 压缩对话的函数，可用作 budget.Config.Summarize
+func Summarizer(cm model.BaseChatModel) func(ctx context.Context, msgs []*schema.Message) (string, error) {
+	return func(ctx context.Context, msgs []*schema.Message) (string, error) {
+		return summarize(ctx, cm, "", msgs)
+	}
+}
+
+const summaryPrompt = `你负责压缩对话历史。请把已有摘要和新的对话合并成一份简洁的摘要，
+保留用户的身份信息、偏好、已确认的事实、未完成的问题和重要结论，省略寒暄和重复内容。只输出摘要本身。`
+
+func summarize(ctx context.Context, cm model.BaseChatModel, prev string, msgs []*schema.Message) (string, error) {
+	var sb strings.Builder
+	if prev != "" {
+		sb.WriteString("已有摘要：\n")
+		sb.WriteString(prev)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("新的对话：\n")
+	for _, m := range msgs {
+		switch m.Role {
+		case schema.User:
+			sb.WriteString("用户: ")
+		case schema.Assistant:
+			sb.WriteString("助手: ")
+		case schema.Tool:
+			sb.WriteString("工具: ")
+		default:
+			continue
+		}
+		sb.WriteString(m.Content)
+		sb.WriteString("\n")
+	}
+
+	resp, err := cm.Generate(ctx, []*schema.Message{
+		schema.SystemMessage(summaryPrompt),
+		schema.UserMessage(sb.String()),
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Content), nil
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// conversation 生成 n 轮 user / assistant 对话
+func conversation(n int) *Session {
+	s := NewSession("你是助手")
+	for i := 1; i <= n; i++ {
+		s.Append(schema.UserMessage(fmt.Sprintf("问题%d", i)), schema.AssistantMessage(fmt.Sprintf("回答%d", i), nil))
+	}
+	return s
+}
+
+// perMessage 每条消息按 10 个 token 计
+func perMessage(msgs []*schema.Message) int {
+	return 10 * len(msgs)
+}
+
+func TestWindowStrategies(t *testing.T) {
+	ctx := context.Background()
+	opening := conversation(2)
+	opening.Messages = append([]*schema.Message{schema.AssistantMessage("你好，有什么可以帮你？", nil)}, opening.Messages...)
+
+	cases := []struct {
+		name     string
+		strategy Strategy
+		session  *Session
+		want     int
+	}{
+		{"KeepAll", KeepAll(), conversation(3), 0},
+		{"LastN", LastN(2), conversation(3), 2},
+		{"LastN 不足 n 轮", LastN(5), conversation(3), 0},
+		{"LastN 非正数", LastN(0), conversation(3), 0},
+		{"TokenBudget", TokenBudget(40, perMessage), conversation(3), 2},
+		{"TokenBudget 放不下也保留最新一轮", TokenBudget(5, perMessage), conversation(3), 4},
+		{"TokenBudget 开场消息放得下", TokenBudget(50, perMessage), opening, 0},
+		{"TokenBudget 开场消息放不下", TokenBudget(40, perMessage), opening, 1},
+		{"TokenBudget 空会话", TokenBudget(40, perMessage), NewSession(""), 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.strategy.Window(ctx, c.session)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("Window = %d，期望 %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	s := conversation(3)
+	s.Summary = "用户问过问题1"
+	msgs, err := New(nil, LastN(1)).Messages(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"你是助手", "以下是之前对话的摘要：\n用户问过问题1", "问题3", "回答3"}
+	if got := contents(msgs); !equal(got, want) {
+		t.Errorf("Messages = %q，期望 %q", got, want)
+	}
+
+	// 窗口不能越过已并入摘要的消息
+	s.Summarized = 4
+	bad := StrategyFunc(func(context.Context, *Session) (int, error) { return 2, nil })
+	if _, err := New(nil, bad).Messages(context.Background(), s); err == nil {
+		t.Error("窗口起点小于 Summarized 时应返回错误")
+	}
+}
+
+// summaryModel 记录收到的摘要请求，返回固定编号的摘要
+type summaryModel struct {
+	requests []string
+	err      error
+}
+
+func (m *summaryModel) Generate(_ context.Context, msgs []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.requests = append(m.requests, msgs[len(msgs)-1].Content)
+	return schema.AssistantMessage(fmt.Sprintf(" 摘要%d\n", len(m.requests)), nil), nil
+}
+
+func (m *summaryModel) Stream(context.Context, []*schema.Message, ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, errors.New("不支持")
+}
+
+func TestSummarize(t *testing.T) {
+	ctx := context.Background()
+	cm := &summaryModel{}
+	mem := New(nil, Summarize(&SummaryConfig{Model: cm, Keep: LastN(2), Batch: 3}))
+
+	// 窗口外只有 2 轮，未达到 Batch，照常发送全部历史
+	s := conversation(4)
+	msgs, err := mem.Messages(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cm.requests) != 0 || len(msgs) != 9 || s.Summarized != 0 {
+		t.Fatalf("未达到 Batch 时不应生成摘要: 请求 %d 次，发送 %d 条", len(cm.requests), len(msgs))
+	}
+
+	// 第 5 轮后窗口外有 3 轮，压缩成摘要
+	s.Append(schema.UserMessage("问题5"), schema.AssistantMessage("回答5", nil))
+	msgs, err = mem.Messages(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Summary != "摘要1" || s.Summarized != 6 {
+		t.Fatalf("Summary = %q，Summarized = %d", s.Summary, s.Summarized)
+	}
+	if !strings.Contains(cm.requests[0], "用户: 问题3") || strings.Contains(cm.requests[0], "问题4") {
+		t.Errorf("摘要请求应包含前 3 轮且不含窗口内的对话:\n%s", cm.requests[0])
+	}
+	want := []string{"你是助手", "以下是之前对话的摘要：\n摘要1", "问题4", "回答4", "问题5", "回答5"}
+	if got := contents(msgs); !equal(got, want) {
+		t.Errorf("Messages = %q，期望 %q", got, want)
+	}
+
+	// 再积累 3 轮后，新摘要合并已有摘要
+	for i := 6; i <= 8; i++ {
+		s.Append(schema.UserMessage(fmt.Sprintf("问题%d", i)), schema.AssistantMessage(fmt.Sprintf("回答%d", i), nil))
+	}
+	if _, err := mem.Messages(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if len(cm.requests) != 2 || !strings.Contains(cm.requests[1], "已有摘要：\n摘要1") || strings.Contains(cm.requests[1], "问题3") {
+		t.Errorf("第二次摘要请求应基于已有摘要:\n%s", cm.requests[len(cm.requests)-1])
+	}
+	if s.Summary != "摘要2" || s.Summarized != 12 {
+		t.Errorf("Summary = %q，Summarized = %d", s.Summary, s.Summarized)
+	}
+
+	// 摘要失败时返回错误，会话不变
+	failing := New(nil, Summarize(&SummaryConfig{Model: &summaryModel{err: errors.New("超时")}, Keep: LastN(1), Batch: 1}))
+	s = conversation(2)
+	if _, err := failing.Messages(ctx, s); err == nil || s.Summarized != 0 {
+		t.Errorf("摘要失败时应返回错误且不修改会话: err = %v，Summarized = %d", err, s.Summarized)
+	}
+}
+
+func contents(msgs []*schema.Message) []string {
+	out := make([]string, len(msgs))
+	for i, m := range msgs {
+		out[i] = m.Content
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
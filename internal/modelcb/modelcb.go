@@ -0,0 +1,127 @@
+// Package modelcb 为 ChatModel 包装器补发 eino 回调。
+//
+// 包装器（回放、缓存等）在没有真正调用底层模型时，需要自己触发 OnStart / OnEnd，
+// 这样挂在 Chain、ReAct Agent、ADK Runner 上的回调处理器仍然能看到这次调用。
+package modelcb
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Input 根据调用参数构造 model.CallbackInput
+func Input(in []*schema.Message, opts ...model.Option) *model.CallbackInput {
+	o := model.GetCommonOptions(nil, opts...)
+	cfg := &model.Config{Stop: o.Stop}
+	if o.Model != nil {
+		cfg.Model = *o.Model
+	}
+	if o.MaxTokens != nil {
+		cfg.MaxTokens = *o.MaxTokens
+	}
+	if o.Temperature != nil {
+		cfg.Temperature = *o.Temperature
+	}
+	if o.TopP != nil {
+		cfg.TopP = *o.TopP
+	}
+	return &model.CallbackInput{
+		Messages:   in,
+		Tools:      o.Tools,
+		ToolChoice: o.ToolChoice,
+		Config:     cfg,
+	}
+}
+
+// Output 根据模型输出构造 model.CallbackOutput
+func Output(msg *schema.Message, cfg *model.Config) *model.CallbackOutput {
+	return &model.CallbackOutput{
+		Message:    msg,
+		Config:     cfg,
+		TokenUsage: Usage(msg),
+	}
+}
+
+// Usage 将消息中的 schema.TokenUsage 转换为回调使用的 model.TokenUsage
+func Usage(msg *schema.Message) *model.TokenUsage {
+	if msg == nil || msg.ResponseMeta == nil || msg.ResponseMeta.Usage == nil {
+		return nil
+	}
+	u := msg.ResponseMeta.Usage
+	return &model.TokenUsage{
+		PromptTokens: u.PromptTokens,
+		PromptTokenDetails: model.PromptTokenDetails{
+			CachedTokens: u.PromptTokenDetails.CachedTokens,
+		},
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+		CompletionTokensDetails: model.CompletionTokensDetails{
+			ReasoningTokens: u.CompletionTokensDetails.ReasoningTokens,
+		},
+	}
+}
+
+// OutputFunc 根据模型输出构造回调输出
+type OutputFunc func(msg *schema.Message, cfg *model.Config) *model.CallbackOutput
+
+// Generate 以 typ 类型的 ChatModel 身份执行 fn，并在前后触发回调
+func Generate(ctx context.Context, typ string, in []*schema.Message, opts []model.Option,
+	fn func(ctx context.Context) (*schema.Message, error)) (*schema.Message, error) {
+	return GenerateWithOutput(ctx, typ, in, opts, Output, fn)
+}
+
+// GenerateWithOutput 与 Generate 相同，回调输出由 out 构造
+func GenerateWithOutput(ctx context.Context, typ string, in []*schema.Message, opts []model.Option, out OutputFunc,
+	fn func(ctx context.Context) (*schema.Message, error)) (*schema.Message, error) {
+
+	ctx = callbacks.EnsureRunInfo(ctx, typ, components.ComponentOfChatModel)
+	cbInput := Input(in, opts...)
+	ctx = callbacks.OnStart(ctx, cbInput)
+
+	msg, err := fn(ctx)
+	if err != nil {
+		callbacks.OnError(ctx, err)
+		return nil, err
+	}
+
+	callbacks.OnEnd(ctx, out(msg, cbInput.Config))
+	return msg, nil
+}
+
+// Stream 以 typ 类型的 ChatModel 身份执行 fn，并把输出流交给 OnEndWithStreamOutput
+func Stream(ctx context.Context, typ string, in []*schema.Message, opts []model.Option,
+	fn func(ctx context.Context) (*schema.StreamReader[*schema.Message], error)) (*schema.StreamReader[*schema.Message], error) {
+	return StreamWithOutput(ctx, typ, in, opts, Output, fn)
+}
+
+// StreamWithOutput 与 Stream 相同，每个分片的回调输出由 out 构造
+func StreamWithOutput(ctx context.Context, typ string, in []*schema.Message, opts []model.Option, out OutputFunc,
+	fn func(ctx context.Context) (*schema.StreamReader[*schema.Message], error)) (*schema.StreamReader[*schema.Message], error) {
+
+	ctx = callbacks.EnsureRunInfo(ctx, typ, components.ComponentOfChatModel)
+	cbInput := Input(in, opts...)
+	ctx = callbacks.OnStart(ctx, cbInput)
+
+	sr, err := fn(ctx)
+	if err != nil {
+		callbacks.OnError(ctx, err)
+		return nil, err
+	}
+
+	_, nsr := callbacks.OnEndWithStreamOutput(ctx, schema.StreamReaderWithConvert(sr,
+		func(msg *schema.Message) (callbacks.CallbackOutput, error) {
+			return out(msg, cbInput.Config), nil
+		}))
+
+	return schema.StreamReaderWithConvert(nsr, func(out callbacks.CallbackOutput) (*schema.Message, error) {
+		o := out.(*model.CallbackOutput)
+		if o.Message == nil {
+			return nil, schema.ErrNoValue
+		}
+		return o.Message, nil
+	}), nil
+}
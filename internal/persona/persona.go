@@ -0,0 +1,166 @@
+package persona
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+)
+
+// DefaultRole 未指定角色时使用
+const DefaultRole = "AI 助手"
+
+// Persona 一个角色加上各维度选用的特征
+type Persona struct {
+	// Role 角色，如 "医疗健康专家"，按原文输出，不引用变量；默认 DefaultRole
+	Role string `yaml:"role,omitempty"`
+	// Traits 维度 -> 特征名称
+	Traits map[Dimension]string `yaml:"traits,omitempty"`
+	// Vars 特征文本中的变量，覆盖特征的默认值
+	Vars map[string]any `yaml:"vars,omitempty"`
+	// Extra 附加在最后的要求，按原文输出，可以包含 JSON 等带花括号的内容
+	Extra []string `yaml:"extra,omitempty"`
+}
+
+// With 返回设置了 dim 维度特征的副本，用于在一个基础人设上派生
+func (p *Persona) With(dim Dimension, name string) *Persona {
+	c := *p
+	c.Traits = make(map[Dimension]string, len(p.Traits)+1)
+	for k, v := range p.Traits {
+		c.Traits[k] = v
+	}
+	c.Traits[dim] = name
+	return &c
+}
+
+// Render 把人设渲染成系统提示词。特征文本按 FString 格式化，vars 覆盖 Persona.Vars 和特征的默认值，可以为 nil；
+// Role 和 Extra 按原文输出
+func (r *Registry) Render(ctx context.Context, p *Persona, vars map[string]any) (string, error) {
+	dims := make([]Dimension, 0, len(p.Traits))
+	for dim := range p.Traits {
+		dims = append(dims, dim)
+	}
+	sortDimensions(dims)
+
+	traits := make([]*Trait, 0, len(dims))
+	values := map[string]any{}
+	for _, dim := range dims {
+		t, err := r.Lookup(dim, p.Traits[dim])
+		if err != nil {
+			return "", err
+		}
+		traits = append(traits, t)
+		for k, v := range t.Defaults {
+			values[k] = v
+		}
+	}
+	for k, v := range p.Vars {
+		values[k] = v
+	}
+	for k, v := range vars {
+		values[k] = v
+	}
+
+	var rules []string
+	for _, t := range traits {
+		if t.Text == "" {
+			continue
+		}
+		msgs, err := schema.SystemMessage(t.Text).Format(ctx, values, schema.FString)
+		if err != nil {
+			return "", fmt.Errorf("persona: 渲染特征 %s/%s 失败: %w", t.Dimension, t.Name, err)
+		}
+		rules = append(rules, msgs[0].Content)
+	}
+	rules = append(rules, p.Extra...)
+
+	role := p.Role
+	if role == "" {
+		role = DefaultRole
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "你是一个%s。", role)
+	if len(rules) > 0 {
+		sb.WriteString("\n要求：")
+		for i, rule := range rules {
+			fmt.Fprintf(&sb, "\n%d. %s", i+1, rule)
+		}
+	}
+	return sb.String(), nil
+}
+
+// Instruction 渲染成 adk.ChatModelAgentConfig.Instruction。
+// 只有在没有会话变量时结果才能原样使用：Agent 有会话变量时会把 Instruction 当作 FString 格式化，此时使用 InstructionForSession
+func (r *Registry) Instruction(ctx context.Context, p *Persona, vars map[string]any) (string, error) {
+	return r.Render(ctx, p, vars)
+}
+
+// InstructionForSession 与 Instruction 相同，但转义了花括号，
+// 用于带会话变量（adk.AddSessionValues）运行的 Agent，格式化后得到与 Render 相同的文本
+func (r *Registry) InstructionForSession(ctx context.Context, p *Persona, vars map[string]any) (string, error) {
+	text, err := r.Render(ctx, p, vars)
+	if err != nil {
+		return "", err
+	}
+	return strings.NewReplacer("{", "{{", "}", "}}").Replace(text), nil
+}
+
+// Template 返回一个 ChatTemplate：Format 时用同一组变量渲染人设和 inner，
+// 人设放在 inner 的第一条系统消息之前并合并成一条；inner 没有系统消息时单独作为第一条
+func (r *Registry) Template(inner prompt.ChatTemplate, p *Persona) prompt.ChatTemplate {
+	return &personaTemplate{inner: inner, r: r, p: p}
+}
+
+type personaTemplate struct {
+	inner prompt.ChatTemplate
+	r     *Registry
+	p     *Persona
+}
+
+func (t *personaTemplate) Format(ctx context.Context, vs map[string]any, opts ...prompt.Option) ([]*schema.Message, error) {
+	system, err := t.r.Render(ctx, t.p, vs)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := t.inner.Format(ctx, vs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) > 0 && msgs[0].Role == schema.System {
+		first := *msgs[0]
+		first.Content = system + "\n\n" + first.Content
+		return append([]*schema.Message{&first}, msgs[1:]...), nil
+	}
+	return append([]*schema.Message{schema.SystemMessage(system)}, msgs...), nil
+}
+
+func (t *personaTemplate) GetType() string {
+	return "Persona"
+}
+
+// IsCallbacksEnabled 回调由内部的 ChatTemplate 触发
+func (t *personaTemplate) IsCallbacksEnabled() bool {
+	return true
+}
+
+// Render 用 Default 渲染人设
+func Render(ctx context.Context, p *Persona, vars map[string]any) (string, error) {
+	return Default.Render(ctx, p, vars)
+}
+
+// Instruction 用 Default 渲染 Agent 的 Instruction
+func Instruction(ctx context.Context, p *Persona, vars map[string]any) (string, error) {
+	return Default.Instruction(ctx, p, vars)
+}
+
+// InstructionForSession 用 Default 渲染带会话变量的 Agent 的 Instruction
+func InstructionForSession(ctx context.Context, p *Persona, vars map[string]any) (string, error) {
+	return Default.InstructionForSession(ctx, p, vars)
+}
+
+// Template 用 Default 把人设加到 inner 前面
+func Template(inner prompt.ChatTemplate, p *Persona) prompt.ChatTemplate {
+	return Default.Template(inner, p)
+}
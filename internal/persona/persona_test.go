@@ -0,0 +1,46 @@
+package persona
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestRenderLiteralRoleAndExtra(t *testing.T) {
+	ctx := context.Background()
+	p := &Persona{
+		Role:   "{情感}分析专家",
+		Traits: map[Dimension]string{Domain: "medical"},
+		Vars:   map[string]any{"domain": "医疗健康"},
+		Extra:  []string{`按 JSON 输出：{"sentiment": "positive"}`},
+	}
+	got, err := Render(ctx, p, nil)
+	if err != nil {
+		t.Fatalf("Render 返回错误: %v", err)
+	}
+	for _, want := range []string{"你是一个{情感}分析专家。", "医疗健康方面", `{"sentiment": "positive"}`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render 结果缺少 %q:\n%s", want, got)
+		}
+	}
+
+	instruction, err := Instruction(ctx, p, nil)
+	if err != nil || instruction != got {
+		t.Errorf("Instruction = %q, %v，期望与 Render 相同", instruction, err)
+	}
+
+	// 带会话变量的 Agent 会把 Instruction 当作 FString 格式化，结果应与 Render 相同
+	escaped, err := InstructionForSession(ctx, p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := schema.SystemMessage(escaped).Format(ctx, map[string]any{"user": "张三"}, schema.FString)
+	if err != nil {
+		t.Fatalf("格式化 InstructionForSession 失败: %v", err)
+	}
+	if msgs[0].Content != got {
+		t.Errorf("格式化后 = %q，期望 %q", msgs[0].Content, got)
+	}
+}
@@ -0,0 +1,232 @@
+// Package persona 用可组合的特征拼出系统提示词。
+//
+// 人设由一个角色和若干维度上的特征组成：风格、领域、受众、输出语言、篇幅和格式互相独立，
+// 每个维度选一个已登记的特征，渲染成一条系统消息：
+//
+//	p := &persona.Persona{
+//		Role:   "医疗健康专家",
+//		Traits: map[persona.Dimension]string{persona.Style: "friendly", persona.Audience: "beginner"},
+//	}
+//	system, err := persona.Render(ctx, p, nil)
+//
+// 特征可以在运行时用 Register 登记，或用 LoadFile 从 YAML 文件加载，也可以登记新的维度。
+// Template 把人设加到任意 ChatTemplate 前面，Instruction 生成 ChatModelAgent 使用的 Instruction
+// （Agent 带会话变量时用 InstructionForSession）。
+package persona
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dimension 特征所属的维度
+type Dimension string
+
+const (
+	Domain   Dimension = "domain"
+	Audience Dimension = "audience"
+	Style    Dimension = "style"
+	Language Dimension = "language"
+	Length   Dimension = "length"
+	Format   Dimension = "format"
+)
+
+// Dimensions 内置维度，渲染时按此顺序排列，其它维度按名称排在后面
+var Dimensions = []Dimension{Domain, Audience, Style, Language, Length, Format}
+
+// Trait 一个维度上的特征
+type Trait struct {
+	Dimension   Dimension `yaml:"dimension"`
+	Name        string    `yaml:"name"`
+	Description string    `yaml:"description,omitempty"`
+	// Text 渲染到系统提示词中的要求，FString 格式，可以引用变量，如 {domain}；为空时不输出
+	Text string `yaml:"text"`
+	// Defaults 变量的默认值
+	Defaults map[string]any `yaml:"defaults,omitempty"`
+}
+
+// Registry 按维度登记的特征，可以并发使用
+type Registry struct {
+	mu     sync.RWMutex
+	traits map[Dimension]map[string]*Trait
+}
+
+// NewRegistry 创建空的 Registry
+func NewRegistry() *Registry {
+	return &Registry{traits: map[Dimension]map[string]*Trait{}}
+}
+
+// Register 登记特征，同一维度下同名时覆盖
+func (r *Registry) Register(traits ...*Trait) error {
+	for _, t := range traits {
+		if t == nil || t.Dimension == "" || t.Name == "" {
+			return errors.New("persona: 特征缺少维度或名称")
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range traits {
+		if r.traits[t.Dimension] == nil {
+			r.traits[t.Dimension] = map[string]*Trait{}
+		}
+		r.traits[t.Dimension][t.Name] = t
+	}
+	return nil
+}
+
+// Lookup 查找特征
+func (r *Registry) Lookup(dim Dimension, name string) (*Trait, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	byName, ok := r.traits[dim]
+	if !ok {
+		return nil, fmt.Errorf("persona: 未知的维度 %q", dim)
+	}
+	t, ok := byName[name]
+	if !ok {
+		return nil, fmt.Errorf("persona: 维度 %s 没有特征 %q，可用: %s", dim, name, strings.Join(sortedKeys(byName), ", "))
+	}
+	return t, nil
+}
+
+// Traits 返回一个维度下的所有特征（按名称排序）
+func (r *Registry) Traits(dim Dimension) []*Trait {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	byName := r.traits[dim]
+	out := make([]*Trait, 0, len(byName))
+	for _, name := range sortedKeys(byName) {
+		out = append(out, byName[name])
+	}
+	return out
+}
+
+// Dimensions 返回已登记的维度，内置维度在前
+func (r *Registry) Dimensions() []Dimension {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	dims := make([]Dimension, 0, len(r.traits))
+	for dim := range r.traits {
+		dims = append(dims, dim)
+	}
+	sortDimensions(dims)
+	return dims
+}
+
+// LoadFile 从 YAML 文件加载特征，文件内容为特征列表
+func (r *Registry) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("persona: %w", err)
+	}
+	var traits []*Trait
+	if err := yaml.Unmarshal(data, &traits); err != nil {
+		return fmt.Errorf("persona: 解析 %s 失败: %w", path, err)
+	}
+	if err := r.Register(traits...); err != nil {
+		return fmt.Errorf("persona: %s: %w", path, err)
+	}
+	return nil
+}
+
+// sortDimensions 内置维度按 Dimensions 的顺序在前，其它按名称排序
+func sortDimensions(dims []Dimension) {
+	rank := func(d Dimension) int {
+		if i := slices.Index(Dimensions, d); i >= 0 {
+			return i
+		}
+		return len(Dimensions)
+	}
+	sort.Slice(dims, func(i, j int) bool {
+		ri, rj := rank(dims[i]), rank(dims[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return dims[i] < dims[j]
+	})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Default 进程内共享的 Registry，登记了内置特征
+var Default = NewRegistry()
+
+// Register 向 Default 登记特征
+func Register(traits ...*Trait) error {
+	return Default.Register(traits...)
+}
+
+// LoadFile 向 Default 加载 YAML 文件中的特征
+func LoadFile(path string) error {
+	return Default.LoadFile(path)
+}
+
+func init() {
+	_ = Default.Register(
+		&Trait{Dimension: Domain, Name: "general", Description: "通用",
+			Text: "回答各类常见问题，不确定的内容要说明。"},
+		&Trait{Dimension: Domain, Name: "expert", Description: "指定领域的专家",
+			Text: "只回答{domain}领域的问题，内容要符合该领域的专业共识；超出该领域的问题请说明。", Defaults: map[string]any{"domain": "软件开发"}},
+		&Trait{Dimension: Domain, Name: "medical", Description: "医疗健康",
+			Text: "提供医疗健康方面的科普信息；涉及诊断、用药和治疗方案时提醒用户咨询医生。"},
+		&Trait{Dimension: Domain, Name: "software", Description: "软件开发",
+			Text: "回答软件开发问题，给出的代码要能直接运行，并说明适用的版本和前提。"},
+
+		&Trait{Dimension: Audience, Name: "beginner", Description: "初学者",
+			Text: "用户是初学者：少用术语，必要的术语先解释，多举生活中的例子。"},
+		&Trait{Dimension: Audience, Name: "professional", Description: "专业人士",
+			Text: "用户是专业人士：可以直接使用术语，省略基础概念的解释。"},
+		&Trait{Dimension: Audience, Name: "child", Description: "儿童",
+			Text: "用户是小学生：用简单的词语和短句，避免可怕或复杂的内容。"},
+
+		&Trait{Dimension: Style, Name: "professional", Description: "专业高效",
+			Text: "语气专业、高效，提供准确且简洁的信息。"},
+		&Trait{Dimension: Style, Name: "casual", Description: "随和轻松",
+			Text: "语气随和、易于接近，像朋友聊天一样轻松地交流。"},
+		&Trait{Dimension: Style, Name: "friendly", Description: "友好温暖",
+			Text: "语气友好、乐于助人，多用温暖和鼓励的话。"},
+		&Trait{Dimension: Style, Name: "formal", Description: "正式礼貌",
+			Text: "语言正式、尊重礼仪，用词专业且恰当。"},
+
+		&Trait{Dimension: Language, Name: "zh", Description: "简体中文",
+			Text: "使用简体中文回答。"},
+		&Trait{Dimension: Language, Name: "en", Description: "英文",
+			Text: "Answer in English."},
+		&Trait{Dimension: Language, Name: "match", Description: "与提问相同",
+			Text: "使用与用户提问相同的语言回答。"},
+		&Trait{Dimension: Language, Name: "custom", Description: "指定语言",
+			Text: "使用{language}回答。", Defaults: map[string]any{"language": "简体中文"}},
+
+		&Trait{Dimension: Length, Name: "brief", Description: "简短",
+			Text: "回答简短，不超过 3 句话。"},
+		&Trait{Dimension: Length, Name: "normal", Description: "适中",
+			Text: "先给出结论，再简要说明理由。"},
+		&Trait{Dimension: Length, Name: "detailed", Description: "详细",
+			Text: "回答详细，覆盖原因、步骤和注意事项。"},
+		&Trait{Dimension: Length, Name: "limit", Description: "限制字数",
+			Text: "回答不超过 {max_words} 字。", Defaults: map[string]any{"max_words": 200}},
+
+		&Trait{Dimension: Format, Name: "plain", Description: "纯文本",
+			Text: "使用纯文本，不要使用 Markdown。"},
+		&Trait{Dimension: Format, Name: "markdown", Description: "Markdown",
+			Text: "使用 Markdown 组织回答：小标题、列表，代码放在代码块中。"},
+		&Trait{Dimension: Format, Name: "bullets", Description: "要点列表",
+			Text: "用要点列表回答，每条一句话。"},
+		&Trait{Dimension: Format, Name: "json", Description: "JSON",
+			Text: "只输出 JSON，不要输出其它内容。"},
+	)
+}
@@ -0,0 +1,359 @@
+// Package promptcheck 在渲染之前静态检查 prompt.FromMessages 模板。
+//
+// Analyze 从消息模板中提取需要的变量（支持 FString、GoTemplate 和 Jinja2），并找出语法错误和可疑的写法：
+// 原样发给模型的字面量 \n、遗留的 fmt 格式化动词、FString 中被 {{ }} 转义的变量等。
+// Check 把提取结果与调用方准备的变量（map 或结构体）对比，报告缺少和多余的键。
+//
+// 创建模板时用 FromMessages 代替 prompt.FromMessages 即可在构造时检查；测试中可以用 promptchecktest.AssertTemplate。
+package promptcheck
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/nikolalohinski/gonja"
+	"github.com/nikolalohinski/gonja/tokens"
+
+	"eino-tutorial/internal/promptrender"
+)
+
+// Issue 模板中的一个问题
+type Issue struct {
+	// Message 消息在模板中的下标，从 0 开始；与具体消息无关时为 -1
+	Message int
+	Text    string
+}
+
+func (i Issue) String() string {
+	if i.Message < 0 {
+		return i.Text
+	}
+	return fmt.Sprintf("第 %d 条消息: %s", i.Message+1, i.Text)
+}
+
+// Analysis 模板的静态分析结果
+type Analysis struct {
+	Format schema.FormatType
+	// Variables 模板用到的变量（已排序），包括必填的消息占位符
+	Variables []string
+	// Optional 可选的消息占位符，不提供也不会报错
+	Optional []string
+	Issues   []Issue
+}
+
+// Analyze 分析消息模板，不渲染任何内容
+func Analyze(format schema.FormatType, templates ...schema.MessagesTemplate) *Analysis {
+	a := &Analysis{Format: format}
+	used := map[string]bool{}
+	optional := map[string]bool{}
+	for i, t := range templates {
+		if key, opt, ok := placeholder(t); ok {
+			if opt {
+				optional[key] = true
+			} else {
+				used[key] = true
+			}
+			continue
+		}
+		msg, ok := t.(*schema.Message)
+		if !ok {
+			a.Issues = append(a.Issues, Issue{i, fmt.Sprintf("无法分析的模板类型 %T", t)})
+			continue
+		}
+		for _, content := range contents(msg) {
+			names, err := variables(format, content)
+			if err != nil {
+				a.Issues = append(a.Issues, Issue{i, "语法错误: " + err.Error()})
+			}
+			for _, name := range names {
+				used[name] = true
+			}
+			for _, text := range suspicious(format, content) {
+				a.Issues = append(a.Issues, Issue{i, text})
+			}
+		}
+	}
+	for name := range optional {
+		if used[name] {
+			delete(optional, name)
+		}
+	}
+	a.Variables = sortedKeys(used)
+	a.Optional = sortedKeys(optional)
+	return a
+}
+
+// placeholder 取出 schema.MessagesPlaceholder 的键，eino 没有导出这些字段，只能通过反射读取
+func placeholder(t schema.MessagesTemplate) (key string, optional, ok bool) {
+	v := reflect.ValueOf(t)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || v.Type().Name() != "messagesPlaceholder" {
+		return "", false, false
+	}
+	k, o := v.FieldByName("key"), v.FieldByName("optional")
+	if k.Kind() != reflect.String || o.Kind() != reflect.Bool {
+		return "", false, false
+	}
+	return k.String(), o.Bool(), true
+}
+
+// contents 消息中会被渲染的文本
+func contents(msg *schema.Message) []string {
+	out := []string{msg.Content}
+	for _, part := range msg.UserInputMultiContent {
+		if part.Type == schema.ChatMessagePartTypeText {
+			out = append(out, part.Text)
+		}
+	}
+	return out
+}
+
+func variables(format schema.FormatType, content string) ([]string, error) {
+	switch format {
+	case schema.FString:
+		return fstringVariables(content)
+	case schema.GoTemplate:
+		return goTemplateVariables(content)
+	case schema.Jinja2:
+		return jinjaVariables(content)
+	default:
+		return nil, fmt.Errorf("未知的模板格式 %v", format)
+	}
+}
+
+// fstringVariables 按 pyfmt 的规则提取 {name}、{name.field}、{name:spec} 中的 name，{{ 和 }} 是转义
+func fstringVariables(s string) ([]string, error) {
+	var names []string
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			if strings.HasPrefix(s[i:], "{{") {
+				i++
+				continue
+			}
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return names, fmt.Errorf("位置 %d 的 { 没有匹配的 }", i)
+			}
+			field := s[i+1 : i+end]
+			name := field[:strings.IndexAny(field+".", ".[:!")]
+			if name == "" {
+				return names, fmt.Errorf("位置 %d 的 {%s} 缺少变量名", i, field)
+			}
+			names = append(names, name)
+			i += end
+		case '}':
+			if !strings.HasPrefix(s[i:], "}}") {
+				return names, fmt.Errorf("位置 %d 的 } 没有匹配的 {，字面量需要写成 }}", i)
+			}
+			i++
+		}
+	}
+	return names, nil
+}
+
+// goTemplateVariables 提取根作用域中的 .name 和 $.name；range / with 内部的 . 已经不是变量表
+func goTemplateVariables(s string) ([]string, error) {
+	t, err := template.New("").Funcs(promptrender.Funcs()).Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if t.Tree == nil {
+		return nil, nil
+	}
+	var names []string
+	var walk func(n parse.Node, root bool)
+	walk = func(n parse.Node, root bool) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c, root)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe, root)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Cmds {
+				walk(c, root)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg, root)
+			}
+		case *parse.ChainNode:
+			walk(n.Node, root)
+		case *parse.FieldNode:
+			if root {
+				names = append(names, n.Ident[0])
+			}
+		case *parse.VariableNode:
+			if n.Ident[0] == "$" && len(n.Ident) > 1 {
+				names = append(names, n.Ident[1])
+			}
+		case *parse.IfNode:
+			walk(n.Pipe, root)
+			walk(n.List, root)
+			walk(n.ElseList, root)
+		case *parse.RangeNode:
+			walk(n.Pipe, root)
+			walk(n.List, false)
+			walk(n.ElseList, root)
+		case *parse.WithNode:
+			walk(n.Pipe, root)
+			walk(n.List, false)
+			walk(n.ElseList, root)
+		}
+	}
+	walk(t.Tree.Root, true)
+	return names, nil
+}
+
+// jinjaKeywords 语句关键字、运算符和内置函数，不是变量
+var jinjaKeywords = map[string]bool{
+	"if": true, "elif": true, "else": true, "endif": true, "for": true, "endfor": true, "in": true,
+	"is": true, "not": true, "and": true, "or": true, "set": true, "endset": true, "with": true,
+	"endwith": true, "macro": true, "endmacro": true, "call": true, "endcall": true, "filter": true,
+	"endfilter": true, "block": true, "endblock": true, "raw": true, "endraw": true, "recursive": true,
+	"true": true, "false": true, "none": true, "True": true, "False": true, "None": true, "loop": true,
+	"range": true, "dict": true, "lipsum": true, "cycler": true, "joiner": true, "namespace": true,
+}
+
+// jinjaVariables 按词法分析近似提取变量：跳过属性、过滤器、测试、关键字以及 for / set / with / macro 定义的局部变量
+func jinjaVariables(s string) ([]string, error) {
+	if err := promptrender.CheckJinja(s); err != nil {
+		return nil, err
+	}
+	if _, err := gonja.FromString(s); err != nil {
+		return nil, err
+	}
+
+	var toks []*tokens.Token
+	stream := tokens.Lex(s)
+	for !stream.End() {
+		t := stream.Next()
+		if t.Type != tokens.Whitespace {
+			toks = append(toks, t)
+		}
+	}
+
+	local := map[string]bool{}
+	var names []string
+	var stmt string // 当前 {% %} 的语句名
+	for i, t := range toks {
+		switch t.Type {
+		case tokens.BlockBegin:
+			stmt = ""
+			if i+1 < len(toks) && toks[i+1].Type == tokens.Name {
+				stmt = toks[i+1].Val
+			}
+			continue
+		case tokens.BlockEnd, tokens.VariableBegin:
+			stmt = ""
+			continue
+		case tokens.Name:
+		default:
+			continue
+		}
+
+		var prev, next *tokens.Token
+		if i > 0 {
+			prev = toks[i-1]
+		}
+		if i+1 < len(toks) {
+			next = toks[i+1]
+		}
+		switch {
+		case jinjaKeywords[t.Val], local[t.Val]:
+			continue
+		case prev != nil && (prev.Type == tokens.Dot || prev.Type == tokens.Pipe || prev.Val == "is"):
+			continue
+		case prev != nil && prev.Val == "not" && i > 1 && toks[i-2].Val == "is":
+			// is not 之后是测试名
+			continue
+		case next != nil && next.Type == tokens.Assign && stmt != "set" && stmt != "with":
+			// 调用中的关键字参数
+			continue
+		case defines(stmt, toks[i:]):
+			local[t.Val] = true
+			continue
+		}
+		names = append(names, t.Val)
+	}
+	return names, nil
+}
+
+// defines 语句中位于 rest[0] 的名称是否是新定义的局部变量
+func defines(stmt string, rest []*tokens.Token) bool {
+	switch stmt {
+	case "for":
+		// for 与 in 之间的名称是循环变量
+		for _, t := range rest {
+			if t.Val == "in" {
+				return true
+			}
+			if t.Type == tokens.BlockEnd {
+				return false
+			}
+		}
+	case "set", "with":
+		return len(rest) > 1 && rest[1].Type == tokens.Assign
+	case "macro":
+		return true
+	}
+	return false
+}
+
+var (
+	// escapedNewline 源码中多写了一个反斜杠，模型收到的是字面量 \n
+	escapedNewline = regexp.MustCompile(`\\[ntr]`)
+	fmtVerb        = regexp.MustCompile(`%[-+#0]*[0-9]*(\.[0-9]+)?[sdvqfxXtT]`)
+	// escapedField FString 中的 {{name}} 渲染结果是 {name}
+	escapedField = regexp.MustCompile(`\{\{\s*[A-Za-z_][A-Za-z0-9_]*\s*\}\}`)
+	// fstringField GoTemplate / Jinja2 模板中出现的 {name}
+	fstringField = regexp.MustCompile(`(?:^|[^{])(\{[A-Za-z_][A-Za-z0-9_]*\})(?:$|[^}])`)
+)
+
+// suspicious 找出可疑的转义和写法
+func suspicious(format schema.FormatType, content string) []string {
+	var out []string
+	if m := escapedNewline.FindString(content); m != "" {
+		out = append(out, fmt.Sprintf("包含字面量 %s，模型会原样收到反斜杠，换行应写成真正的换行符", m))
+	}
+	// Jinja2 中 % 是语句和取模运算符
+	if m := fmtVerb.FindString(content); m != "" && format != schema.Jinja2 {
+		out = append(out, fmt.Sprintf("包含 %s，疑似没有替换的 fmt 格式化动词", m))
+	}
+	switch format {
+	case schema.FString:
+		if m := escapedField.FindString(content); m != "" {
+			out = append(out, fmt.Sprintf("%s 在 FString 中是转义，会原样输出而不是替换变量", m))
+		}
+	case schema.GoTemplate, schema.Jinja2:
+		if m := fstringField.FindStringSubmatch(content); m != nil {
+			out = append(out, fmt.Sprintf("%s 是 FString 语法，在当前格式中不会被替换", m[1]))
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
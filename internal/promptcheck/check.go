@@ -0,0 +1,123 @@
+package promptcheck
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/promptrender"
+)
+
+// Report 模板与变量的对比结果
+type Report struct {
+	// Missing 模板需要但没有提供的变量
+	Missing []string
+	// Unused 提供了但模板没有用到的变量
+	Unused []string
+	Issues []Issue
+}
+
+// OK 没有发现任何问题
+func (r *Report) OK() bool {
+	return len(r.Missing) == 0 && len(r.Unused) == 0 && len(r.Issues) == 0
+}
+
+// Err 有问题时返回列出全部问题的错误
+func (r *Report) Err() error {
+	if r.OK() {
+		return nil
+	}
+	var parts []string
+	if len(r.Missing) > 0 {
+		parts = append(parts, "缺少变量 "+strings.Join(r.Missing, ", "))
+	}
+	if len(r.Unused) > 0 {
+		parts = append(parts, "未使用的变量 "+strings.Join(r.Unused, ", "))
+	}
+	for _, issue := range r.Issues {
+		parts = append(parts, issue.String())
+	}
+	return fmt.Errorf("promptcheck: %s", strings.Join(parts, "; "))
+}
+
+// Check 对比模板用到的变量和 vars 提供的键。vars 可以是：
+//
+//	nil                   只报告模板本身的问题
+//	[]string              变量名列表
+//	map[string]T          使用 map 的键
+//	结构体或结构体指针     字段名与 promptrender.Vars 相同：prompt 标签、json 标签或字段名
+func (a *Analysis) Check(vars any) *Report {
+	r := &Report{Issues: a.Issues}
+	if vars == nil {
+		return r
+	}
+	keys, err := Keys(vars)
+	if err != nil {
+		r.Issues = append(r.Issues, Issue{Message: -1, Text: err.Error()})
+		return r
+	}
+
+	provided := map[string]bool{}
+	for _, k := range keys {
+		provided[k] = true
+	}
+	used := map[string]bool{}
+	for _, name := range a.Variables {
+		used[name] = true
+		if !provided[name] {
+			r.Missing = append(r.Missing, name)
+		}
+	}
+	for _, name := range a.Optional {
+		used[name] = true
+	}
+	for _, k := range keys {
+		if !used[k] {
+			r.Unused = append(r.Unused, k)
+		}
+	}
+	return r
+}
+
+// Keys 返回 vars 提供的变量名（已排序），vars 的形式见 Analysis.Check
+func Keys(vars any) ([]string, error) {
+	v := reflect.ValueOf(vars)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	keys := map[string]bool{}
+	switch v.Kind() {
+	case reflect.Slice:
+		names, ok := vars.([]string)
+		if !ok {
+			return nil, fmt.Errorf("变量名列表应为 []string，实际为 %T", vars)
+		}
+		for _, name := range names {
+			keys[name] = true
+		}
+	case reflect.Map, reflect.Struct:
+		// 与渲染时使用同样的字段名
+		m, err := promptrender.Vars(vars)
+		if err != nil {
+			return nil, err
+		}
+		for k := range m {
+			keys[k] = true
+		}
+	default:
+		return nil, fmt.Errorf("变量应为 map 或结构体，实际为 %T", vars)
+	}
+	return sortedKeys(keys), nil
+}
+
+// FromMessages 与 prompt.FromMessages 相同，但会先检查模板并与 vars 对比，有问题时返回错误。
+// vars 是调用 Format 时会传入的变量（或者同样键的样例），形式见 Analysis.Check
+func FromMessages(format schema.FormatType, vars any, templates ...schema.MessagesTemplate) (*prompt.DefaultChatTemplate, error) {
+	if err := Analyze(format, templates...).Check(vars).Err(); err != nil {
+		return nil, err
+	}
+	return prompt.FromMessages(format, templates...), nil
+}
@@ -0,0 +1,38 @@
+package promptcheck
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestCheck(t *testing.T) {
+	a := Analyze(schema.FString, schema.SystemMessage("你是一个{role}"), schema.UserMessage("{question}"))
+	cases := []struct {
+		name            string
+		vars            any
+		missing, unused []string
+	}{
+		{"完整", map[string]any{"role": "助手", "question": "你好"}, nil, nil},
+		{"nil map", map[string]any(nil), []string{"question", "role"}, nil},
+		{"类型化 nil map", map[string]string(nil), []string{"question", "role"}, nil},
+		{"变量名列表", []string{"role", "extra"}, []string{"question"}, []string{"extra"}},
+		{"结构体", struct {
+			Role     string `prompt:"role"`
+			Question string `json:"question"`
+		}{}, nil, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := a.Check(c.vars)
+			if !slices.Equal(r.Missing, c.missing) || !slices.Equal(r.Unused, c.unused) || len(r.Issues) > 0 {
+				t.Errorf("Check = %+v，期望缺少 %v、未使用 %v", r, c.missing, c.unused)
+			}
+		})
+	}
+
+	if r := a.Check(42); r.OK() || r.Err() == nil {
+		t.Error("变量类型不对时应报告问题")
+	}
+}
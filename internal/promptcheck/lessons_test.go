@@ -0,0 +1,157 @@
+package promptcheck_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/promptcheck/promptchecktest"
+)
+
+// lessonDirs 检查其中用字面量写成的 FromMessages 模板
+var lessonDirs = []string{"../../2-Prompt_ChatTemplate", "../../3-Chain"}
+
+var formatNames = map[string]schema.FormatType{
+	"FString":    schema.FString,
+	"GoTemplate": schema.GoTemplate,
+	"Jinja2":     schema.Jinja2,
+}
+
+var roleFuncs = map[string]schema.RoleType{
+	"SystemMessage":    schema.System,
+	"UserMessage":      schema.User,
+	"AssistantMessage": schema.Assistant,
+}
+
+// TestLessonTemplates 解析章节示例的源码，对每个 FromMessages 调用检查模板本身的问题；
+// 消息中有变量或函数调用等无法静态取值的参数时跳过该模板
+func TestLessonTemplates(t *testing.T) {
+	checked := 0
+	for _, dir := range lessonDirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil || len(files) == 0 {
+			t.Fatalf("找不到 %s 中的示例: %v", dir, err)
+		}
+		for _, path := range files {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ast.Inspect(f, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				format, msgs, ok := literalTemplate(call)
+				if !ok {
+					return true
+				}
+				checked++
+				pos := fset.Position(call.Pos())
+				t.Run(filepath.Base(path)+":"+strconv.Itoa(pos.Line), func(t *testing.T) {
+					promptchecktest.AssertTemplate(t, format, nil, msgs...)
+				})
+				return true
+			})
+		}
+	}
+	if checked < 10 {
+		t.Errorf("只检查了 %d 个模板，示例的写法可能变了", checked)
+	}
+}
+
+// literalTemplate 识别 prompt.FromMessages、promptrender.FromMessages 和 promptcheck.FromMessages 调用
+func literalTemplate(call *ast.CallExpr) (schema.FormatType, []schema.MessagesTemplate, bool) {
+	pkg, name, ok := selector(call.Fun)
+	if !ok || name != "FromMessages" || len(call.Args) < 2 {
+		return 0, nil, false
+	}
+	args := call.Args[1:]
+	if pkg == "promptcheck" {
+		// 第二个参数是变量
+		args = call.Args[2:]
+	}
+	pkg, name, ok = selector(call.Args[0])
+	format, known := formatNames[name]
+	if !ok || pkg != "schema" || !known {
+		return 0, nil, false
+	}
+	var msgs []schema.MessagesTemplate
+	for _, arg := range args {
+		msg, ok := literalMessage(arg)
+		if !ok {
+			return 0, nil, false
+		}
+		msgs = append(msgs, msg)
+	}
+	return format, msgs, len(msgs) > 0
+}
+
+// literalMessage 识别参数为字符串字面量的 schema.XxxMessage 和 schema.MessagesPlaceholder
+func literalMessage(e ast.Expr) (schema.MessagesTemplate, bool) {
+	call, ok := e.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return nil, false
+	}
+	pkg, name, ok := selector(call.Fun)
+	if !ok || pkg != "schema" {
+		return nil, false
+	}
+	if name == "MessagesPlaceholder" {
+		if len(call.Args) != 2 {
+			return nil, false
+		}
+		key, ok := literalString(call.Args[0])
+		optional, isIdent := call.Args[1].(*ast.Ident)
+		return schema.MessagesPlaceholder(key, isIdent && optional.Name == "true"), ok && isIdent
+	}
+	role, ok := roleFuncs[name]
+	if !ok {
+		return nil, false
+	}
+	content, ok := literalString(call.Args[0])
+	return &schema.Message{Role: role, Content: content}, ok
+}
+
+// literalString 计算字符串字面量及其 + 拼接
+func literalString(e ast.Expr) (string, bool) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.ParenExpr:
+		return literalString(e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := literalString(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := literalString(e.Y)
+		return x + y, ok
+	}
+	return "", false
+}
+
+func selector(e ast.Expr) (pkg, name string, ok bool) {
+	sel, isSel := e.(*ast.SelectorExpr)
+	if !isSel {
+		return "", "", false
+	}
+	x, isIdent := sel.X.(*ast.Ident)
+	if !isIdent {
+		return "", "", false
+	}
+	return x.Name, sel.Sel.Name, true
+}
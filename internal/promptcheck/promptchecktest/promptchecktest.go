@@ -0,0 +1,26 @@
+// Package promptchecktest 提供在测试中检查提示词模板的辅助函数，只应在 _test.go 中导入，
+// 这样 promptcheck 本身不依赖 testing 包。
+package promptchecktest
+
+import (
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/promptcheck"
+)
+
+// AssertTemplate 检查模板，有问题时调用 tb.Errorf 列出全部问题；vars 的形式见 promptcheck.Analysis.Check
+func AssertTemplate(tb testing.TB, format schema.FormatType, vars any, templates ...schema.MessagesTemplate) {
+	tb.Helper()
+	r := promptcheck.Analyze(format, templates...).Check(vars)
+	for _, name := range r.Missing {
+		tb.Errorf("模板缺少变量 %s", name)
+	}
+	for _, name := range r.Unused {
+		tb.Errorf("变量 %s 没有被模板使用", name)
+	}
+	for _, issue := range r.Issues {
+		tb.Errorf("%s", issue)
+	}
+}
@@ -0,0 +1,241 @@
+package promptrender
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"unicode"
+
+	"github.com/nikolalohinski/gonja"
+	"github.com/nikolalohinski/gonja/config"
+	"github.com/nikolalohinski/gonja/exec"
+	"github.com/nikolalohinski/gonja/nodes"
+	"github.com/nikolalohinski/gonja/parser"
+)
+
+// DefaultTruncateEnd truncate 截断后追加的省略号
+const DefaultTruncateEnd = "..."
+
+// Funcs 返回 GoTemplate 模板可以使用的函数，参数顺序便于放在管道末尾：
+//
+//	{{ .interests | join "、" }}     用分隔符连接列表
+//	{{ .summary | truncate 100 }}    按字符截断，超出时追加 ...
+//	{{ .profile | json }}            输出 JSON
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"join":     join,
+		"truncate": truncate,
+		"json":     toJSON,
+	}
+}
+
+func join(sep string, list any) (string, error) {
+	v := reflect.ValueOf(list)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return "", nil
+	case reflect.Slice, reflect.Array:
+	default:
+		return fmt.Sprint(list), nil
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// truncate 超过 length 个字符时截断，不截断英文单词
+func truncate(length int, s any) string {
+	return truncateString(fmt.Sprint(s), length, false, DefaultTruncateEnd)
+}
+
+// truncateString 与 Jinja2 的 truncate 相同：killwords 为 false 时不把单词截成两半，退回到上一个空白处。
+// 中文没有空格分词，截断处是汉字时直接按字符截断
+func truncateString(s string, length int, killwords bool, end string) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+	keep := max(length-len([]rune(end)), 0)
+	if !killwords && keep > 0 && inWord(runes[keep-1]) && inWord(runes[keep]) {
+		i := keep
+		for i > 0 && !unicode.IsSpace(runes[i-1]) {
+			i--
+		}
+		if i > 0 {
+			keep = i
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:keep]), unicode.IsSpace) + end
+}
+
+// inWord 字符是否属于以空格分词的文字
+func inWord(r rune) bool {
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func toJSON(v any) (string, error) {
+	return marshalJSON(v, "")
+}
+
+// marshalJSON 不转义 <、> 和 &，中文原样输出
+func marshalJSON(v any, indent string) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+var (
+	jinjaEnvOnce sync.Once
+	jinjaEnv     *gonja.Environment
+	jinjaEnvErr  error
+)
+
+// JinjaEnv 返回渲染 Jinja2 模板的环境：与 eino 一样禁用 include、extends、import 和 from，
+// 增加 json 过滤器，truncate 对没有空格的中文按字符截断
+func JinjaEnv() (*gonja.Environment, error) {
+	jinjaEnvOnce.Do(func() {
+		env := gonja.NewEnvironment(config.DefaultConfig, gonja.DefaultLoader)
+		for _, name := range []string{"include", "extends", "import", "from"} {
+			if !env.Statements.Exists(name) {
+				continue
+			}
+			err := env.Statements.Replace(name, func(*parser.Parser, *parser.Parser) (nodes.Statement, error) {
+				return nil, fmt.Errorf("keyword[%s] has been disabled", name)
+			})
+			if err != nil {
+				jinjaEnvErr = err
+				return
+			}
+		}
+		jinjaEnvErr = errors.Join(
+			env.Filters.Register("json", filterJSON),
+			env.Filters.Replace("truncate", filterTruncate),
+		)
+		jinjaEnv = env
+	})
+	return jinjaEnv, jinjaEnvErr
+}
+
+// filterJSON {{ profile | json }} 或 {{ profile | json(indent=2) }}
+func filterJSON(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	p := params.Expect(0, []*exec.KwArg{{Name: "indent", Default: 0}})
+	if p.IsError() {
+		return exec.AsValue(fmt.Errorf("json 过滤器参数有误: %s", p.Error()))
+	}
+	out, err := marshalJSON(in.Interface(), strings.Repeat(" ", p.KwArgs["indent"].Integer()))
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("json 过滤器: %w", err))
+	}
+	return exec.AsSafeValue(out)
+}
+
+// filterTruncate {{ text | truncate(100) }}，参数与 Jinja2 相同：length、killwords、end
+func filterTruncate(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	p := params.Expect(0, []*exec.KwArg{
+		{Name: "length", Default: 255},
+		{Name: "killwords", Default: false},
+		{Name: "end", Default: DefaultTruncateEnd},
+	})
+	if p.IsError() {
+		return exec.AsValue(fmt.Errorf("truncate 过滤器参数有误: %s", p.Error()))
+	}
+	return exec.AsValue(truncateString(in.String(), p.KwArgs["length"].Integer(), p.KwArgs["killwords"].Bool(), p.KwArgs["end"].String()))
+}
+
+// jinjaEndRaw raw 块的结束标签
+var jinjaEndRaw = regexp.MustCompile(`\{%-?\s*endraw\s*-?%\}`)
+
+// CheckJinja 检查 Jinja2 模板中的 {{ }}、{% %}、{# #} 是否闭合，以及标签内的括号和引号是否配对。
+// gonja 的词法分析器遇到未闭合的标签或括号时会死循环，解析前需要先检查
+func CheckJinja(s string) error {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '{' || i+1 == len(s) {
+			continue
+		}
+		var end string
+		switch s[i+1] {
+		case '{':
+			end = "}}"
+		case '%':
+			end = "%}"
+		case '#':
+			n := strings.Index(s[i+2:], "#}")
+			if n < 0 {
+				return fmt.Errorf("位置 %d 的 {# 没有闭合", i)
+			}
+			i += n + 3
+			continue
+		default:
+			continue
+		}
+
+		start := i
+		var stack []byte
+		var quote byte
+		for i += 2; ; i++ {
+			if i >= len(s) {
+				if quote != 0 {
+					return fmt.Errorf("位置 %d 的标签中有未闭合的引号", start)
+				}
+				return fmt.Errorf("位置 %d 的 %s 没有闭合", start, s[start:start+2])
+			}
+			c := s[i]
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				}
+				continue
+			}
+			if len(stack) == 0 && strings.HasPrefix(s[i:], end) {
+				i++
+				break
+			}
+			switch c {
+			case '"', '\'':
+				quote = c
+			case '(':
+				stack = append(stack, ')')
+			case '[':
+				stack = append(stack, ']')
+			case '{':
+				stack = append(stack, '}')
+			case ')', ']', '}':
+				if len(stack) == 0 || stack[len(stack)-1] != c {
+					return fmt.Errorf("位置 %d 的 %c 没有匹配的左括号", i, c)
+				}
+				stack = stack[:len(stack)-1]
+			}
+		}
+		// raw 块中的内容原样输出，不再检查
+		if f := strings.Fields(strings.Trim(s[start+2:i-1], "-")); end == "%}" && len(f) > 0 && f[0] == "raw" {
+			loc := jinjaEndRaw.FindStringIndex(s[i:])
+			if loc == nil {
+				return fmt.Errorf("位置 %d 的 raw 块没有 endraw", start)
+			}
+			i += loc[1] - 1
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package promptrender
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+)
+
+// renderFunc 渲染一段模板文本
+type renderFunc func(vs map[string]any) (string, error)
+
+// ChatTemplate 渲染前先用 Vars 转换变量的 ChatTemplate，GoTemplate 和 Jinja2 模板可以使用 join、truncate、json
+type ChatTemplate struct {
+	format    schema.FormatType
+	templates []schema.MessagesTemplate
+	// compiled 模板文本 -> 预先解析好的渲染函数，FString 模板交给 eino 渲染
+	compiled map[string]renderFunc
+}
+
+var _ prompt.ChatTemplate = (*ChatTemplate)(nil)
+
+// FromMessages 与 prompt.FromMessages 相同，但在创建时解析全部模板，有语法错误时直接返回
+func FromMessages(format schema.FormatType, templates ...schema.MessagesTemplate) (*ChatTemplate, error) {
+	t := &ChatTemplate{format: format, templates: templates, compiled: map[string]renderFunc{}}
+	if format == schema.FString {
+		return t, nil
+	}
+	for i, tpl := range templates {
+		msg, ok := tpl.(*schema.Message)
+		if !ok {
+			continue
+		}
+		for _, text := range contents(msg) {
+			if _, ok := t.compiled[text]; ok {
+				continue
+			}
+			fn, err := compile(format, text)
+			if err != nil {
+				return nil, fmt.Errorf("promptrender: 第 %d 条消息: %w", i+1, err)
+			}
+			t.compiled[text] = fn
+		}
+	}
+	return t, nil
+}
+
+// Format 用 Vars 转换 vs 中的结构体、切片等值，然后渲染消息
+func (t *ChatTemplate) Format(ctx context.Context, vs map[string]any, _ ...prompt.Option) (result []*schema.Message, err error) {
+	ctx = callbacks.EnsureRunInfo(ctx, t.GetType(), components.ComponentOfPrompt)
+	ctx = callbacks.OnStart(ctx, &prompt.CallbackInput{
+		Variables: vs,
+		Templates: t.templates,
+	})
+	defer func() {
+		if err != nil {
+			_ = callbacks.OnError(ctx, err)
+		}
+	}()
+
+	values := map[string]any{}
+	if vs != nil {
+		if values, err = Vars(vs); err != nil {
+			return nil, err
+		}
+	}
+
+	result = make([]*schema.Message, 0, len(t.templates))
+	for i, tpl := range t.templates {
+		msg, ok := tpl.(*schema.Message)
+		if !ok || t.format == schema.FString {
+			msgs, err := tpl.Format(ctx, values, t.format)
+			if err != nil {
+				return nil, fmt.Errorf("promptrender: 第 %d 条消息: %w", i+1, err)
+			}
+			result = append(result, msgs...)
+			continue
+		}
+		out, err := t.render(msg, values)
+		if err != nil {
+			return nil, fmt.Errorf("promptrender: 第 %d 条消息: %w", i+1, err)
+		}
+		result = append(result, out)
+	}
+
+	_ = callbacks.OnEnd(ctx, &prompt.CallbackOutput{
+		Result:    result,
+		Templates: t.templates,
+	})
+	return result, nil
+}
+
+func (t *ChatTemplate) GetType() string {
+	return "Render"
+}
+
+func (t *ChatTemplate) IsCallbacksEnabled() bool {
+	return true
+}
+
+// render 渲染消息的文本内容，返回副本
+func (t *ChatTemplate) render(msg *schema.Message, vs map[string]any) (*schema.Message, error) {
+	out := *msg
+	content, err := t.compiled[msg.Content](vs)
+	if err != nil {
+		return nil, err
+	}
+	out.Content = content
+	if len(msg.UserInputMultiContent) > 0 {
+		out.UserInputMultiContent = append([]schema.MessageInputPart(nil), msg.UserInputMultiContent...)
+		for i, part := range out.UserInputMultiContent {
+			if part.Type != schema.ChatMessagePartTypeText {
+				continue
+			}
+			if out.UserInputMultiContent[i].Text, err = t.compiled[part.Text](vs); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &out, nil
+}
+
+// contents 消息中需要渲染的文本
+func contents(msg *schema.Message) []string {
+	out := []string{msg.Content}
+	for _, part := range msg.UserInputMultiContent {
+		if part.Type == schema.ChatMessagePartTypeText {
+			out = append(out, part.Text)
+		}
+	}
+	return out
+}
+
+func compile(format schema.FormatType, text string) (renderFunc, error) {
+	switch format {
+	case schema.GoTemplate:
+		tpl, err := template.New("template").Funcs(Funcs()).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		return func(vs map[string]any) (string, error) {
+			var sb strings.Builder
+			if err := tpl.Execute(&sb, vs); err != nil {
+				return "", err
+			}
+			return sb.String(), nil
+		}, nil
+	case schema.Jinja2:
+		if err := CheckJinja(text); err != nil {
+			return nil, err
+		}
+		env, err := JinjaEnv()
+		if err != nil {
+			return nil, err
+		}
+		tpl, err := env.FromString(text)
+		if err != nil {
+			return nil, err
+		}
+		return func(vs map[string]any) (string, error) {
+			return tpl.Execute(vs)
+		}, nil
+	default:
+		return nil, fmt.Errorf("未知的模板格式 %v", format)
+	}
+}
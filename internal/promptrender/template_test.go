@@ -0,0 +1,112 @@
+package promptrender
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+type profile struct {
+	Name      string   `prompt:"name"`
+	Age       int      `json:"age"`
+	Interests []string `prompt:"interests"`
+	Bio       string   `prompt:"bio"`
+	Password  string   `prompt:"-"`
+}
+
+func TestFilters(t *testing.T) {
+	vars := map[string]any{
+		"user": profile{Name: "小明", Age: 30, Interests: []string{"阅读", "跑步"}, Bio: "I love writing Go code", Password: "secret"},
+		"zh":   "我喜欢写代码和读书",
+	}
+	cases := []struct {
+		name   string
+		format schema.FormatType
+		text   string
+		want   string
+	}{
+		{"GoTemplate join", schema.GoTemplate, `{{ .user.interests | join "、" }}`, "阅读、跑步"},
+		{"GoTemplate truncate 不截断单词", schema.GoTemplate, `{{ .user.bio | truncate 12 }}`, "I love..."},
+		{"GoTemplate truncate 中文", schema.GoTemplate, `{{ .zh | truncate 6 }}`, "我喜欢..."},
+		{"GoTemplate json", schema.GoTemplate, `{{ .user | json }}`, `{"age":30,"bio":"I love writing Go code","interests":["阅读","跑步"],"name":"小明"}`},
+		{"Jinja2 join", schema.Jinja2, `{{ user.interests | join("、") }}`, "阅读、跑步"},
+		{"Jinja2 truncate", schema.Jinja2, `{{ user.bio | truncate(12) }}`, "I love..."},
+		{"Jinja2 truncate killwords", schema.Jinja2, `{{ user.bio | truncate(12, killwords=true, end="…") }}`, "I love writ…"},
+		{"Jinja2 truncate 中文", schema.Jinja2, `{{ zh | truncate(6) }}`, "我喜欢..."},
+		{"Jinja2 json", schema.Jinja2, `{{ user.interests | json }}`, `["阅读","跑步"]`},
+		{"Jinja2 循环", schema.Jinja2, `{% for i in user.interests %}- {{ i }}{% endfor %}`, "- 阅读- 跑步"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tpl, err := FromMessages(c.format, schema.UserMessage(c.text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			msgs, err := tpl.Format(context.Background(), vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := msgs[0].Content; got != c.want {
+				t.Errorf("渲染结果 = %q，期望 %q", got, c.want)
+			}
+			if strings.Contains(msgs[0].Content, "secret") {
+				t.Error("prompt:\"-\" 的字段不应出现在结果中")
+			}
+		})
+	}
+}
+
+func TestFromMessagesRejectsBadTemplates(t *testing.T) {
+	cases := []struct {
+		format schema.FormatType
+		text   string
+	}{
+		{schema.GoTemplate, "{{ .name "},
+		{schema.Jinja2, "你好 {{ name "},
+		{schema.Jinja2, "{% if x %}"},
+		{schema.Jinja2, `{% include "other.txt" %}`},
+	}
+	for _, c := range cases {
+		if _, err := FromMessages(c.format, schema.UserMessage(c.text)); err == nil {
+			t.Errorf("FromMessages(%q) 应返回错误", c.text)
+		}
+	}
+
+	// 缺少变量时返回错误，而不是输出 <no value>
+	tpl, err := FromMessages(schema.GoTemplate, schema.UserMessage("{{ .missing }}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tpl.Format(context.Background(), map[string]any{}); err == nil {
+		t.Error("缺少变量时应返回错误")
+	}
+}
+
+func TestCheckJinja(t *testing.T) {
+	cases := []struct {
+		text string
+		ok   bool
+	}{
+		{"{{ a }} 和 {% if b %}x{% endif %}", true},
+		{"普通文本 { 和 }", true},
+		{"{# 注释 #}{{ a }}", true},
+		{`{{ f("}}") }}`, true},
+		{"{{ d({'k': [1, 2]}) }}", true},
+		{"{% raw %}{{ 原样 {% endraw %}", true},
+		{"{{ a", false},
+		{"{% if a", false},
+		{"{# 注释", false},
+		{"{{ f(a }}", false},
+		{"{{ a) }}", false},
+		{"{{ 'abc }}", false},
+		{"{% raw %}{{", false},
+	}
+	for _, c := range cases {
+		err := CheckJinja(c.text)
+		if (err == nil) != c.ok {
+			t.Errorf("CheckJinja(%q) = %v，期望 ok=%v", c.text, err, c.ok)
+		}
+	}
+}
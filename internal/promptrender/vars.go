@@ -0,0 +1,175 @@
+// Package promptrender 直接用 Go 结构体渲染提示词模板。
+//
+// Vars 按字段标签把结构体（以及其中嵌套的结构体、切片和 map）转换成模板变量，
+// 字段名优先取 prompt 标签，其次是 json 标签，都没有时使用字段名：
+//
+//	type UserProfile struct {
+//		Name      string   `prompt:"name"`
+//		Interests []string `prompt:"interests"`
+//		Password  string   `prompt:"-"`
+//	}
+//
+// FromMessages 与 prompt.FromMessages 用法相同，GoTemplate 和 Jinja2 模板中可以对列表和对象使用循环、
+// 条件以及 join、truncate、json 过滤器，不需要先在 Go 代码中手工拼接字符串。
+package promptrender
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// maxDepth 嵌套层数上限，超过时认为存在循环引用
+const maxDepth = 32
+
+var (
+	messageType     = reflect.TypeOf((*schema.Message)(nil))
+	messagesType    = reflect.TypeOf([]*schema.Message(nil))
+	textMarshalType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Vars 把 map 或结构体转换成模板变量。
+// 嵌套的结构体转换为 map[string]any，切片和数组转换为 []any，实现了 encoding.TextMarshaler 的值（如 time.Time）
+// 转换为文本；*schema.Message 和 []*schema.Message 保持不变，供消息占位符使用
+func Vars(v any) (map[string]any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			break
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Map && rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("promptrender: 变量应为 map 或结构体，实际为 %T", v)
+	}
+	out, err := convert(rv, 0)
+	if err != nil {
+		return nil, fmt.Errorf("promptrender: %w", err)
+	}
+	switch m := out.(type) {
+	case nil:
+		// nil map 按没有变量处理
+		return map[string]any{}, nil
+	case map[string]any:
+		return m, nil
+	default:
+		return nil, fmt.Errorf("promptrender: %T 转换后不是对象", v)
+	}
+}
+
+// FieldName 返回结构体字段对应的变量名，字段不参与渲染时返回 false
+func FieldName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	for _, key := range []string{"prompt", "json"} {
+		name, _, _ := strings.Cut(f.Tag.Get(key), ",")
+		if name == "-" {
+			return "", false
+		}
+		if name != "" {
+			return name, true
+		}
+	}
+	return f.Name, true
+}
+
+func convert(v reflect.Value, depth int) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if depth > maxDepth {
+		return nil, fmt.Errorf("嵌套超过 %d 层，可能存在循环引用", maxDepth)
+	}
+	if v.Type() == messageType || v.Type() == messagesType {
+		return v.Interface(), nil
+	}
+	if v.Type().Implements(textMarshalType) && !(v.Kind() == reflect.Pointer && v.IsNil()) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("转换 %s 失败: %w", v.Type(), err)
+		}
+		return string(text), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return convert(v.Elem(), depth+1)
+	case reflect.Struct:
+		m := map[string]any{}
+		if err := convertFields(m, v, depth); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			val, err := convert(iter.Value(), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(iter.Key().Interface())] = val
+		}
+		return m, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+		list := make([]any, v.Len())
+		for i := range list {
+			val, err := convert(v.Index(i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = val
+		}
+		return list, nil
+	case reflect.Chan, reflect.UnsafePointer:
+		return nil, fmt.Errorf("不支持的变量类型 %s", v.Type())
+	default:
+		return v.Interface(), nil
+	}
+}
+
+// convertFields 把结构体字段写入 m，没有标签的匿名嵌入结构体展开到同一层
+func convertFields(m map[string]any, v reflect.Value, depth int) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+		if f.Anonymous && f.IsExported() && f.Tag.Get("prompt") == "" && f.Tag.Get("json") == "" {
+			for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := convertFields(m, fv, depth); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		name, ok := FieldName(f)
+		if !ok {
+			continue
+		}
+		val, err := convert(fv, depth+1)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+		m[name] = val
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package promptrender
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestVarsNilMap(t *testing.T) {
+	type profile struct {
+		Name string `prompt:"name"`
+	}
+	cases := map[string]any{
+		"nil map":           map[string]any(nil),
+		"typed nil map":     map[string]string(nil),
+		"empty map pointer": &map[string]any{},
+	}
+	for name, v := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Vars(v)
+			if err != nil {
+				t.Fatalf("Vars 返回错误: %v", err)
+			}
+			if got == nil || len(got) != 0 {
+				t.Fatalf("Vars = %#v，期望空 map", got)
+			}
+		})
+	}
+
+	if _, err := Vars((*profile)(nil)); err == nil {
+		t.Error("nil 结构体指针应返回错误")
+	}
+	got, err := Vars(profile{Name: "小明"})
+	if err != nil || got["name"] != "小明" {
+		t.Errorf("Vars(profile) = %#v, %v", got, err)
+	}
+}
+
+func TestVarsConversion(t *testing.T) {
+	type Base struct {
+		ID string `prompt:"id"`
+	}
+	type Tagged struct {
+		Note string
+	}
+	type item struct {
+		Title string `json:"title"`
+	}
+	type doc struct {
+		Base
+		Tagged   `prompt:"tagged"`
+		Created  time.Time         `prompt:"created"`
+		Raw      []byte            `prompt:"raw"`
+		Items    []item            `prompt:"items"`
+		Scores   map[int]float64   `prompt:"scores"`
+		History  []*schema.Message `prompt:"history"`
+		Optional *item             `prompt:"optional"`
+		hidden   string
+	}
+	created := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	history := []*schema.Message{schema.UserMessage("你好")}
+	got, err := Vars(doc{
+		Base:    Base{ID: "d1"},
+		Tagged:  Tagged{Note: "n"},
+		Created: created,
+		Raw:     []byte("字节"),
+		Items:   []item{{Title: "a"}},
+		Scores:  map[int]float64{1: 0.5},
+		History: history,
+		hidden:  "x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"id":       "d1",
+		"tagged":   map[string]any{"Note": "n"},
+		"created":  "2024-05-01T08:00:00Z",
+		"raw":      "字节",
+		"items":    []any{map[string]any{"title": "a"}},
+		"scores":   map[string]any{"1": 0.5},
+		"history":  history,
+		"optional": nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Vars =\n%#v\n期望\n%#v", got, want)
+	}
+}
+
+func TestVarsCycle(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+	n := &node{Name: "a"}
+	n.Next = n
+	if _, err := Vars(map[string]any{"node": n}); err == nil || !strings.Contains(err.Error(), "循环引用") {
+		t.Errorf("循环引用应返回错误，得到 %v", err)
+	}
+	if _, err := Vars(map[string]any{"ch": make(chan int)}); err == nil {
+		t.Error("chan 应返回错误")
+	}
+	if _, err := Vars("字符串"); err == nil {
+		t.Error("非 map 或结构体应返回错误")
+	}
+}
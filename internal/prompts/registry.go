@@ -0,0 +1,249 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+)
+
+// DefaultDir 默认的模板目录（相对于运行目录）
+const DefaultDir = "configs/prompts"
+
+// Registry 一个目录中的所有模板，按名称和版本查找
+type Registry struct {
+	dir string
+
+	mu sync.RWMutex
+	// templates 名称 -> 按版本从低到高排序的模板
+	templates map[string][]*Template
+	// stamp 上次加载时目录中文件的修改时间和大小，用于判断是否需要重新加载
+	stamp string
+}
+
+// Load 加载 dir 下（包括子目录）所有 .yaml、.yml 和 .md 模板
+func Load(dir string) (*Registry, error) {
+	r := &Registry{dir: dir}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 重新加载目录；任何文件有错误时保留之前加载的模板，并返回所有文件的错误
+func (r *Registry) Reload() error {
+	files, stamp, err := scan(r.dir)
+	if err != nil {
+		return fmt.Errorf("prompts: 读取目录 %s 失败: %w", r.dir, err)
+	}
+
+	templates := map[string][]*Template{}
+	var errs []error
+	for _, path := range files {
+		t, err := ParseFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, other := range templates[t.Name] {
+			if compareVersions(other.Version, t.Version) == 0 {
+				errs = append(errs, fmt.Errorf("prompts: %s@%s 在 %s 和 %s 中重复定义", t.Name, t.Version, other.Path, t.Path))
+			}
+		}
+		templates[t.Name] = append(templates[t.Name], t)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	for _, versions := range templates {
+		sort.Slice(versions, func(i, j int) bool {
+			return compareVersions(versions[i].Version, versions[j].Version) < 0
+		})
+	}
+
+	r.mu.Lock()
+	r.templates, r.stamp = templates, stamp
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch 每隔 interval 检查一次目录，有文件新增、删除或修改时重新加载，直到 ctx 结束。
+// 重新加载失败时继续使用之前的模板，错误交给 onError（可以为 nil）
+func (r *Registry) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, stamp, err := scan(r.dir)
+		if err == nil {
+			r.mu.RLock()
+			same := stamp == r.stamp
+			r.mu.RUnlock()
+			if same {
+				continue
+			}
+			if err = r.Reload(); err != nil {
+				// 记下出错时的目录状态，文件再次修改前不重复报错
+				r.mu.Lock()
+				r.stamp = stamp
+				r.mu.Unlock()
+			}
+		}
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// Lookup 返回指定名称和版本的模板，version 为空时返回最新版本
+func (r *Registry) Lookup(name, version string) (*Template, error) {
+	r.mu.RLock()
+	versions := r.templates[name]
+	r.mu.RUnlock()
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("prompts: 未知的模板 %q，可用: %s", name, strings.Join(r.Names(), ", "))
+	}
+	if version == "" {
+		return versions[len(versions)-1], nil
+	}
+	for _, t := range versions {
+		if compareVersions(t.Version, version) == 0 {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("prompts: 模板 %s 没有版本 %q，可用: %s", name, version, strings.Join(versionNames(versions), ", "))
+}
+
+// Get 返回指定名称和版本的 ChatTemplate，version 为空时使用最新版本。
+// 每次 Format 时重新查找，Watch 重新加载后使用的是新的模板内容
+func (r *Registry) Get(name, version string) (prompt.ChatTemplate, error) {
+	if _, err := r.Lookup(name, version); err != nil {
+		return nil, err
+	}
+	return &liveTemplate{r: r, name: name, version: version}, nil
+}
+
+// Names 返回所有模板名称（已排序）
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Versions 返回模板的所有版本，从低到高排序
+func (r *Registry) Versions(name string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return versionNames(r.templates[name])
+}
+
+// liveTemplate 每次 Format 时从 Registry 中取出当前的模板
+type liveTemplate struct {
+	r       *Registry
+	name    string
+	version string
+}
+
+func (l *liveTemplate) Format(ctx context.Context, vs map[string]any, opts ...prompt.Option) ([]*schema.Message, error) {
+	t, err := l.r.Lookup(l.name, l.version)
+	if err != nil {
+		return nil, err
+	}
+	return t.Format(ctx, vs, opts...)
+}
+
+func (l *liveTemplate) GetType() string {
+	return "PromptFile"
+}
+
+// IsCallbacksEnabled 回调由内部的 ChatTemplate 触发
+func (l *liveTemplate) IsCallbacksEnabled() bool {
+	return true
+}
+
+// scan 列出目录中的模板文件，并返回由文件名、修改时间和大小组成的目录状态
+func scan(dir string) ([]string, string, error) {
+	var files []string
+	var stamp strings.Builder
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".md":
+		default:
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, path)
+		fmt.Fprintf(&stamp, "%s|%d|%d\n", path, info.ModTime().UnixNano(), info.Size())
+		return nil
+	})
+	return files, stamp.String(), err
+}
+
+func versionNames(ts []*Template) []string {
+	names := make([]string, len(ts))
+	for i, t := range ts {
+		names[i] = t.Version
+	}
+	return names
+}
+
+// compareVersions 按点分隔的各段比较版本号，数字段按数值比较，例如 1.10 > 1.9
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		// 缺少的段视为 0，1.0 与 1 相同
+		x, y := "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xerr := strconv.Atoi(x)
+		yn, yerr := strconv.Atoi(y)
+		if xerr != nil || yerr != nil {
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+			continue
+		}
+		if xn != yn {
+			if xn < yn {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package prompts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/promptcheck/promptchecktest"
+)
+
+// TestConfigPrompts 加载 configs/prompts 中的全部模板，检查声明的变量与消息一致，并用示例值渲染每个版本
+func TestConfigPrompts(t *testing.T) {
+	r, err := Load("../../" + DefaultDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Names()) == 0 {
+		t.Fatal("没有加载到模板")
+	}
+	for _, name := range r.Names() {
+		for _, version := range r.Versions(name) {
+			t.Run(name+"@"+version, func(t *testing.T) {
+				tpl, err := r.Lookup(name, version)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var msgs []schema.MessagesTemplate
+				for _, m := range tpl.Messages {
+					if m.Placeholder != "" {
+						msgs = append(msgs, schema.MessagesPlaceholder(m.Placeholder, m.Optional))
+					} else {
+						msgs = append(msgs, &schema.Message{Role: m.Role, Content: m.Content})
+					}
+				}
+				vars := map[string]any{}
+				for _, v := range tpl.Variables {
+					if v.Type == TypeMessages {
+						vars[v.Name] = []*schema.Message{schema.UserMessage("示例")}
+					} else {
+						vars[v.Name] = "示例"
+					}
+				}
+				promptchecktest.AssertTemplate(t, formats[strings.ToLower(tpl.Syntax)], vars, msgs...)
+
+				out, err := tpl.Format(context.Background(), vars)
+				if err != nil {
+					t.Fatalf("渲染失败: %v", err)
+				}
+				if len(out) == 0 {
+					t.Error("渲染结果为空")
+				}
+				// 作为人设使用时只需要系统消息中的变量，默认值即可
+				if out[0].Role == schema.System {
+					system, err := tpl.System(context.Background(), nil)
+					if err != nil || system == "" {
+						t.Errorf("System = %q, %v", system, err)
+					}
+				}
+			})
+		}
+	}
+}
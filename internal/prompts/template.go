@@ -0,0 +1,404 @@
+// Package prompts 从目录中的 YAML / Markdown 文件加载提示词模板。
+//
+// 每个文件声明一个模板：名称、版本、说明、变量（类型、默认值）和各角色的消息。YAML 文件：
+//
+//	name: translator
+//	version: 1.0.0
+//	description: 翻译助手
+//	variables:
+//	  - name: target
+//	    type: string
+//	    default: 英文
+//	messages:
+//	  - role: system
+//	    content: 请把用户的内容翻译成{target}。
+//	  - placeholder: history
+//	    optional: true
+//	  - role: user
+//	    content: "{text}"
+//
+// Markdown 文件把同样的元信息写在开头 --- 之间的 front matter 中，正文用 "# system"、"# user"、
+// "# assistant" 标题分隔各条消息，"# placeholder history" 表示消息占位符。
+//
+// 加载时用 promptcheck 检查声明的变量与消息中用到的是否一致。
+// Registry 按名称和版本返回 prompt.ChatTemplate，Format 时补上默认值并检查变量类型，再交给 promptrender 渲染，
+// 变量可以直接是结构体和列表。开发时可以用 Watch 轮询目录，文件修改后自动重新加载。
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+	"gopkg.in/yaml.v3"
+
+	"eino-tutorial/internal/promptcheck"
+	"eino-tutorial/internal/promptrender"
+)
+
+// 变量类型
+const (
+	TypeAny      = "any"
+	TypeString   = "string"
+	TypeNumber   = "number"
+	TypeInteger  = "integer"
+	TypeBoolean  = "boolean"
+	TypeArray    = "array"
+	TypeObject   = "object"
+	TypeMessages = "messages"
+)
+
+// Variable 模板变量
+type Variable struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type,omitempty"`
+	Default     any    `yaml:"default,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	// Required 为 nil 时，没有默认值的变量为必填
+	Required *bool `yaml:"required,omitempty"`
+}
+
+// required 变量是否必须由调用方提供
+func (v *Variable) required() bool {
+	if v.Required != nil {
+		return *v.Required
+	}
+	return v.Default == nil
+}
+
+// Message 模板中的一条消息，Placeholder 不为空时表示消息占位符
+type Message struct {
+	Role        schema.RoleType `yaml:"role,omitempty"`
+	Content     string          `yaml:"content,omitempty"`
+	Placeholder string          `yaml:"placeholder,omitempty"`
+	Optional    bool            `yaml:"optional,omitempty"`
+}
+
+// Template 从文件加载的提示词模板
+type Template struct {
+	Name        string `yaml:"name"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+	// Syntax 模板语法：fstring（默认）、go_template 或 jinja2
+	Syntax    string     `yaml:"format,omitempty"`
+	Variables []Variable `yaml:"variables,omitempty"`
+	Messages  []Message  `yaml:"messages,omitempty"`
+	// Path 模板文件路径
+	Path string `yaml:"-"`
+
+	format schema.FormatType
+	tpl    prompt.ChatTemplate
+}
+
+var _ prompt.ChatTemplate = (*Template)(nil)
+
+var formats = map[string]schema.FormatType{
+	"":            schema.FString,
+	"fstring":     schema.FString,
+	"go_template": schema.GoTemplate,
+	"jinja2":      schema.Jinja2,
+}
+
+var roles = map[schema.RoleType]bool{
+	schema.System:    true,
+	schema.User:      true,
+	schema.Assistant: true,
+}
+
+// ParseFile 解析一个模板文件，格式由扩展名决定：.yaml、.yml 或 .md
+func ParseFile(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Template{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, t)
+	case ".md":
+		err = parseMarkdown(data, t)
+	default:
+		return nil, fmt.Errorf("prompts: 不支持的模板文件格式: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("prompts: 解析 %s 失败: %w", path, err)
+	}
+
+	t.Path = path
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := t.init(); err != nil {
+		return nil, fmt.Errorf("prompts: %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// parseMarkdown 解析 front matter 和按角色标题分隔的正文
+func parseMarkdown(data []byte, t *Template) error {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	body := string(data)
+	if rest, ok := strings.CutPrefix(body, "---\n"); ok {
+		meta, content, ok := strings.Cut(rest, "\n---\n")
+		if !ok {
+			return errors.New("front matter 缺少结束的 ---")
+		}
+		if err := yaml.Unmarshal([]byte(meta), t); err != nil {
+			return err
+		}
+		body = content
+	}
+	if len(t.Messages) > 0 {
+		return errors.New("Markdown 模板的消息应写在正文中，不能在 front matter 中声明 messages")
+	}
+
+	var cur *Message
+	var lines []string
+	flush := func() {
+		if cur != nil {
+			cur.Content = strings.TrimSpace(strings.Join(lines, "\n"))
+			t.Messages = append(t.Messages, *cur)
+		}
+		lines = nil
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if m, ok := markdownHeading(line); ok {
+			flush()
+			cur = m
+			continue
+		}
+		if cur == nil {
+			if strings.TrimSpace(line) != "" {
+				return fmt.Errorf("正文在第一个角色标题之前有内容: %q", line)
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return nil
+}
+
+// markdownHeading 识别 "# system"、"## user"、"# placeholder history" 等标题，其它标题属于消息内容
+func markdownHeading(line string) (*Message, bool) {
+	text := strings.TrimLeft(line, "#")
+	if len(text) == len(line) || !strings.HasPrefix(text, " ") {
+		return nil, false
+	}
+	fields := strings.Fields(strings.ToLower(text))
+	switch {
+	case len(fields) == 1 && roles[schema.RoleType(fields[0])]:
+		return &Message{Role: schema.RoleType(fields[0])}, true
+	case len(fields) >= 2 && strings.TrimSuffix(fields[0], ":") == "placeholder":
+		// 占位符名称保留原始大小写
+		orig := strings.Fields(text)
+		m := &Message{Placeholder: orig[1]}
+		m.Optional = len(fields) == 3 && fields[2] == "optional"
+		return m, true
+	}
+	return nil, false
+}
+
+// init 检查模板声明并构造 ChatTemplate
+func (t *Template) init() error {
+	if t.Version == "" {
+		return errors.New("缺少 version")
+	}
+	format, ok := formats[strings.ToLower(t.Syntax)]
+	if !ok {
+		return fmt.Errorf("未知的 format %q，可选: fstring, go_template, jinja2", t.Syntax)
+	}
+	t.format = format
+	if len(t.Messages) == 0 {
+		return errors.New("没有任何消息")
+	}
+
+	seen := map[string]bool{}
+	for i := range t.Variables {
+		v := &t.Variables[i]
+		if v.Name == "" {
+			return fmt.Errorf("第 %d 个变量缺少 name", i+1)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("变量 %s 重复声明", v.Name)
+		}
+		seen[v.Name] = true
+		if v.Type == "" {
+			v.Type = TypeAny
+		}
+		if _, ok := typeChecks[v.Type]; !ok {
+			return fmt.Errorf("变量 %s 的类型 %q 无效，可选: %s", v.Name, v.Type, strings.Join(typeNames(), ", "))
+		}
+		if v.Default != nil {
+			if err := checkType(v.Type, v.Default); err != nil {
+				return fmt.Errorf("变量 %s 的默认值: %w", v.Name, err)
+			}
+		}
+	}
+
+	msgs := make([]schema.MessagesTemplate, 0, len(t.Messages))
+	for i, m := range t.Messages {
+		if m.Placeholder != "" {
+			if !seen[m.Placeholder] {
+				// 占位符自动声明为 messages 类型的变量
+				seen[m.Placeholder] = true
+				required := !m.Optional
+				t.Variables = append(t.Variables, Variable{Name: m.Placeholder, Type: TypeMessages, Required: &required})
+			}
+			msgs = append(msgs, schema.MessagesPlaceholder(m.Placeholder, m.Optional))
+			continue
+		}
+		if !roles[m.Role] {
+			return fmt.Errorf("第 %d 条消息的角色 %q 无效，可选: system, user, assistant", i+1, m.Role)
+		}
+		msgs = append(msgs, &schema.Message{Role: m.Role, Content: m.Content})
+	}
+	// 声明的变量必须与模板中用到的一致
+	if err := promptcheck.Analyze(format, msgs...).Check(seen).Err(); err != nil {
+		return err
+	}
+	tpl, err := promptrender.FromMessages(format, msgs...)
+	if err != nil {
+		return err
+	}
+	t.tpl = tpl
+	return nil
+}
+
+// Format 补上默认值、检查变量类型后渲染消息
+func (t *Template) Format(ctx context.Context, vs map[string]any, opts ...prompt.Option) ([]*schema.Message, error) {
+	values, err := t.bind(vs)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := t.tpl.Format(ctx, values, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("prompts: 渲染 %s@%s 失败: %w", t.Name, t.Version, err)
+	}
+	return msgs, nil
+}
+
+// System 只渲染模板中的系统消息，多条时以空行连接，用于把模板当作人设使用。
+// vars 覆盖变量的默认值，只在其它消息中用到的变量不需要提供
+func (t *Template) System(ctx context.Context, vars map[string]any) (string, error) {
+	var msgs []schema.MessagesTemplate
+	for _, m := range t.Messages {
+		if m.Placeholder == "" && m.Role == schema.System {
+			msgs = append(msgs, &schema.Message{Role: m.Role, Content: m.Content})
+		}
+	}
+	if len(msgs) == 0 {
+		return "", fmt.Errorf("prompts: %s@%s 没有系统消息", t.Name, t.Version)
+	}
+	values := t.Defaults()
+	for k, v := range vars {
+		values[k] = v
+	}
+	tpl, err := promptrender.FromMessages(t.format, msgs...)
+	if err != nil {
+		return "", fmt.Errorf("prompts: %s@%s: %w", t.Name, t.Version, err)
+	}
+	out, err := tpl.Format(ctx, values)
+	if err != nil {
+		return "", fmt.Errorf("prompts: 渲染 %s@%s 的系统消息失败: %w", t.Name, t.Version, err)
+	}
+	parts := make([]string, len(out))
+	for i, m := range out {
+		parts[i] = m.Content
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// Defaults 返回有默认值的变量
+func (t *Template) Defaults() map[string]any {
+	values := map[string]any{}
+	for _, v := range t.Variables {
+		if v.Default != nil {
+			values[v.Name] = v.Default
+		}
+	}
+	return values
+}
+
+func (t *Template) GetType() string {
+	return "PromptFile"
+}
+
+// IsCallbacksEnabled 回调由内部的 ChatTemplate 触发
+func (t *Template) IsCallbacksEnabled() bool {
+	return true
+}
+
+// bind 返回补上默认值的变量，不修改 vs
+func (t *Template) bind(vs map[string]any) (map[string]any, error) {
+	values := make(map[string]any, len(vs)+len(t.Variables))
+	for k, v := range vs {
+		values[k] = v
+	}
+
+	var problems []string
+	for _, v := range t.Variables {
+		val, ok := values[v.Name]
+		switch {
+		case ok:
+			if err := checkType(v.Type, val); err != nil {
+				problems = append(problems, fmt.Sprintf("变量 %s: %v", v.Name, err))
+			}
+		case v.Default != nil:
+			values[v.Name] = v.Default
+		case v.required():
+			problems = append(problems, fmt.Sprintf("缺少必填变量 %s", v.Name))
+		case v.Type != TypeMessages:
+			// 可选且没有默认值的变量渲染为空
+			values[v.Name] = ""
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("prompts: %s@%s: %s", t.Name, t.Version, strings.Join(problems, "; "))
+	}
+	return values, nil
+}
+
+var typeChecks = map[string]func(v reflect.Value) bool{
+	TypeAny:    func(reflect.Value) bool { return true },
+	TypeString: func(v reflect.Value) bool { return v.Kind() == reflect.String },
+	TypeNumber: func(v reflect.Value) bool { return v.CanInt() || v.CanUint() || v.CanFloat() },
+	TypeInteger: func(v reflect.Value) bool {
+		return v.CanInt() || v.CanUint() || (v.CanFloat() && v.Float() == float64(int64(v.Float())))
+	},
+	TypeBoolean: func(v reflect.Value) bool { return v.Kind() == reflect.Bool },
+	TypeArray:   func(v reflect.Value) bool { return v.Kind() == reflect.Slice || v.Kind() == reflect.Array },
+	TypeObject: func(v reflect.Value) bool {
+		return v.Kind() == reflect.Map || v.Kind() == reflect.Struct ||
+			(v.Kind() == reflect.Pointer && v.Elem().Kind() == reflect.Struct)
+	},
+	TypeMessages: func(v reflect.Value) bool { return v.Type() == reflect.TypeOf([]*schema.Message(nil)) },
+}
+
+func checkType(typ string, val any) error {
+	if val == nil {
+		return fmt.Errorf("值为 nil，应为 %s", typ)
+	}
+	if !typeChecks[typ](reflect.ValueOf(val)) {
+		return fmt.Errorf("类型为 %T，应为 %s", val, typ)
+	}
+	return nil
+}
+
+func typeNames() []string {
+	names := make([]string, 0, len(typeChecks))
+	for name := range typeChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,224 @@
+// Package ratelimit 为模型调用提供进程内共享的限流与并发控制。
+//
+// 每个 key（通常是提供方或 API Key）可以配置每分钟请求数、每分钟 token 数和最大并发数。
+// 等待配额的调用按到达顺序排队（先到先得，不会被后来的小请求插队），等待期间响应 ctx 的取消和截止时间。
+// Default 返回进程内共享的 Limiter，通过 chatmodel.New 创建的模型会按 configs/models.yaml
+// 中的 rate_limits 自动使用它，所有 Chain、Agent 共享同一份配额。
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limits 一个 key 的限额，零值字段表示不限制
+type Limits struct {
+	RequestsPerMinute int `json:"requests_per_minute,omitempty" yaml:"requests_per_minute,omitempty"`
+	TokensPerMinute   int `json:"tokens_per_minute,omitempty" yaml:"tokens_per_minute,omitempty"`
+	MaxInFlight       int `json:"max_in_flight,omitempty" yaml:"max_in_flight,omitempty"`
+}
+
+// Limiter 按 key 管理配额，可在多个 goroutine 之间共享
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// New 创建 Limiter
+func New() *Limiter {
+	return &Limiter{buckets: map[string]*bucket{}}
+}
+
+var defaultLimiter = New()
+
+// Default 返回进程内共享的 Limiter
+func Default() *Limiter {
+	return defaultLimiter
+}
+
+// SetLimits 设置 key 的限额，已经在排队的调用按新限额继续
+func (l *Limiter) SetLimits(key string, lim Limits) {
+	b := l.bucket(key)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	// 首次设置的限额从满配额开始，修改限额时不超过新的上限
+	if b.lim.RequestsPerMinute == 0 {
+		b.reqAvail = float64(lim.RequestsPerMinute)
+	}
+	if b.lim.TokensPerMinute == 0 {
+		b.tokAvail = float64(lim.TokensPerMinute)
+	}
+	b.lim = lim
+	b.reqAvail = math.Min(b.reqAvail, float64(lim.RequestsPerMinute))
+	b.tokAvail = math.Min(b.tokAvail, float64(lim.TokensPerMinute))
+	b.dispatch()
+}
+
+func (l *Limiter) bucket(key string) *bucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{key: key, queue: list.New(), last: time.Now()}
+		l.buckets[key] = b
+	}
+	return b
+}
+
+// Permit 一次获得的配额，调用结束后必须 Release
+type Permit struct {
+	b        *bucket
+	tokens   int
+	released bool
+}
+
+// Acquire 为 key 上一次预计消耗 tokens 个 token 的调用申请配额，没有配额时排队等待。
+// 未配置限额的 key 立即返回
+func (l *Limiter) Acquire(ctx context.Context, key string, tokens int) (*Permit, error) {
+	b := l.bucket(key)
+	w := &waiter{tokens: tokens, ready: make(chan struct{})}
+
+	b.mu.Lock()
+	el := b.queue.PushBack(w)
+	b.dispatch()
+	b.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return &Permit{b: b, tokens: tokens}, nil
+	case <-ctx.Done():
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if w.granted {
+		// 取消的同时拿到了配额：退还
+		b.inFlight--
+		b.reqAvail++
+		b.tokAvail += float64(tokens)
+	} else {
+		b.queue.Remove(el)
+	}
+	b.dispatch()
+	return nil, fmt.Errorf("ratelimit: 等待 %s 的配额时: %w", key, context.Cause(ctx))
+}
+
+// Release 归还并发名额。actualTokens > 0 时按实际用量修正 token 配额
+func (p *Permit) Release(actualTokens int) {
+	b := p.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if p.released {
+		return
+	}
+	p.released = true
+	b.inFlight--
+	if actualTokens > 0 && b.lim.TokensPerMinute > 0 {
+		b.refill(time.Now())
+		// 实际用量超出预估时可以为负，之后的调用需要等配额恢复
+		b.tokAvail -= float64(actualTokens - p.tokens)
+		b.tokAvail = math.Min(b.tokAvail, float64(b.lim.TokensPerMinute))
+	}
+	b.dispatch()
+}
+
+type waiter struct {
+	tokens  int
+	ready   chan struct{}
+	granted bool
+}
+
+type bucket struct {
+	key string
+
+	mu       sync.Mutex
+	lim      Limits
+	reqAvail float64
+	tokAvail float64
+	last     time.Time
+	inFlight int
+	queue    *list.List
+	timer    *time.Timer
+	wakeAt   time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Minutes()
+	b.last = now
+	if rpm := float64(b.lim.RequestsPerMinute); rpm > 0 {
+		b.reqAvail = math.Min(rpm, b.reqAvail+elapsed*rpm)
+	}
+	if tpm := float64(b.lim.TokensPerMinute); tpm > 0 {
+		b.tokAvail = math.Min(tpm, b.tokAvail+elapsed*tpm)
+	}
+}
+
+// need 超过每分钟上限的请求只要求配额回满
+func (b *bucket) need(tokens int) float64 {
+	return math.Min(float64(tokens), float64(b.lim.TokensPerMinute))
+}
+
+// dispatch 按顺序放行队首的调用，队首因速率受限时定时唤醒。调用方持有 b.mu
+func (b *bucket) dispatch() {
+	now := time.Now()
+	b.refill(now)
+	for el := b.queue.Front(); el != nil; el = b.queue.Front() {
+		w := el.Value.(*waiter)
+		if b.lim.MaxInFlight > 0 && b.inFlight >= b.lim.MaxInFlight {
+			// 等正在进行的调用 Release
+			return
+		}
+		if wait := b.waitFor(w); wait > 0 {
+			b.wakeAfter(now, wait)
+			return
+		}
+		if b.lim.RequestsPerMinute > 0 {
+			b.reqAvail--
+		}
+		if b.lim.TokensPerMinute > 0 {
+			b.tokAvail -= float64(w.tokens)
+		}
+		b.inFlight++
+		w.granted = true
+		close(w.ready)
+		b.queue.Remove(el)
+	}
+}
+
+// waitFor 队首调用还需要等待多久才有速率配额
+func (b *bucket) waitFor(w *waiter) time.Duration {
+	var wait float64 // 分钟
+	if rpm := float64(b.lim.RequestsPerMinute); rpm > 0 && b.reqAvail < 1 {
+		wait = math.Max(wait, (1-b.reqAvail)/rpm)
+	}
+	if tpm := float64(b.lim.TokensPerMinute); tpm > 0 {
+		if need := b.need(w.tokens); b.tokAvail < need {
+			wait = math.Max(wait, (need-b.tokAvail)/tpm)
+		}
+	}
+	if wait == 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(wait * float64(time.Minute)))
+}
+
+func (b *bucket) wakeAfter(now time.Time, wait time.Duration) {
+	at := now.Add(wait)
+	if b.timer != nil && !b.wakeAt.After(at) && b.wakeAt.After(now) {
+		return
+	}
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.wakeAt = at
+	b.timer = time.AfterFunc(wait, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.dispatch()
+	})
+}
@@ -0,0 +1,228 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// blockingModel 收到请求后通知 started，等 release 关闭后才返回
+type blockingModel struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingModel() *blockingModel {
+	return &blockingModel{started: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (m *blockingModel) Generate(ctx context.Context, _ []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	m.started <- struct{}{}
+	<-m.release
+	return schema.AssistantMessage("好的", nil), nil
+}
+
+func (m *blockingModel) Stream(ctx context.Context, _ []*schema.Message, _ ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	m.started <- struct{}{}
+	return schema.StreamReaderFromArray([]*schema.Message{
+		schema.AssistantMessage("好", nil),
+		schema.AssistantMessage("的", nil),
+	}), nil
+}
+
+type fakeEmbedder struct {
+	calls int
+}
+
+func (e *fakeEmbedder) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	e.calls++
+	return make([][]float64, len(texts)), nil
+}
+
+// waitErr 在 d 内等待 fn 返回
+func waitErr(t *testing.T, d time.Duration, fn func() error) (error, bool) {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err, true
+	case <-time.After(d):
+		return nil, false
+	}
+}
+
+func TestSharedInFlight(t *testing.T) {
+	limiter := New()
+	limiter.SetLimits("ark", Limits{MaxInFlight: 1})
+	inner := newBlockingModel()
+	cm := WrapChatModel(inner, limiter, "ark")
+	emb := &fakeEmbedder{}
+	embedder := WrapEmbedder(emb, limiter, "ark")
+	other := WrapEmbedder(&fakeEmbedder{}, limiter, "openai")
+
+	generated := make(chan error, 1)
+	go func() {
+		_, err := cm.Generate(context.Background(), []*schema.Message{schema.UserMessage("你好")})
+		generated <- err
+	}()
+	<-inner.started
+
+	// 同一个 key 的 Embedder 要等 ChatModel 的调用结束
+	embedded := make(chan error, 1)
+	go func() {
+		_, err := embedder.EmbedStrings(context.Background(), []string{"文本"})
+		embedded <- err
+	}()
+	// 其他 key 不受影响
+	if err, ok := waitErr(t, time.Second, func() error {
+		_, err := other.EmbedStrings(context.Background(), []string{"文本"})
+		return err
+	}); !ok || err != nil {
+		t.Fatalf("其他 key 的调用不应排队: ok=%v err=%v", ok, err)
+	}
+	select {
+	case <-embedded:
+		t.Fatal("并发名额被占用时 Embedder 不应执行")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(inner.release)
+	if err := <-generated; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-embedded; err != nil {
+		t.Fatal(err)
+	}
+	if emb.calls != 1 {
+		t.Errorf("Embedder 调用 %d 次", emb.calls)
+	}
+}
+
+func TestStreamHoldsSlotUntilClosed(t *testing.T) {
+	limiter := New()
+	limiter.SetLimits("ark", Limits{MaxInFlight: 1})
+	inner := newBlockingModel()
+	cm := WrapChatModel(inner, limiter, "ark")
+	embedder := WrapEmbedder(&fakeEmbedder{}, limiter, "ark")
+
+	sr, err := cm.Stream(context.Background(), []*schema.Message{schema.UserMessage("你好")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	embed := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := embedder.EmbedStrings(ctx, []string{"文本"})
+		return err
+	}
+	if err := embed(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("流未读完时应等待并发名额，得到 %v", err)
+	}
+
+	var sb strings.Builder
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		sb.WriteString(chunk.Content)
+	}
+	sr.Close()
+	if sb.String() != "好的" {
+		t.Errorf("流内容 = %q", sb.String())
+	}
+	if err, ok := waitErr(t, time.Second, embed); !ok || err != nil {
+		t.Errorf("流读完后应归还并发名额: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestSharedTokenBudget(t *testing.T) {
+	limiter := New()
+	limiter.SetLimits("ark", Limits{TokensPerMinute: 1000})
+	embedder := WrapEmbedder(&fakeEmbedder{}, limiter, "ark")
+	cm := WrapChatModel(newBlockingModel(), limiter, "ark")
+
+	// Embedder 用掉大部分 token 配额后，ChatModel 的请求要等配额恢复
+	if _, err := embedder.EmbedStrings(context.Background(), []string{strings.Repeat("字", 900)}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := cm.Generate(ctx, []*schema.Message{schema.UserMessage("你好")}, model.WithMaxTokens(500))
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "ark") {
+		t.Errorf("token 配额不足时应等待直到超时，得到 %v", err)
+	}
+}
+
+func TestAcquireOrderAndCancel(t *testing.T) {
+	limiter := New()
+	limiter.SetLimits("k", Limits{MaxInFlight: 1})
+	ctx := context.Background()
+
+	first, err := limiter.Acquire(ctx, "k", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 排队中被取消的调用不占用名额
+	cctx, cancel := context.WithCancel(ctx)
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := limiter.Acquire(cctx, "k", 0)
+		cancelled <- err
+	}()
+
+	order := make(chan int, 2)
+	for i := 1; i <= 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+		go func() {
+			p, err := limiter.Acquire(ctx, "k", 0)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- i
+			p.Release(0)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("取消等待应返回 context.Canceled，得到 %v", err)
+	}
+
+	first.Release(0)
+	first.Release(0) // 重复 Release 不应多归还名额
+	for want := 1; want <= 2; want++ {
+		select {
+		case got := <-order:
+			if got != want {
+				t.Errorf("第 %d 个获得名额的是 %d，应按到达顺序", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("等待名额超时")
+		}
+	}
+}
+
+func TestUnlimitedKey(t *testing.T) {
+	limiter := New()
+	for range 100 {
+		p, err := limiter.Acquire(context.Background(), "none", 1_000_000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer p.Release(0)
+	}
+}
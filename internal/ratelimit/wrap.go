@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/tokens"
+)
+
+// ChatModel 受限流控制的 ChatModel
+type ChatModel struct {
+	inner   model.BaseChatModel
+	limiter *Limiter
+	key     string
+}
+
+var _ model.ToolCallingChatModel = (*ChatModel)(nil)
+
+// WrapChatModel 用 limiter 中 key 的配额限制 inner
+func WrapChatModel(inner model.BaseChatModel, limiter *Limiter, key string) *ChatModel {
+	return &ChatModel{inner: inner, limiter: limiter, key: key}
+}
+
+func (m *ChatModel) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	permit, err := m.limiter.Acquire(ctx, m.key, estimate(in, opts))
+	if err != nil {
+		return nil, err
+	}
+	msg, err := m.inner.Generate(ctx, in, opts...)
+	permit.Release(totalTokens(msg))
+	return msg, err
+}
+
+func (m *ChatModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	permit, err := m.limiter.Acquire(ctx, m.key, estimate(in, opts))
+	if err != nil {
+		return nil, err
+	}
+	sr, err := m.inner.Stream(ctx, in, opts...)
+	if err != nil {
+		permit.Release(0)
+		return nil, err
+	}
+
+	// 流读完或被关闭时才归还并发名额
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		used := 0
+		defer func() {
+			sr.Close()
+			sw.Close()
+			permit.Release(used)
+		}()
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if n := totalTokens(chunk); n > 0 {
+				used = n
+			}
+			if closed := sw.Send(chunk, err); closed || err != nil {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (m *ChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	tc, ok := m.inner.(model.ToolCallingChatModel)
+	if !ok {
+		return nil, fmt.Errorf("ratelimit: 底层模型 %T 不支持 WithTools", m.inner)
+	}
+	inner, err := tc.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatModel{inner: inner, limiter: m.limiter, key: m.key}, nil
+}
+
+func (m *ChatModel) GetType() string {
+	return "RateLimit"
+}
+
+// IsCallbacksEnabled 回调由底层模型触发，排队时间不计入模型调用
+func (m *ChatModel) IsCallbacksEnabled() bool {
+	return components.IsCallbacksEnabled(m.inner)
+}
+
+// Embedder 受限流控制的 Embedder
+type Embedder struct {
+	inner   embedding.Embedder
+	limiter *Limiter
+	key     string
+}
+
+var _ embedding.Embedder = (*Embedder)(nil)
+
+// WrapEmbedder 用 limiter 中 key 的配额限制 inner
+func WrapEmbedder(inner embedding.Embedder, limiter *Limiter, key string) *Embedder {
+	return &Embedder{inner: inner, limiter: limiter, key: key}
+}
+
+func (e *Embedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	n := 0
+	for _, t := range texts {
+		n += tokens.Count(t)
+	}
+	permit, err := e.limiter.Acquire(ctx, e.key, n)
+	if err != nil {
+		return nil, err
+	}
+	defer permit.Release(0)
+	return e.inner.EmbedStrings(ctx, texts, opts...)
+}
+
+func (e *Embedder) GetType() string {
+	return "RateLimit"
+}
+
+func (e *Embedder) IsCallbacksEnabled() bool {
+	return components.IsCallbacksEnabled(e.inner)
+}
+
+// estimate 预估一次调用的 token 数：输入估算值加上 MaxTokens
+func estimate(in []*schema.Message, opts []model.Option) int {
+	n := 0
+	for _, m := range in {
+		n += tokens.Count(m.Content)
+		for _, tc := range m.ToolCalls {
+			n += tokens.Count(tc.Function.Arguments)
+		}
+	}
+	if o := model.GetCommonOptions(nil, opts...); o.MaxTokens != nil {
+		n += *o.MaxTokens
+	}
+	return n
+}
+
+func totalTokens(msg *schema.Message) int {
+	if msg == nil || msg.ResponseMeta == nil || msg.ResponseMeta.Usage == nil {
+		return 0
+	}
+	return msg.ResponseMeta.Usage.TotalTokens
+}
@@ -0,0 +1,224 @@
+package reasoning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ChatModel 把推理内容与回答分开，并在发送前去掉历史消息中的推理内容
+type ChatModel struct {
+	inner model.BaseChatModel
+}
+
+var _ model.ToolCallingChatModel = (*ChatModel)(nil)
+
+// Wrap 为 inner 增加推理内容处理，对非推理模型没有影响
+func Wrap(inner model.BaseChatModel) *ChatModel {
+	return &ChatModel{inner: inner}
+}
+
+func (m *ChatModel) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	msg, err := m.inner.Generate(ctx, Strip(in), opts...)
+	if err != nil || msg == nil {
+		return msg, err
+	}
+
+	out := *msg
+	if out.ReasoningContent == "" {
+		if r, answer := SplitThink(out.Content); r != "" {
+			out.ReasoningContent, out.Content = r, answer
+		}
+	}
+	out.ResponseMeta = withReasoningUsage(out.ResponseMeta, Of(&out))
+	return &out, nil
+}
+
+func (m *ChatModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	sr, err := m.inner.Stream(ctx, Strip(in), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer sr.Close()
+		defer sw.Close()
+
+		p := &thinkParser{}
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				if rest := p.flush(); rest != nil {
+					sw.Send(rest, nil)
+				}
+				return
+			}
+			if err != nil {
+				sw.Send(nil, err)
+				return
+			}
+			if c := p.next(chunk); c != nil {
+				if closed := sw.Send(c, nil); closed {
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (m *ChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	tc, ok := m.inner.(model.ToolCallingChatModel)
+	if !ok {
+		return nil, fmt.Errorf("reasoning: 底层模型 %T 不支持 WithTools", m.inner)
+	}
+	inner, err := tc.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatModel{inner: inner}, nil
+}
+
+func (m *ChatModel) GetType() string {
+	return "Reasoning"
+}
+
+// IsCallbacksEnabled 回调由底层模型触发
+func (m *ChatModel) IsCallbacksEnabled() bool {
+	return components.IsCallbacksEnabled(m.inner)
+}
+
+// withReasoningUsage 返回补上推理 token 估算值的 ResponseMeta 副本
+func withReasoningUsage(meta *schema.ResponseMeta, reasoning string) *schema.ResponseMeta {
+	if meta == nil || meta.Usage == nil || reasoning == "" || meta.Usage.CompletionTokensDetails.ReasoningTokens > 0 {
+		return meta
+	}
+	cm, u := *meta, *meta.Usage
+	FillUsage(&u, reasoning)
+	cm.Usage = &u
+	return &cm
+}
+
+type thinkState int
+
+const (
+	// stateStart 还不确定内容是否以 <think> 开头
+	stateStart thinkState = iota
+	// stateThinking 在 <think> 标签内
+	stateThinking
+	// stateTrim 刚结束 </think>，跳过回答前的空白
+	stateTrim
+	stateAnswer
+)
+
+// thinkParser 把流式内容中的 <think>...</think> 转成 ReasoningContent 分片
+type thinkParser struct {
+	state thinkState
+	// pending 还不能确定归属的内容：开头的 <think> 或者可能是 </think> 前缀的结尾
+	pending   string
+	reasoning strings.Builder
+}
+
+// next 处理一个分片，返回要输出的分片，没有需要输出的内容时返回 nil
+func (p *thinkParser) next(chunk *schema.Message) *schema.Message {
+	if chunk == nil {
+		return nil
+	}
+	out := *chunk
+	if chunk.ReasoningContent != "" && p.state == stateStart {
+		// 模型原生输出推理内容，不需要解析标签
+		p.state = stateAnswer
+	}
+
+	var reasoning, content string
+	if chunk.Content != "" {
+		reasoning, content = p.feed(chunk.Content)
+	}
+	out.ReasoningContent = chunk.ReasoningContent + reasoning
+	out.Content = content
+	p.reasoning.WriteString(out.ReasoningContent)
+	out.ResponseMeta = withReasoningUsage(out.ResponseMeta, p.reasoning.String())
+
+	if out.Content == "" && out.ReasoningContent == "" && chunk.Content != "" &&
+		len(out.ToolCalls) == 0 && out.ResponseMeta == nil && len(out.Extra) == 0 {
+		// 内容全部暂存，等待下一个分片
+		return nil
+	}
+	return &out
+}
+
+// feed 处理一段内容，返回其中的推理内容和回答内容
+func (p *thinkParser) feed(s string) (reasoning, content string) {
+	s = p.pending + s
+	p.pending = ""
+	for s != "" {
+		switch p.state {
+		case stateStart:
+			trimmed := strings.TrimLeft(s, " \t\r\n")
+			switch {
+			case strings.HasPrefix(trimmed, thinkOpen):
+				p.state = stateThinking
+				s = trimmed[len(thinkOpen):]
+			case strings.HasPrefix(thinkOpen, trimmed):
+				// 可能是不完整的 <think>
+				p.pending = s
+				return reasoning, content
+			default:
+				p.state = stateAnswer
+			}
+		case stateThinking:
+			if end := strings.Index(s, thinkClose); end >= 0 {
+				reasoning += s[:end]
+				s = s[end+len(thinkClose):]
+				p.state = stateTrim
+				continue
+			}
+			// 保留可能是 </think> 开头的结尾部分
+			keep := partialSuffix(s, thinkClose)
+			reasoning += s[:len(s)-keep]
+			p.pending = s[len(s)-keep:]
+			return reasoning, content
+		case stateTrim:
+			s = strings.TrimLeft(s, " \t\r\n")
+			if s != "" {
+				p.state = stateAnswer
+			}
+		case stateAnswer:
+			content += s
+			s = ""
+		}
+	}
+	return reasoning, content
+}
+
+// flush 流结束时输出暂存的内容
+func (p *thinkParser) flush() *schema.Message {
+	if p.pending == "" {
+		return nil
+	}
+	msg := &schema.Message{Role: schema.Assistant}
+	if p.state == stateThinking {
+		msg.ReasoningContent = p.pending
+	} else {
+		msg.Content = p.pending
+	}
+	p.pending = ""
+	return msg
+}
+
+// partialSuffix s 的结尾与 tag 开头重合的最大长度
+func partialSuffix(s, tag string) int {
+	for n := min(len(s), len(tag)-1); n > 0; n-- {
+		if strings.HasSuffix(s, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+// Package reasoning 处理推理模型（如 deepseek-reasoner）的思考过程。
+//
+// 推理内容统一放在 schema.Message.ReasoningContent 中，与最终回答 Content 分开：
+// 把思考过程写在 Content 开头 <think>...</think> 中的模型，由 Wrap 在 Generate 和 Stream 中拆分出来。
+// 推理内容不应再发回模型（DeepSeek 会拒绝带 reasoning_content 的历史消息），Wrap 会自动去掉；
+// 提供方没有单独上报推理 token 时，用 tokens.Approx 按推理内容估算。
+package reasoning
+
+import (
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/tokens"
+)
+
+// deepseekExtraKey deepseek 组件在 Extra 中重复保存推理内容的键
+const deepseekExtraKey = "_eino_deepseek_reasoning_content"
+
+const (
+	thinkOpen  = "<think>"
+	thinkClose = "</think>"
+)
+
+// Of 返回消息的推理内容
+func Of(msg *schema.Message) string {
+	if msg == nil {
+		return ""
+	}
+	if msg.ReasoningContent != "" {
+		return msg.ReasoningContent
+	}
+	s, _ := msg.Extra[deepseekExtraKey].(string)
+	return s
+}
+
+// Text 返回消息的推理内容，包括写在 Content 开头 <think> 标签中的内容
+func Text(msg *schema.Message) string {
+	if r := Of(msg); r != "" || msg == nil {
+		return r
+	}
+	r, _ := SplitThink(msg.Content)
+	return r
+}
+
+// Strip 返回去掉推理内容的消息列表，带推理内容的消息会被复制，不修改原消息
+func Strip(msgs []*schema.Message) []*schema.Message {
+	var out []*schema.Message
+	for i, m := range msgs {
+		if Of(m) == "" {
+			if out != nil {
+				out = append(out, m)
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]*schema.Message, 0, len(msgs)), msgs[:i]...)
+		}
+		cm := *m
+		cm.ReasoningContent = ""
+		if _, ok := m.Extra[deepseekExtraKey]; ok {
+			cm.Extra = make(map[string]any, len(m.Extra)-1)
+			for k, v := range m.Extra {
+				if k != deepseekExtraKey {
+					cm.Extra[k] = v
+				}
+			}
+		}
+		out = append(out, &cm)
+	}
+	if out == nil {
+		return msgs
+	}
+	return out
+}
+
+// SplitThink 拆分以 <think>...</think> 开头的内容，没有思考标签时 reasoning 为空
+func SplitThink(content string) (reasoning, answer string) {
+	trimmed := strings.TrimLeft(content, " \t\r\n")
+	if !strings.HasPrefix(trimmed, thinkOpen) {
+		return "", content
+	}
+	rest := trimmed[len(thinkOpen):]
+	end := strings.Index(rest, thinkClose)
+	if end < 0 {
+		// 没有结束标签（例如输出被截断），全部视为推理内容
+		return strings.TrimSpace(rest), ""
+	}
+	return strings.TrimSpace(rest[:end]), strings.TrimLeft(rest[end+len(thinkClose):], " \t\r\n")
+}
+
+// Tokens 估算推理 token 数，不超过输出 token 数 completion（为 0 时不限制）
+func Tokens(reasoning string, completion int) int {
+	n := tokens.Approx.Count(reasoning)
+	if completion > 0 {
+		n = min(n, completion)
+	}
+	return n
+}
+
+// FillUsage 提供方没有上报推理 token 时，按推理内容估算并写入 u
+func FillUsage(u *schema.TokenUsage, reasoning string) {
+	if u == nil || reasoning == "" || u.CompletionTokensDetails.ReasoningTokens > 0 {
+		return
+	}
+	u.CompletionTokensDetails.ReasoningTokens = Tokens(reasoning, u.CompletionTokens)
+}
@@ -0,0 +1,237 @@
+// Package replay 提供可录制 / 回放的 ChatModel。
+//
+// 录制模式下请求真实模型，把 Generate / Stream 的完整交互（包括工具调用、usage 和流式分片）
+// 写入录制目录；回放模式下按消息内容匹配录制记录并确定性地返回，不需要 API Key，
+// 可以在 CI 中运行 Chain、ReAct Agent 和 ADK Agent。
+//
+// 通过 chatmodel.New 创建的模型会读取 EINO_REPLAY_MODE / EINO_REPLAY_DIR 自动套上这一层：
+//
+//	EINO_REPLAY_MODE=record go run 5-ReAct/1_simple_react_agent.go   # 录制
+//	EINO_REPLAY_MODE=replay go run 5-ReAct/1_simple_react_agent.go   # 离线回放
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/modelcb"
+)
+
+// Mode 录制 / 回放模式
+type Mode string
+
+const (
+	// ModeOff 不启用，直接使用底层模型
+	ModeOff Mode = ""
+	// ModeRecord 总是请求底层模型，并覆盖录制记录
+	ModeRecord Mode = "record"
+	// ModeReplay 只使用录制记录，找不到时返回 ErrNoFixture
+	ModeReplay Mode = "replay"
+	// ModeAuto 有录制记录时回放，否则请求底层模型并录制
+	ModeAuto Mode = "auto"
+)
+
+const (
+	// EnvMode 设置录制 / 回放模式的环境变量
+	EnvMode = "EINO_REPLAY_MODE"
+	// EnvDir 设置录制目录的环境变量
+	EnvDir = "EINO_REPLAY_DIR"
+
+	// DefaultDir 默认录制目录（相对于运行目录）
+	DefaultDir = "testdata/replay"
+)
+
+// ModeFromEnv 读取 EINO_REPLAY_MODE
+func ModeFromEnv() (Mode, error) {
+	mode := Mode(os.Getenv(EnvMode))
+	switch mode {
+	case ModeOff, ModeRecord, ModeReplay, ModeAuto:
+		return mode, nil
+	default:
+		return ModeOff, fmt.Errorf("无效的 %s=%q，可选: record, replay, auto", EnvMode, mode)
+	}
+}
+
+// DirFromEnv 读取 EINO_REPLAY_DIR，未设置时返回 DefaultDir
+func DirFromEnv() string {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir
+	}
+	return DefaultDir
+}
+
+// Config 录制 / 回放配置
+type Config struct {
+	Mode Mode
+	// Dir 录制目录，同一个目录在进程内共享回放进度
+	Dir string
+}
+
+// ChatModel 可录制 / 回放的 ChatModel
+type ChatModel struct {
+	inner model.ToolCallingChatModel // 回放模式下可以为 nil
+	store *Store
+	mode  Mode
+	tools []*schema.ToolInfo
+}
+
+var _ model.ToolCallingChatModel = (*ChatModel)(nil)
+
+// New 包装 inner，回放模式下 inner 可以为 nil
+func New(inner model.ToolCallingChatModel, cfg *Config) (*ChatModel, error) {
+	if cfg == nil || cfg.Mode == ModeOff {
+		return nil, errors.New("replay: 未指定录制 / 回放模式")
+	}
+	if inner == nil && cfg.Mode != ModeReplay {
+		return nil, fmt.Errorf("replay: %s 模式需要底层模型", cfg.Mode)
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return &ChatModel{
+		inner: inner,
+		store: OpenStore(dir),
+		mode:  cfg.Mode,
+	}, nil
+}
+
+func (m *ChatModel) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	tools := m.toolNames(opts)
+	key := Key(in, tools)
+
+	if m.shouldReplay(key) {
+		return modelcb.Generate(ctx, m.GetType(), in, opts, func(ctx context.Context) (*schema.Message, error) {
+			ex, err := m.store.Next(key)
+			if err != nil {
+				return nil, err
+			}
+			return ex.message()
+		})
+	}
+
+	gen := m.inner.Generate
+	if !components.IsCallbacksEnabled(m.inner) {
+		gen = func(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			return modelcb.Generate(ctx, m.GetType(), in, opts, func(ctx context.Context) (*schema.Message, error) {
+				return m.inner.Generate(ctx, in, opts...)
+			})
+		}
+	}
+
+	out, err := gen(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	err = m.store.Append(key, &Exchange{Kind: kindGenerate, Input: in, Tools: tools, Output: out})
+	if err != nil {
+		log.Printf("replay: 保存录制记录失败: %v", err)
+	}
+	return out, nil
+}
+
+func (m *ChatModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	tools := m.toolNames(opts)
+	key := Key(in, tools)
+
+	if m.shouldReplay(key) {
+		return modelcb.Stream(ctx, m.GetType(), in, opts, func(ctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+			ex, err := m.store.Next(key)
+			if err != nil {
+				return nil, err
+			}
+			return schema.StreamReaderFromArray(ex.chunks()), nil
+		})
+	}
+
+	stream := m.inner.Stream
+	if !components.IsCallbacksEnabled(m.inner) {
+		stream = func(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+			return modelcb.Stream(ctx, m.GetType(), in, opts, func(ctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+				return m.inner.Stream(ctx, in, opts...)
+			})
+		}
+	}
+
+	sr, err := stream(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer sr.Close()
+		defer sw.Close()
+
+		var chunks []*schema.Message
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				err = m.store.Append(key, &Exchange{Kind: kindStream, Input: in, Tools: tools, Chunks: chunks})
+				if err != nil {
+					log.Printf("replay: 保存录制记录失败: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				sw.Send(nil, err)
+				return
+			}
+			chunks = append(chunks, chunk)
+			if closed := sw.Send(chunk, nil); closed {
+				// 调用方提前关闭，流不完整，不录制
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (m *ChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	nm := *m
+	nm.tools = tools
+	if m.inner != nil {
+		inner, err := m.inner.WithTools(tools)
+		if err != nil {
+			return nil, err
+		}
+		nm.inner = inner
+	}
+	return &nm, nil
+}
+
+func (m *ChatModel) GetType() string {
+	return "Replay"
+}
+
+func (m *ChatModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+func (m *ChatModel) shouldReplay(key string) bool {
+	switch m.mode {
+	case ModeReplay:
+		return true
+	case ModeAuto:
+		return m.store.Has(key)
+	default:
+		return false
+	}
+}
+
+// toolNames 单次调用通过 model.WithTools 传入的工具优先于 WithTools 绑定的工具
+func (m *ChatModel) toolNames(opts []model.Option) []string {
+	tools := m.tools
+	if o := model.GetCommonOptions(nil, opts...); o.Tools != nil {
+		tools = o.Tools
+	}
+	return namesOf(tools)
+}
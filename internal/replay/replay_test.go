@@ -0,0 +1,181 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// scripted 按顺序返回带 usage 的回答，并记录被调用的次数
+type scripted struct {
+	replies []string
+	calls   int
+}
+
+func (m *scripted) reply() *schema.Message {
+	msg := schema.AssistantMessage(m.replies[m.calls%len(m.replies)], nil)
+	msg.ResponseMeta = &schema.ResponseMeta{FinishReason: "stop", Usage: &schema.TokenUsage{TotalTokens: 7}}
+	m.calls++
+	return msg
+}
+
+func (m *scripted) Generate(context.Context, []*schema.Message, ...model.Option) (*schema.Message, error) {
+	return m.reply(), nil
+}
+
+// Stream 把回答按字符拆成分片，usage 放在最后一个分片上
+func (m *scripted) Stream(context.Context, []*schema.Message, ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg := m.reply()
+	var chunks []*schema.Message
+	for _, r := range msg.Content {
+		chunks = append(chunks, schema.AssistantMessage(string(r), nil))
+	}
+	chunks = append(chunks, &schema.Message{Role: schema.Assistant, ResponseMeta: msg.ResponseMeta})
+	return schema.StreamReaderFromArray(chunks), nil
+}
+
+func (m *scripted) WithTools([]*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+// reopen 丢弃进程内缓存的 Store，模拟新进程从磁盘读取录制文件
+func reopen(dir string) {
+	storesMu.Lock()
+	delete(stores, dir)
+	storesMu.Unlock()
+}
+
+func readAll(t *testing.T, sr *schema.StreamReader[*schema.Message]) []*schema.Message {
+	t.Helper()
+	defer sr.Close()
+	var out []*schema.Message
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			return out
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, chunk)
+	}
+}
+
+func TestGenerateRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	in := []*schema.Message{schema.SystemMessage("你是助手"), schema.UserMessage("你好")}
+
+	inner := &scripted{replies: []string{"第一次", "第二次"}}
+	rec, err := New(inner, &Config{Mode: ModeRecord, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"第一次", "第二次"} {
+		msg, err := rec.Generate(ctx, in)
+		if err != nil || msg.Content != want {
+			t.Fatalf("录制 Generate = %v, %v", msg, err)
+		}
+	}
+
+	reopen(dir)
+	play, err := New(nil, &Config{Mode: ModeReplay, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同一请求按录制顺序返回，次数用完后重复最后一条
+	for _, want := range []string{"第一次", "第二次", "第二次"} {
+		msg, err := play.Generate(ctx, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Content != want || msg.ResponseMeta == nil || msg.ResponseMeta.Usage.TotalTokens != 7 {
+			t.Errorf("回放 Generate = %q %+v，期望 %q 且保留 usage", msg.Content, msg.ResponseMeta, want)
+		}
+	}
+	if inner.calls != 2 {
+		t.Errorf("回放时不应请求底层模型，共调用 %d 次", inner.calls)
+	}
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	in := []*schema.Message{schema.UserMessage("讲个笑话")}
+
+	rec, err := New(&scripted{replies: []string{"哈哈"}}, &Config{Mode: ModeRecord, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr, err := rec.Stream(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 录制在底层流结束后写盘，之后才关闭返回给调用方的流，所以读完时已经写好
+	recorded := readAll(t, sr)
+
+	reopen(dir)
+	play, err := New(nil, &Config{Mode: ModeReplay, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr, err = play.Stream(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := readAll(t, sr)
+	if len(replayed) != len(recorded) {
+		t.Fatalf("回放 %d 个分片，录制了 %d 个", len(replayed), len(recorded))
+	}
+	for i := range recorded {
+		if replayed[i].Content != recorded[i].Content {
+			t.Errorf("分片 %d = %q，期望 %q", i, replayed[i].Content, recorded[i].Content)
+		}
+	}
+
+	// 流式录制的记录也可以用 Generate 回放，分片合并为完整消息
+	msg, err := play.Generate(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Content != "哈哈" || msg.ResponseMeta == nil || msg.ResponseMeta.Usage == nil || msg.ResponseMeta.Usage.TotalTokens != 7 {
+		t.Errorf("合并后的消息 = %q %+v", msg.Content, msg.ResponseMeta)
+	}
+}
+
+func TestReplayMiss(t *testing.T) {
+	ctx := context.Background()
+	play, err := New(nil, &Config{Mode: ModeReplay, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := play.Generate(ctx, []*schema.Message{schema.UserMessage("没录过")}); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("Generate err = %v，期望 ErrNoFixture", err)
+	}
+	if _, err := play.Stream(ctx, []*schema.Message{schema.UserMessage("没录过")}); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("Stream err = %v，期望 ErrNoFixture", err)
+	}
+
+	// 工具列表不同也算不同的请求
+	dir := t.TempDir()
+	rec, err := New(&scripted{replies: []string{"好"}}, &Config{Mode: ModeRecord, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := []*schema.Message{schema.UserMessage("查天气")}
+	if _, err := rec.Generate(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	play, err = New(nil, &Config{Mode: ModeReplay, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := []*schema.ToolInfo{{Name: "get_weather"}}
+	if _, err := play.Generate(ctx, in, model.WithTools(tools)); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("带工具的请求 err = %v，期望 ErrNoFixture", err)
+	}
+}
@@ -0,0 +1,233 @@
+package replay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// ErrNoFixture 回放模式下找不到匹配的录制记录
+var ErrNoFixture = errors.New("replay: 没有匹配的录制记录")
+
+const (
+	kindGenerate = "generate"
+	kindStream   = "stream"
+)
+
+// Exchange 一次录制下来的模型调用
+type Exchange struct {
+	Kind   string            `json:"kind"` // generate 或 stream
+	Input  []*schema.Message `json:"input"`
+	Tools  []string          `json:"tools,omitempty"`
+	Output *schema.Message   `json:"output,omitempty"` // Generate 的完整响应
+	Chunks []*schema.Message `json:"chunks,omitempty"` // Stream 的原始分片，包括工具调用增量和 usage
+}
+
+// message 返回完整响应，流式录制的记录会先合并分片
+func (e *Exchange) message() (*schema.Message, error) {
+	if e.Kind == kindStream {
+		return schema.ConcatMessages(e.Chunks)
+	}
+	return e.Output, nil
+}
+
+// chunks 返回流式分片，非流式录制的记录作为单个分片返回
+func (e *Exchange) chunks() []*schema.Message {
+	if e.Kind == kindStream {
+		return e.Chunks
+	}
+	return []*schema.Message{e.Output}
+}
+
+// fixture 一个录制文件，同一个 key 的多次调用按顺序保存
+type fixture struct {
+	Key       string      `json:"key"`
+	Exchanges []*Exchange `json:"exchanges"`
+}
+
+// Store 管理一个目录下的录制文件，同一个目录在进程内共享一个 Store
+type Store struct {
+	dir string
+
+	mu       sync.Mutex
+	cache    map[string]*fixture
+	cursor   map[string]int
+	recorded map[string]bool // 本进程内已重新录制过的 key
+}
+
+var (
+	storesMu sync.Mutex
+	stores   = map[string]*Store{}
+)
+
+// OpenStore 返回 dir 对应的 Store
+func OpenStore(dir string) *Store {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	if s, ok := stores[dir]; ok {
+		return s
+	}
+	s := &Store{
+		dir:      dir,
+		cache:    map[string]*fixture{},
+		cursor:   map[string]int{},
+		recorded: map[string]bool{},
+	}
+	stores[dir] = s
+	return s
+}
+
+// Key 根据消息内容和可用工具计算录制记录的 key
+//
+// 只使用角色、内容、名称和工具调用的函数名与参数；ResponseMeta、Extra、推理内容以及
+// 每次请求都会变化的工具调用 ID 不参与匹配。
+func Key(in []*schema.Message, tools []string) string {
+	type call struct {
+		Name string `json:"name"`
+		Args string `json:"args"`
+	}
+	type msg struct {
+		Role    schema.RoleType `json:"role"`
+		Content string          `json:"content"`
+		Name    string          `json:"name,omitempty"`
+		Calls   []call          `json:"calls,omitempty"`
+	}
+
+	norm := struct {
+		Messages []msg    `json:"messages"`
+		Tools    []string `json:"tools,omitempty"`
+	}{Tools: tools}
+	for _, m := range in {
+		if m == nil {
+			continue
+		}
+		nm := msg{Role: m.Role, Content: m.Content, Name: m.Name}
+		for _, tc := range m.ToolCalls {
+			nm.Calls = append(nm.Calls, call{Name: tc.Function.Name, Args: tc.Function.Arguments})
+		}
+		norm.Messages = append(norm.Messages, nm)
+	}
+
+	b, _ := json.Marshal(norm)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:16])
+}
+
+func namesOf(tools []*schema.ToolInfo) []string {
+	names := make([]string, 0, len(tools))
+	for _, t := range tools {
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
+
+// load 读取 key 对应的录制文件，调用方需持有锁
+func (s *Store) load(key string) (*fixture, error) {
+	if f, ok := s.cache[key]; ok {
+		return f, nil
+	}
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, err
+	}
+	f := &fixture{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("解析录制文件 %s 失败: %w", s.path(key), err)
+	}
+	s.cache[key] = f
+	return f, nil
+}
+
+// Has 判断 key 是否有可以回放的记录（本进程新录制的不算）
+func (s *Store) Has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.recorded[key] {
+		return false
+	}
+	f, err := s.load(key)
+	return err == nil && len(f.Exchanges) > 0
+}
+
+// Next 按顺序返回 key 的下一条记录，超出录制次数后重复最后一条
+func (s *Store) Next(key string) (*Exchange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.load(key)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(f.Exchanges) == 0) {
+		return nil, fmt.Errorf("%w: key=%s, 目录=%s", ErrNoFixture, key, s.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	i := s.cursor[key]
+	if i >= len(f.Exchanges) {
+		i = len(f.Exchanges) - 1
+	}
+	s.cursor[key] = i + 1
+	return clone(f.Exchanges[i])
+}
+
+// Append 追加一条录制记录并写盘；本进程第一次录制某个 key 时会覆盖旧记录
+func (s *Store) Append(key string, ex *Exchange) error {
+	ex, err := clone(ex)
+	if err != nil {
+		return fmt.Errorf("序列化录制记录失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := &fixture{Key: key}
+	if s.recorded[key] {
+		f = s.cache[key]
+	}
+	f.Exchanges = append(f.Exchanges, ex)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化录制记录失败: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	tmp := s.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path(key)); err != nil {
+		return err
+	}
+
+	s.cache[key] = f
+	s.recorded[key] = true
+	return nil
+}
+
+// clone 通过 JSON 深拷贝，避免调用方修改缓存中的记录
+func clone(ex *Exchange) (*Exchange, error) {
+	b, err := json.Marshal(ex)
+	if err != nil {
+		return nil, err
+	}
+	out := &Exchange{}
+	if err := json.Unmarshal(b, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
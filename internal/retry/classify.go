@@ -0,0 +1,120 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	deepseekapi "github.com/cohesion-org/deepseek-go"
+)
+
+// Classifier 判断错误是否值得重试
+type Classifier func(err error) bool
+
+// RetryAfterError 由携带服务端建议等待时间的错误实现
+type RetryAfterError interface {
+	error
+	RetryAfter() time.Duration
+}
+
+type retryAfterError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryAfterError) Error() string             { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error             { return e.err }
+func (e *retryAfterError) RetryAfter() time.Duration { return e.after }
+
+// WithRetryAfter 为错误附加服务端返回的 Retry-After
+func WithRetryAfter(err error, after time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryAfterError{err: err, after: after}
+}
+
+// ParseRetryAfter 解析 HTTP Retry-After 头，支持秒数和 HTTP 日期两种格式
+func ParseRetryAfter(header string) (time.Duration, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// retryAfter 从错误链中取出 Retry-After
+func retryAfter(err error) (time.Duration, bool) {
+	var ra RetryAfterError
+	if errors.As(err, &ra) {
+		return ra.RetryAfter(), true
+	}
+	return 0, false
+}
+
+// StatusCode 从错误链中取出 HTTP 状态码
+func StatusCode(err error) (int, bool) {
+	var apiErr *deepseekapi.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode, true
+	}
+	var apiErrVal deepseekapi.APIError
+	if errors.As(err, &apiErrVal) {
+		return apiErrVal.StatusCode, true
+	}
+	return 0, false
+}
+
+// IsRetryable 默认的错误分类：
+//
+//	可重试：429、408、5xx、Retry-After、网络错误（超时、DNS、连接被拒绝或重置）、意外断开
+//	不可重试：调用方取消、其它 4xx（认证失败、参数错误、余额不足等）、
+//	证书校验失败、不支持的协议等永久错误、无法识别的错误
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if _, ok := retryAfter(err); ok {
+		return true
+	}
+	if code, ok := StatusCode(err); ok {
+		return code == http.StatusTooManyRequests ||
+			code == http.StatusRequestTimeout ||
+			code >= http.StatusInternalServerError
+	}
+	// http.Client 把所有错误都包装成 url.Error，它本身也实现了 net.Error，所以按内层错误判断
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	// 单次请求超时（例如 ChatModelConfig.Timeout）可以重试，调用方 ctx 是否到期由 Retry 自己判断
+	return errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	deepseekapi "github.com/cohesion-org/deepseek-go"
+)
+
+func TestIsRetryable(t *testing.T) {
+	// 证书不受信任：httptest 的自签名证书
+	tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer tlsSrv.Close()
+	_, tlsErr := http.Get(tlsSrv.URL)
+
+	_, schemeErr := http.Get("ftp://example.com/")
+
+	// 连接被拒绝：监听后立即关闭的端口
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	_, refusedErr := http.Get("http://" + addr)
+	if tlsErr == nil || schemeErr == nil || refusedErr == nil {
+		t.Fatalf("构造传输错误失败: %v / %v / %v", tlsErr, schemeErr, refusedErr)
+	}
+
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"429", &deepseekapi.APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"503", &deepseekapi.APIError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"401", &deepseekapi.APIError{StatusCode: http.StatusUnauthorized}, false},
+		{"Retry-After", WithRetryAfter(errors.New("限流"), 0), true},
+		{"调用方取消", fmt.Errorf("请求失败: %w", context.Canceled), false},
+		{"单次请求超时", context.DeadlineExceeded, true},
+		{"意外断开", fmt.Errorf("读取响应: %w", io.ErrUnexpectedEOF), true},
+		{"连接被拒绝", refusedErr, true},
+		{"证书校验失败", tlsErr, false},
+		{"不支持的协议", schemeErr, false},
+		{"无法识别", errors.New("未知错误"), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := IsRetryable(c.err); got != c.want {
+				t.Errorf("IsRetryable(%v) = %v，期望 %v", c.err, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Package retry 为任意 BaseChatModel 提供带退避的重试。
+//
+// 与 1-ChatModel/6_err_handler.go 中最初的 generateWithRetry 相比：
+//   - 只重试可恢复的错误（429、5xx、网络错误），认证失败、参数错误直接返回
+//   - 优先使用服务端的 Retry-After（HTTP 客户端需要使用 Transport），指数退避加随机抖动
+//   - 等待期间响应 ctx 取消，等待时间超过 ctx 截止时间时不再重试
+//   - Stream 在收到第一个分片之前失败也会重试
+//   - 返回的 *Error 保留每次尝试的错误，errors.Is / errors.As 仍然可用
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/streamx"
+)
+
+// Config 重试配置，零值字段使用默认值
+type Config struct {
+	// MaxAttempts 最大尝试次数（包括第一次），默认 3
+	MaxAttempts int
+	// BaseDelay 第一次重试前的基础等待时间，默认 500ms
+	BaseDelay time.Duration
+	// MaxDelay 单次等待的上限，默认 30s
+	MaxDelay time.Duration
+	// Retryable 错误分类，默认 IsRetryable
+	Retryable Classifier
+	// OnRetry 每次重试前调用，可用于打印日志
+	OnRetry func(attempt int, err error, delay time.Duration)
+}
+
+func (c *Config) withDefaults() Config {
+	out := Config{}
+	if c != nil {
+		out = *c
+	}
+	if out.MaxAttempts <= 0 {
+		out.MaxAttempts = 3
+	}
+	if out.BaseDelay <= 0 {
+		out.BaseDelay = 500 * time.Millisecond
+	}
+	if out.MaxDelay <= 0 {
+		out.MaxDelay = 30 * time.Second
+	}
+	if out.Retryable == nil {
+		out.Retryable = IsRetryable
+	}
+	return out
+}
+
+// Error 重试最终失败时返回的错误，包含每次尝试的错误
+type Error struct {
+	Attempts int
+	Errs     []error
+	// Stopped 因 ctx 取消或剩余时间不足而提前停止时的原因
+	Stopped error
+}
+
+func (e *Error) Error() string {
+	last := e.Errs[len(e.Errs)-1]
+	if e.Stopped != nil {
+		return fmt.Sprintf("尝试 %d 次后停止重试（%v）: %v", e.Attempts, e.Stopped, last)
+	}
+	return fmt.Sprintf("尝试 %d 次后失败: %v", e.Attempts, last)
+}
+
+// Unwrap 让 errors.Is / errors.As 可以匹配任意一次尝试的错误以及停止原因
+func (e *Error) Unwrap() []error {
+	if e.Stopped != nil {
+		return append(e.Errs[:len(e.Errs):len(e.Errs)], e.Stopped)
+	}
+	return e.Errs
+}
+
+// ChatModel 带重试的 ChatModel
+type ChatModel struct {
+	inner model.BaseChatModel
+	cfg   Config
+}
+
+var _ model.ToolCallingChatModel = (*ChatModel)(nil)
+
+// New 为 inner 增加重试
+func New(inner model.BaseChatModel, cfg *Config) *ChatModel {
+	return &ChatModel{inner: inner, cfg: cfg.withDefaults()}
+}
+
+func (m *ChatModel) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	var out *schema.Message
+	err := m.do(ctx, func() error {
+		var err error
+		out, err = m.inner.Generate(ctx, in, opts...)
+		return err
+	})
+	return out, err
+}
+
+func (m *ChatModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	var out *schema.StreamReader[*schema.Message]
+	err := m.do(ctx, func() error {
+		sr, err := m.inner.Stream(ctx, in, opts...)
+		if err != nil {
+			return err
+		}
+		// 第一个分片到达之前的错误（连接中断、限流等）同样重试
+		out, err = streamx.Peek(sr, nil)
+		return err
+	})
+	return out, err
+}
+
+func (m *ChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	tc, ok := m.inner.(model.ToolCallingChatModel)
+	if !ok {
+		return nil, fmt.Errorf("retry: 底层模型 %T 不支持 WithTools", m.inner)
+	}
+	inner, err := tc.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatModel{inner: inner, cfg: m.cfg}, nil
+}
+
+func (m *ChatModel) GetType() string {
+	return "Retry"
+}
+
+// IsCallbacksEnabled 回调由底层模型在每次尝试时触发
+func (m *ChatModel) IsCallbacksEnabled() bool {
+	return components.IsCallbacksEnabled(m.inner)
+}
+
+// do 执行 fn，按配置重试
+func (m *ChatModel) do(ctx context.Context, fn func() error) error {
+	var errs []error
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+
+		// 调用方的 ctx 已经结束：不再重试
+		if ctx.Err() != nil || !m.cfg.Retryable(err) || attempt >= m.cfg.MaxAttempts {
+			return &Error{Attempts: attempt, Errs: errs}
+		}
+
+		delay := m.backoff(attempt, err)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return &Error{Attempts: attempt, Errs: errs, Stopped: context.DeadlineExceeded}
+		}
+		if m.cfg.OnRetry != nil {
+			m.cfg.OnRetry(attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &Error{Attempts: attempt, Errs: errs, Stopped: ctx.Err()}
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff 计算第 attempt 次失败后的等待时间
+func (m *ChatModel) backoff(attempt int, err error) time.Duration {
+	// 服务端给出的 Retry-After 优先，额外加少量抖动避免同时醒来
+	if after, ok := retryAfter(err); ok {
+		return after + rand.N(m.cfg.BaseDelay)
+	}
+
+	// 指数退避，在 [ceiling/2, ceiling] 之间随机抖动
+	ceiling := m.cfg.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > m.cfg.MaxDelay {
+		ceiling = m.cfg.MaxDelay
+	}
+	return ceiling/2 + rand.N(ceiling/2+1)
+}
@@ -0,0 +1,45 @@
+package retry
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	deepseekapi "github.com/cohesion-org/deepseek-go"
+)
+
+// maxErrorBody 转换为错误时最多读取的响应体字节数
+const maxErrorBody = 4 << 10
+
+// Transport 包装 HTTP Transport，base 为 nil 时使用 http.DefaultTransport。
+// SDK 解析错误响应时会丢掉响应头，这里在 429、503 响应带有 Retry-After 时直接返回用 WithRetryAfter 包装的 APIError，
+// 错误经过 SDK 层层包装之后 Retry 仍能按服务端建议的时间等待，StatusCode 也能取到状态码
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryAfterTransport{base: base}
+}
+
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip 发送请求，需要时把响应转换为错误
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return resp, err
+	}
+	after, ok := ParseRetryAfter(resp.Header.Get("Retry-After"))
+	if !ok {
+		return resp, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	_ = resp.Body.Close()
+	return nil, WithRetryAfter(&deepseekapi.APIError{
+		StatusCode:   resp.StatusCode,
+		Message:      http.StatusText(resp.StatusCode),
+		ResponseBody: strings.TrimSpace(string(body)),
+	}, after)
+}
@@ -0,0 +1,52 @@
+package retry
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTransportRetryAfter(t *testing.T) {
+	status, header := http.StatusTooManyRequests, "2"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if header != "" {
+			w.Header().Set("Retry-After", header)
+		}
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, `{"error": "rate limited"}`)
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: Transport(nil)}
+
+	_, err := client.Get(srv.URL)
+	if err == nil {
+		t.Fatal("429 带 Retry-After 时应返回错误")
+	}
+	if after, ok := retryAfter(err); !ok || after != 2*time.Second {
+		t.Errorf("retryAfter = %v, %v，期望 2s", after, ok)
+	}
+	if code, ok := StatusCode(err); !ok || code != http.StatusTooManyRequests {
+		t.Errorf("StatusCode = %d, %v", code, ok)
+	}
+	if !IsRetryable(err) {
+		t.Error("应可以重试")
+	}
+
+	// 没有 Retry-After 或其它状态码时原样返回响应，由 SDK 自己解析
+	for _, c := range []struct {
+		status int
+		header string
+	}{{http.StatusTooManyRequests, ""}, {http.StatusBadRequest, "2"}, {http.StatusOK, ""}} {
+		status, header = c.status, c.header
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("状态码 %d、Retry-After %q 时返回错误: %v", c.status, c.header, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != c.status {
+			t.Errorf("StatusCode = %d，期望 %d", resp.StatusCode, c.status)
+		}
+	}
+}
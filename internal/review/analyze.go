@@ -0,0 +1,417 @@
+// Package review 在模型审查 Go 代码之前先运行本地分析：go vet、基于 go/ast 的复杂度统计和 gofmt 检查，
+// 把诊断结果作为依据交给模型，每条本地诊断单独输出，模型的意见并入同一行的本地诊断，输出 Markdown 或 SARIF 报告。
+package review
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"eino-tutorial/internal/codeassist"
+)
+
+// 本地分析的来源，也是 SARIF 中的规则 ID
+const (
+	SourceVet        = "vet"
+	SourceCompile    = "compile"
+	SourceSyntax     = "syntax"
+	SourceComplexity = "complexity"
+	SourceGofmt      = "gofmt"
+	SourceLLM        = "llm"
+)
+
+// 默认阈值
+const (
+	DefaultMaxComplexity = 10
+	DefaultMaxLines      = 80
+)
+
+// Options 本地分析选项，零值使用默认值
+type Options struct {
+	// NoVet 不运行 go vet，例如环境中没有 go 命令或代码依赖无法下载
+	NoVet bool
+	// Dir 文件所在模块中的目录，文件路径相对于它。不为空时直接在 Dir 中对文件所在的包运行 go vet，
+	// 分析的是磁盘上的内容；为空时把文件复制到临时模块中，只能解析标准库的依赖
+	Dir string
+	// MaxComplexity 函数圈复杂度超过该值时报告，默认 DefaultMaxComplexity
+	MaxComplexity int
+	// MaxLines 函数行数超过该值时报告，默认 DefaultMaxLines
+	MaxLines int
+}
+
+// Diagnostic 本地分析发现的一个问题
+type Diagnostic struct {
+	codeassist.Finding
+	// Source 来源，见 Source* 常量
+	Source string `json:"source"`
+}
+
+// FuncMetric 一个函数的复杂度指标
+type FuncMetric struct {
+	File string `json:"file"`
+	// Func 函数名，方法为 "类型.方法"
+	Func       string `json:"func"`
+	Line       int    `json:"line"`
+	Complexity int    `json:"complexity"`
+	Lines      int    `json:"lines"`
+}
+
+// Analysis 本地分析结果
+type Analysis struct {
+	Diagnostics []Diagnostic
+	Metrics     []FuncMetric
+	// Diffs 文件路径 -> gofmt 前后的 unified diff，只包含未格式化的文件
+	Diffs map[string]string
+	// Skipped 没有运行的分析及原因
+	Skipped []string
+}
+
+// Analyze 对 files 运行本地分析。files 中的路径必须是相对路径，同一目录下的文件视为同一个包。
+// go vet 无法运行时记录到 Skipped 而不是返回错误
+func Analyze(ctx context.Context, files []codeassist.File, opts *Options) (*Analysis, error) {
+	if len(files) == 0 {
+		return nil, errors.New("review: 没有需要分析的文件")
+	}
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.MaxComplexity <= 0 {
+		o.MaxComplexity = DefaultMaxComplexity
+	}
+	if o.MaxLines <= 0 {
+		o.MaxLines = DefaultMaxLines
+	}
+	for _, f := range files {
+		if err := checkPath(f.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	a := &Analysis{Diffs: map[string]string{}}
+	for _, f := range files {
+		fset := token.NewFileSet()
+		af, err := parser.ParseFile(fset, f.Path, f.Content, parser.AllErrors|parser.ParseComments)
+		if err != nil {
+			a.syntax(f.Path, err)
+			continue
+		}
+		a.complexity(fset, f.Path, af, &o)
+		a.gofmt(f)
+	}
+	if o.NoVet {
+		a.Skipped = append(a.Skipped, "go vet：已关闭")
+	} else if err := a.vet(ctx, files, &o); err != nil {
+		a.Skipped = append(a.Skipped, fmt.Sprintf("go vet：%v", err))
+	}
+	return a, nil
+}
+
+// checkPath 拒绝绝对路径和指向上级目录的路径，避免写到临时目录之外
+func checkPath(p string) error {
+	if !strings.HasSuffix(p, ".go") {
+		return fmt.Errorf("review: 只支持 Go 文件: %s", p)
+	}
+	c := path.Clean(filepath.ToSlash(p))
+	if path.IsAbs(c) || filepath.IsAbs(p) || c == ".." || strings.HasPrefix(c, "../") {
+		return fmt.Errorf("review: 文件路径必须是相对路径: %s", p)
+	}
+	return nil
+}
+
+func (a *Analysis) add(file string, line int, sev codeassist.Severity, source, msg, suggestion string) {
+	a.Diagnostics = append(a.Diagnostics, Diagnostic{
+		Finding: codeassist.Finding{
+			File:       file,
+			Line:       line,
+			Severity:   sev,
+			Category:   source,
+			Message:    msg,
+			Suggestion: suggestion,
+		},
+		Source: source,
+	})
+}
+
+func (a *Analysis) syntax(file string, err error) {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		a.add(file, 0, codeassist.SeverityError, SourceSyntax, err.Error(), "")
+		return
+	}
+	for _, e := range list {
+		a.add(file, e.Pos.Line, codeassist.SeverityError, SourceSyntax, e.Msg, "")
+	}
+}
+
+// complexity 统计每个函数的圈复杂度和行数，超过阈值时报告
+func (a *Analysis) complexity(fset *token.FileSet, file string, f *ast.File, o *Options) {
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		m := FuncMetric{
+			File:       file,
+			Func:       funcName(fn),
+			Line:       fset.Position(fn.Pos()).Line,
+			Complexity: cyclomatic(fn.Body),
+			Lines:      fset.Position(fn.End()).Line - fset.Position(fn.Pos()).Line + 1,
+		}
+		a.Metrics = append(a.Metrics, m)
+		if m.Complexity > o.MaxComplexity {
+			a.add(file, m.Line, codeassist.SeverityWarning, SourceComplexity,
+				fmt.Sprintf("函数 %s 的圈复杂度为 %d，超过 %d", m.Func, m.Complexity, o.MaxComplexity),
+				"拆分分支或提取子函数")
+		}
+		if m.Lines > o.MaxLines {
+			a.add(file, m.Line, codeassist.SeverityInfo, SourceComplexity,
+				fmt.Sprintf("函数 %s 有 %d 行，超过 %d", m.Func, m.Lines, o.MaxLines),
+				"把独立的步骤提取成函数")
+		}
+	}
+}
+
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	t := fn.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	// 泛型类型的接收者带有类型参数
+	switch x := t.(type) {
+	case *ast.IndexExpr:
+		t = x.X
+	case *ast.IndexListExpr:
+		t = x.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// cyclomatic 圈复杂度：1 加上分支、循环、非 default 的 case 和 && / || 的个数，闭包计入所在函数
+func cyclomatic(body *ast.BlockStmt) int {
+	c := 1
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			c++
+		case *ast.CaseClause:
+			if x.List != nil {
+				c++
+			}
+		case *ast.CommClause:
+			if x.Comm != nil {
+				c++
+			}
+		case *ast.BinaryExpr:
+			if x.Op == token.LAND || x.Op == token.LOR {
+				c++
+			}
+		}
+		return true
+	})
+	return c
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)`)
+
+// gofmt 文件没有格式化时在第一处改动的行报告一次，diff 放在 Diffs 中
+func (a *Analysis) gofmt(f codeassist.File) {
+	out, err := format.Source([]byte(f.Content))
+	if err != nil || string(out) == f.Content {
+		return
+	}
+	diff := codeassist.UnifiedDiff("a/"+f.Path, "b/"+f.Path, f.Content, string(out))
+	a.Diffs[f.Path] = diff
+
+	hunks, line, found := 0, 0, false
+	for _, l := range strings.Split(diff, "\n") {
+		if m := hunkHeader.FindStringSubmatch(l); m != nil {
+			hunks++
+			if hunks == 1 {
+				line, _ = strconv.Atoi(m[1])
+			}
+			continue
+		}
+		if hunks != 1 || found {
+			continue
+		}
+		// 跳过第一个 hunk 中改动前的上下文行
+		switch {
+		case strings.HasPrefix(l, " "):
+			line++
+		case strings.HasPrefix(l, "-"), strings.HasPrefix(l, "+"):
+			found = true
+		}
+	}
+	a.add(f.Path, line, codeassist.SeverityInfo, SourceGofmt,
+		fmt.Sprintf("文件未按 gofmt 格式化（%d 处差异）", hunks), "运行 gofmt -w")
+}
+
+// vetLine 匹配 go vet 的输出，"vet: " 前缀表示类型检查失败
+var vetLine = regexp.MustCompile(`^(vet: )?(?:\./)?(\S+?\.go):(\d+)(?::\d+)?: (.+)$`)
+
+// loadError 匹配加载包失败的输出，这类问题来自运行环境而不是代码
+var loadError = regexp.MustCompile(`is not in std|no required module provides package|cannot find module|missing go.sum entry`)
+
+// vet 运行 go vet。Options.Dir 为空时把文件写到临时模块中对 ./... 运行，否则在 Dir 中对文件所在的包运行
+func (a *Analysis) vet(ctx context.Context, files []codeassist.File, o *Options) error {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return errors.New("没有找到 go 命令")
+	}
+
+	// known 运行目录中的路径 -> 调用方给出的路径
+	known := map[string]string{}
+	for _, f := range files {
+		known[path.Clean(filepath.ToSlash(f.Path))] = f.Path
+	}
+	dir, pkgs := o.Dir, []string{"./..."}
+	if dir == "" {
+		if dir, err = writeModule(files, goVersion(ctx, goBin)); err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+	} else {
+		pkgs = pkgs[:0]
+		seen := map[string]bool{}
+		for name := range known {
+			pkg := "./" + path.Dir(name)
+			if !seen[pkg] {
+				seen[pkg] = true
+				pkgs = append(pkgs, pkg)
+			}
+		}
+		sort.Strings(pkgs)
+	}
+
+	cmd := exec.CommandContext(ctx, goBin, append([]string{"vet"}, pkgs...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off")
+	if o.Dir == "" {
+		cmd.Env = append(cmd.Env, "GOFLAGS=-mod=mod")
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	var diags []Diagnostic
+	sc := bufio.NewScanner(bytes.NewReader(out.Bytes()))
+	for sc.Scan() {
+		text := strings.TrimSpace(sc.Text())
+		if loadError.MatchString(text) {
+			return fmt.Errorf("加载包失败: %s", text)
+		}
+		m := vetLine.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		name := filepath.ToSlash(m[2])
+		if filepath.IsAbs(m[2]) {
+			if rel, err := filepath.Rel(dir, m[2]); err == nil {
+				name = filepath.ToSlash(rel)
+			}
+		}
+		file, ok := known[path.Clean(name)]
+		if !ok {
+			continue
+		}
+		line, _ := strconv.Atoi(m[3])
+		d := Diagnostic{
+			Finding: codeassist.Finding{File: file, Line: line, Severity: codeassist.SeverityWarning, Category: SourceVet, Message: m[4]},
+			Source:  SourceVet,
+		}
+		if m[1] != "" {
+			d.Severity, d.Category, d.Source = codeassist.SeverityError, SourceCompile, SourceCompile
+		}
+		diags = append(diags, d)
+	}
+	// go vet 失败但没有可识别的诊断，多半是依赖下载失败等环境问题
+	if runErr != nil && len(diags) == 0 {
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) > 3 {
+			lines = append(lines[:3], "...")
+		}
+		return fmt.Errorf("运行失败: %s", strings.Join(lines, "; "))
+	}
+	a.Diagnostics = append(a.Diagnostics, diags...)
+	return nil
+}
+
+var goVersionPattern = regexp.MustCompile(`^go(\d+\.\d+(?:\.\d+)?)`)
+
+// goVersion 返回 go 命令的版本（如 "1.23.4"），写入临时模块的 go.mod，
+// 使 range over int 等新语法按当前工具链检查。go env 失败时使用编译本程序的版本
+func goVersion(ctx context.Context, goBin string) string {
+	out, err := exec.CommandContext(ctx, goBin, "env", "GOVERSION").Output()
+	if m := goVersionPattern.FindStringSubmatch(strings.TrimSpace(string(out))); err == nil && m != nil {
+		return m[1]
+	}
+	if m := goVersionPattern.FindStringSubmatch(runtime.Version()); m != nil {
+		return m[1]
+	}
+	return "1.21"
+}
+
+// writeModule 把文件写到一个临时模块中，go.mod 声明 Go 版本 goVer，返回模块目录
+func writeModule(files []codeassist.File, goVer string) (string, error) {
+	dir, err := os.MkdirTemp("", "review-*")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module review\n\ngo "+goVer+"\n"), 0o644); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	for _, f := range files {
+		p := filepath.Join(dir, filepath.FromSlash(path.Clean(filepath.ToSlash(f.Path))))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err == nil {
+			err = os.WriteFile(p, []byte(f.Content), 0o644)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// Notes 把诊断结果整理成附加给模型的参考资料，没有诊断时返回空串
+func (a *Analysis) Notes() string {
+	if len(a.Diagnostics) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("本地静态分析（go vet、复杂度、gofmt）的诊断结果如下，这些是真实运行工具得到的：\n")
+	for _, d := range a.Diagnostics {
+		fmt.Fprintf(&sb, "- %s:%d [%s/%s] %s\n", d.File, d.Line, d.Severity, d.Source, d.Message)
+	}
+	sb.WriteString("请以这些诊断为依据：确认它们的影响并给出具体的修改建议，同一行的问题使用相同的行号；" +
+		"再补充工具发现不了的逻辑、并发和设计问题。")
+	return sb.String()
+}
@@ -0,0 +1,45 @@
+package review
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"eino-tutorial/internal/codeassist"
+)
+
+func TestAnalyzeNewSyntax(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("没有 go 命令")
+	}
+	src := `package main
+
+import "fmt"
+
+func main() {
+	for i := range 3 {
+		fmt.Printf("%d\n", "x")
+		_ = i
+	}
+}
+`
+	a, err := Analyze(context.Background(), []codeassist.File{{Path: "main.go", Content: src}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Skipped) > 0 {
+		t.Fatalf("go vet 没有运行: %v", a.Skipped)
+	}
+	var vet int
+	for _, d := range a.Diagnostics {
+		switch d.Source {
+		case SourceCompile:
+			t.Errorf("range over int 不应报编译错误: %s", d.Message)
+		case SourceVet:
+			vet++
+		}
+	}
+	if vet == 0 {
+		t.Errorf("应报告 Printf 参数类型错误: %+v", a.Diagnostics)
+	}
+}
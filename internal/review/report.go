@@ -0,0 +1,291 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"eino-tutorial/internal/codeassist"
+)
+
+// Comment 报告中的一条意见：一条本地诊断（可能并入了同一行的模型意见）或一条模型意见
+type Comment struct {
+	codeassist.Finding
+	// Sources 意见的来源，本地分析在前，模型为 SourceLLM
+	Sources []string `json:"sources"`
+}
+
+// Report 审查报告
+type Report struct {
+	Files    []string          `json:"files"`
+	Comments []Comment         `json:"comments"`
+	Metrics  []FuncMetric      `json:"metrics,omitempty"`
+	Diffs    map[string]string `json:"diffs,omitempty"`
+	Skipped  []string          `json:"skipped,omitempty"`
+}
+
+// Config 审查配置
+type Config struct {
+	// Assistant 为 nil 时只做本地分析
+	Assistant *codeassist.Assistant
+	Options
+}
+
+// Review 先运行本地分析，再把诊断结果附在审查请求中交给模型，最后把模型意见并入同一行的本地诊断
+func Review(ctx context.Context, cfg *Config, files []codeassist.File) (*Report, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	a, err := Analyze(ctx, files, &cfg.Options)
+	if err != nil {
+		return nil, err
+	}
+	var llm []codeassist.Finding
+	if cfg.Assistant != nil {
+		llm, err = cfg.Assistant.ReviewFiles(ctx, files, a.Notes())
+		if err != nil {
+			return nil, fmt.Errorf("review: %w", err)
+		}
+	}
+
+	r := &Report{
+		Comments: merge(files, a.Diagnostics, llm),
+		Metrics:  a.Metrics,
+		Diffs:    a.Diffs,
+		Skipped:  a.Skipped,
+	}
+	for _, f := range files {
+		r.Files = append(r.Files, f.Path)
+	}
+	return r, nil
+}
+
+var severityRank = map[codeassist.Severity]int{
+	codeassist.SeverityInfo:    0,
+	codeassist.SeverityWarning: 1,
+	codeassist.SeverityError:   2,
+}
+
+// merge 每条本地诊断单独作为一条意见，保留各自的类别和严重程度；
+// 模型意见所在的行有本地诊断时并入该行最严重的一条（补充描述和建议，不改变类别和严重程度），否则单独作为一条
+func merge(files []codeassist.File, diags []Diagnostic, llm []codeassist.Finding) []Comment {
+	type key struct {
+		file string
+		line int
+	}
+	// tools 每行最严重的本地诊断在 out 中的下标
+	tools := map[key]int{}
+	out := make([]Comment, 0, len(diags)+len(llm))
+	for _, d := range diags {
+		k := key{d.File, d.Line}
+		if i, ok := tools[k]; !ok || d.Line > 0 && severityRank[d.Severity] > severityRank[out[i].Severity] {
+			tools[k] = len(out)
+		}
+		out = append(out, Comment{Finding: d.Finding, Sources: []string{d.Source}})
+	}
+	for _, f := range llm {
+		i, ok := tools[key{f.File, f.Line}]
+		if !ok || f.Line == 0 {
+			out = append(out, Comment{Finding: f, Sources: []string{SourceLLM}})
+			continue
+		}
+		c := &out[i]
+		if msg := strings.TrimSpace(f.Message); msg != "" && !slices.Contains(strings.Split(c.Message, "\n"), msg) {
+			c.Message += "\n" + msg
+		}
+		if c.Suggestion == "" {
+			c.Suggestion = f.Suggestion
+		}
+		if !slices.Contains(c.Sources, SourceLLM) {
+			c.Sources = append(c.Sources, SourceLLM)
+		}
+	}
+
+	order := map[string]int{}
+	for i, f := range files {
+		order[f.Path] = i
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].File != out[j].File {
+			return order[out[i].File] < order[out[j].File]
+		}
+		return out[i].Line < out[j].Line
+	})
+	return out
+}
+
+// Count 按严重程度统计意见数
+func (r *Report) Count() map[codeassist.Severity]int {
+	n := map[codeassist.Severity]int{}
+	for _, c := range r.Comments {
+		n[c.Severity]++
+	}
+	return n
+}
+
+// WriteMarkdown 输出 Markdown 报告：每个文件一张问题表，之后是复杂度指标和 gofmt 差异
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	n := r.Count()
+	sb.WriteString("# 代码审查报告\n\n")
+	fmt.Fprintf(&sb, "共 %d 条意见：error %d，warning %d，info %d。\n",
+		len(r.Comments), n[codeassist.SeverityError], n[codeassist.SeverityWarning], n[codeassist.SeverityInfo])
+	for _, s := range r.Skipped {
+		fmt.Fprintf(&sb, "\n> 跳过 %s\n", s)
+	}
+
+	for _, file := range r.Files {
+		var rows []Comment
+		for _, c := range r.Comments {
+			if c.File == file {
+				rows = append(rows, c)
+			}
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n", file)
+		if len(rows) == 0 {
+			sb.WriteString("没有发现问题。\n")
+		} else {
+			sb.WriteString("| 行 | 级别 | 来源 | 问题 | 建议 |\n|---:|---|---|---|---|\n")
+			for _, c := range rows {
+				line := "-"
+				if c.Line > 0 {
+					line = fmt.Sprint(c.Line)
+				}
+				fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n", line, c.Severity,
+					strings.Join(c.Sources, ", "), cell(c.Message), cell(c.Suggestion))
+			}
+		}
+		if diff := r.Diffs[file]; diff != "" {
+			fmt.Fprintf(&sb, "\n<details><summary>gofmt 差异</summary>\n\n```diff\n%s```\n\n</details>\n", diff)
+		}
+	}
+
+	if len(r.Metrics) > 0 {
+		sb.WriteString("\n## 复杂度\n\n| 文件 | 函数 | 行 | 圈复杂度 | 行数 |\n|---|---|---:|---:|---:|\n")
+		for _, m := range r.Metrics {
+			fmt.Fprintf(&sb, "| %s | %s | %d | %d | %d |\n", m.File, cell(m.Func), m.Line, m.Complexity, m.Lines)
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// cell 转义表格单元格中的竖线和换行
+func cell(s string) string {
+	s = strings.TrimSpace(s)
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
+
+// SARIF 2.1.0 中用到的部分
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name  string      `json:"name"`
+		Rules []sarifRule `json:"rules,omitempty"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID     string          `json:"ruleId"`
+		Level      string          `json:"level"`
+		Message    sarifMessage    `json:"message"`
+		Locations  []sarifLocation `json:"locations"`
+		Properties map[string]any  `json:"properties,omitempty"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysical `json:"physicalLocation"`
+	}
+	sarifPhysical struct {
+		ArtifactLocation sarifArtifact `json:"artifactLocation"`
+		Region           *sarifRegion  `json:"region,omitempty"`
+	}
+	sarifArtifact struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+)
+
+var ruleDescriptions = map[string]string{
+	SourceVet:        "go vet 诊断",
+	SourceCompile:    "类型检查错误",
+	SourceSyntax:     "语法错误",
+	SourceComplexity: "函数过于复杂",
+	SourceGofmt:      "未按 gofmt 格式化",
+}
+
+// WriteSARIF 输出 SARIF 2.1.0 日志，可以上传到代码扫描平台。每条本地诊断对应一条结果，
+// ruleId 为意见的类别（本地诊断为来源，如 vet），严重程度 error / warning / info 对应 SARIF 的 error / warning / note
+func (r *Report) WriteSARIF(w io.Writer) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "eino-review"}},
+		Results: []sarifResult{},
+	}
+	rules := map[string]bool{}
+	for _, c := range r.Comments {
+		rule := c.Category
+		if rule == "" {
+			rule = c.Sources[0]
+		}
+		if !rules[rule] {
+			rules[rule] = true
+			desc := ruleDescriptions[rule]
+			if desc == "" {
+				desc = rule
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: rule, ShortDescription: sarifMessage{Text: desc}})
+		}
+
+		level := "warning"
+		switch c.Severity {
+		case codeassist.SeverityError:
+			level = "error"
+		case codeassist.SeverityInfo:
+			level = "note"
+		}
+		text := c.Message
+		if c.Suggestion != "" {
+			text += "\n建议：" + c.Suggestion
+		}
+		loc := sarifLocation{PhysicalLocation: sarifPhysical{ArtifactLocation: sarifArtifact{URI: c.File}}}
+		if c.Line > 0 {
+			loc.PhysicalLocation.Region = &sarifRegion{StartLine: c.Line}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:     rule,
+			Level:      level,
+			Message:    sarifMessage{Text: text},
+			Locations:  []sarifLocation{loc},
+			Properties: map[string]any{"sources": c.Sources},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	})
+}
@@ -0,0 +1,55 @@
+package review
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"eino-tutorial/internal/codeassist"
+)
+
+func TestMergeKeepsToolDiagnostics(t *testing.T) {
+	files := []codeassist.File{{Path: "main.go"}}
+	diags := []Diagnostic{
+		{Finding: codeassist.Finding{File: "main.go", Line: 3, Severity: codeassist.SeverityInfo, Category: SourceGofmt, Message: "格式不符合 gofmt", Suggestion: "运行 gofmt -w"}, Source: SourceGofmt},
+		{Finding: codeassist.Finding{File: "main.go", Line: 3, Severity: codeassist.SeverityWarning, Category: SourceVet, Message: "Printf format %d has arg of wrong type"}, Source: SourceVet},
+	}
+	llm := []codeassist.Finding{
+		{File: "main.go", Line: 3, Severity: codeassist.SeverityError, Category: "bug", Message: "格式化动词与参数类型不匹配", Suggestion: "改用 %s"},
+		{File: "main.go", Line: 8, Severity: codeassist.SeverityInfo, Category: "style", Message: "变量名过短"},
+	}
+	r := &Report{Comments: merge(files, diags, llm)}
+	if len(r.Comments) != 3 {
+		t.Fatalf("得到 %d 条意见，期望 3 条: %+v", len(r.Comments), r.Comments)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteSARIF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]sarifResult{}
+	for _, res := range log.Runs[0].Results {
+		got[res.RuleID] = res
+	}
+	vet, ok := got[SourceVet]
+	if !ok {
+		t.Fatalf("缺少 vet 规则的结果: %+v", log.Runs[0].Results)
+	}
+	// 模型意见并入同一行最严重的 vet 诊断，规则和级别保持不变
+	if vet.Level != "warning" || vet.Message.Text != "Printf format %d has arg of wrong type\n格式化动词与参数类型不匹配\n建议：改用 %s" {
+		t.Errorf("vet 结果 = %+v", vet)
+	}
+	if gofmt := got[SourceGofmt]; gofmt.Level != "note" || gofmt.Message.Text != "格式不符合 gofmt\n建议：运行 gofmt -w" {
+		t.Errorf("gofmt 结果 = %+v", gofmt)
+	}
+	if _, ok := got["style"]; !ok {
+		t.Error("没有本地诊断的行上的模型意见应单独输出")
+	}
+	if len(log.Runs[0].Tool.Driver.Rules) != 3 {
+		t.Errorf("rules = %+v", log.Runs[0].Tool.Driver.Rules)
+	}
+}
@@ -0,0 +1,134 @@
+package sampling
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Range 参数的取值范围（闭区间）
+type Range struct {
+	Min, Max float32
+}
+
+func (r *Range) String() string {
+	return fmt.Sprintf("[%v, %v]", r.Min, r.Max)
+}
+
+// Capabilities 模型支持的采样参数。范围为 nil 表示模型不支持该参数，设置了也不会生效
+type Capabilities struct {
+	Temperature      *Range
+	TopP             *Range
+	PresencePenalty  *Range
+	FrequencyPenalty *Range
+	// MaxTokens 单次输出 token 数的上限
+	MaxTokens int
+	// MaxStop 停止序列的最大数量，0 表示不限制
+	MaxStop int
+	// OmitsZero 组件把 0 当作未设置：temperature、top_p 为 0 时实际使用的是提供方默认值
+	OmitsZero bool
+}
+
+// Generic 没有登记能力表的模型使用的通用范围
+var Generic = Capabilities{
+	Temperature:      &Range{0, 2},
+	TopP:             &Range{0, 1},
+	PresencePenalty:  &Range{-2, 2},
+	FrequencyPenalty: &Range{-2, 2},
+	MaxTokens:        8192,
+}
+
+var (
+	capsMu sync.RWMutex
+	// caps 按 "provider/model" 或 "provider" 登记的能力表
+	caps = map[string]Capabilities{
+		"deepseek": {
+			Temperature:      &Range{0, 2},
+			TopP:             &Range{0, 1},
+			PresencePenalty:  &Range{-2, 2},
+			FrequencyPenalty: &Range{-2, 2},
+			MaxTokens:        8192,
+			MaxStop:          16,
+			OmitsZero:        true,
+		},
+		// 推理模型不支持采样参数，设置后会被忽略
+		"deepseek/deepseek-reasoner": {
+			MaxTokens: 65536,
+			MaxStop:   16,
+			OmitsZero: true,
+		},
+	}
+)
+
+// RegisterCapabilities 登记能力表，key 为 "provider/model" 或只有 "provider"
+func RegisterCapabilities(key string, c Capabilities) {
+	capsMu.Lock()
+	defer capsMu.Unlock()
+	caps[key] = c
+}
+
+// Lookup 依次按 "provider/model"、"provider" 查找能力表，都没有时返回 Generic
+func Lookup(provider, modelName string) Capabilities {
+	capsMu.RLock()
+	defer capsMu.RUnlock()
+	if c, ok := caps[provider+"/"+modelName]; ok {
+		return c
+	}
+	if c, ok := caps[provider]; ok {
+		return c
+	}
+	return Generic
+}
+
+// Error 采样参数不符合模型能力表
+type Error struct {
+	// Target 被校验的对象，例如 "profile creative (deepseek/deepseek-reasoner)"
+	Target   string
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("sampling: %s 的采样参数无效: %s", e.Target, strings.Join(e.Problems, "; "))
+}
+
+// Validate 按能力表校验 p，不合法时返回 *Error
+func (c Capabilities) Validate(target string, p Params) error {
+	var problems []string
+	check := func(name string, v *float32, r *Range, zeroMatters bool) {
+		switch {
+		case v == nil:
+		case r == nil:
+			problems = append(problems, fmt.Sprintf("模型不支持 %s", name))
+		case *v < r.Min || *v > r.Max:
+			problems = append(problems, fmt.Sprintf("%s=%v 超出范围 %s", name, *v, r))
+		case zeroMatters && *v == 0 && c.OmitsZero:
+			problems = append(problems, fmt.Sprintf("%s=0 会被当作未设置而使用提供方默认值，请改用 0.01 等极小值", name))
+		}
+	}
+	check("temperature", p.Temperature, c.Temperature, true)
+	check("top_p", p.TopP, c.TopP, true)
+	// penalty 为 0 与不设置的效果相同
+	check("presence_penalty", p.PresencePenalty, c.PresencePenalty, false)
+	check("frequency_penalty", p.FrequencyPenalty, c.FrequencyPenalty, false)
+
+	switch {
+	case p.MaxTokens < 0:
+		problems = append(problems, fmt.Sprintf("max_tokens=%d 必须为正数", p.MaxTokens))
+	case c.MaxTokens > 0 && p.MaxTokens > c.MaxTokens:
+		problems = append(problems, fmt.Sprintf("max_tokens=%d 超出范围 [1, %d]", p.MaxTokens, c.MaxTokens))
+	}
+	if c.MaxStop > 0 && len(p.Stop) > c.MaxStop {
+		problems = append(problems, fmt.Sprintf("stop 最多 %d 个，实际 %d 个", c.MaxStop, len(p.Stop)))
+	}
+	for _, s := range p.Stop {
+		if s == "" {
+			problems = append(problems, "stop 中包含空字符串")
+			break
+		}
+	}
+
+	if len(problems) > 0 {
+		return &Error{Target: target, Problems: problems}
+	}
+	return nil
+}
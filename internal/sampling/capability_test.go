@@ -0,0 +1,129 @@
+package sampling
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestLookup(t *testing.T) {
+	if c := Lookup("deepseek", "deepseek-reasoner"); c.Temperature != nil || c.MaxTokens != 65536 {
+		t.Errorf("deepseek-reasoner 应使用模型级能力表: %+v", c)
+	}
+	if c := Lookup("deepseek", "deepseek-chat"); !c.OmitsZero || c.MaxStop != 16 {
+		t.Errorf("deepseek-chat 应回退到提供方能力表: %+v", c)
+	}
+	if c := Lookup("unknown", "m"); c.MaxTokens != Generic.MaxTokens || c.OmitsZero {
+		t.Errorf("未登记的模型应使用 Generic: %+v", c)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	chat := Lookup("deepseek", "deepseek-chat")
+	reasoner := Lookup("deepseek", "deepseek-reasoner")
+	stops := make([]string, 17)
+	for i := range stops {
+		stops[i] = "。"
+	}
+
+	cases := []struct {
+		name string
+		caps Capabilities
+		p    Params
+		want []string
+	}{
+		{"合法", chat, Params{Temperature: ptr(0.7), TopP: ptr(1), MaxTokens: 8192, PresencePenalty: ptr(-2)}, nil},
+		{"未设置", reasoner, Params{}, nil},
+		{"temperature 超出范围", chat, Params{Temperature: ptr(2.5)}, []string{"temperature=2.5 超出范围 [0, 2]"}},
+		{"top_p 为 0 被忽略", chat, Params{TopP: ptr(0)}, []string{"top_p=0 会被当作未设置"}},
+		{"penalty 为 0 可以", chat, Params{PresencePenalty: ptr(0), FrequencyPenalty: ptr(0)}, nil},
+		{"Generic 接受 0", Generic, Params{Temperature: ptr(0)}, nil},
+		{"推理模型不支持采样参数", reasoner, Params{Temperature: ptr(0.7), FrequencyPenalty: ptr(0.5)},
+			[]string{"模型不支持 temperature", "模型不支持 frequency_penalty"}},
+		{"max_tokens 超出上限", chat, Params{MaxTokens: 9000}, []string{"max_tokens=9000 超出范围 [1, 8192]"}},
+		{"max_tokens 为负", chat, Params{MaxTokens: -1}, []string{"max_tokens=-1 必须为正数"}},
+		{"推理模型的 max_tokens", reasoner, Params{MaxTokens: 32768}, nil},
+		{"stop 太多", chat, Params{Stop: stops}, []string{"stop 最多 16 个，实际 17 个"}},
+		{"stop 空字符串", Generic, Params{Stop: []string{"\n", ""}}, []string{"stop 中包含空字符串"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.caps.Validate("profile test", c.p)
+			if c.want == nil {
+				if err != nil {
+					t.Fatalf("不应返回错误: %v", err)
+				}
+				return
+			}
+			var serr *Error
+			if !errors.As(err, &serr) {
+				t.Fatalf("应返回 *Error，得到 %v", err)
+			}
+			if serr.Target != "profile test" || len(serr.Problems) != len(c.want) {
+				t.Fatalf("Error = %+v", serr)
+			}
+			for i, want := range c.want {
+				if !strings.HasPrefix(serr.Problems[i], want) {
+					t.Errorf("Problems[%d] = %q，期望以 %q 开头", i, serr.Problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestPresetsValid(t *testing.T) {
+	for _, name := range Names() {
+		p, err := Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, caps := range []Capabilities{Generic, Lookup("deepseek", "deepseek-chat")} {
+			if err := caps.Validate("preset "+name, p.Params); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
+
+type fakeModel struct {
+	calls int
+}
+
+func (m *fakeModel) Generate(context.Context, []*schema.Message, ...model.Option) (*schema.Message, error) {
+	m.calls++
+	return schema.AssistantMessage("好的", nil), nil
+}
+
+func (m *fakeModel) Stream(context.Context, []*schema.Message, ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	m.calls++
+	return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage("好的", nil)}), nil
+}
+
+func TestWrap(t *testing.T) {
+	inner := &fakeModel{}
+	cm := Wrap(inner, Lookup("deepseek", "deepseek-reasoner"), "deepseek/deepseek-reasoner")
+	ctx := context.Background()
+	in := []*schema.Message{schema.UserMessage("你好")}
+
+	var serr *Error
+	if _, err := cm.Generate(ctx, in, model.WithTemperature(0.5)); !errors.As(err, &serr) || !strings.Contains(serr.Target, "单次调用") {
+		t.Errorf("单次调用设置不支持的参数应返回 *Error，得到 %v", err)
+	}
+	if _, err := cm.Stream(ctx, in, model.WithMaxTokens(100000)); !errors.As(err, &serr) {
+		t.Errorf("Stream 也应校验参数，得到 %v", err)
+	}
+	if inner.calls != 0 {
+		t.Fatalf("参数无效时不应调用底层模型")
+	}
+
+	if _, err := cm.Generate(ctx, in, model.WithMaxTokens(1000)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cm.WithTools(nil); err == nil {
+		t.Error("底层模型不支持工具时 WithTools 应返回错误")
+	}
+}
@@ -0,0 +1,63 @@
+package sampling
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ChatModel 在调用前校验单次调用通过 model.Option 覆盖的采样参数
+type ChatModel struct {
+	inner  model.BaseChatModel
+	caps   Capabilities
+	target string
+}
+
+var _ model.ToolCallingChatModel = (*ChatModel)(nil)
+
+// Wrap 按 caps 校验 inner 每次调用的采样参数，target 用于错误信息
+func Wrap(inner model.BaseChatModel, caps Capabilities, target string) *ChatModel {
+	return &ChatModel{inner: inner, caps: caps, target: target}
+}
+
+func (m *ChatModel) check(opts []model.Option) error {
+	return m.caps.Validate(m.target+"（单次调用）", FromOptions(opts...))
+}
+
+func (m *ChatModel) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	if err := m.check(opts); err != nil {
+		return nil, err
+	}
+	return m.inner.Generate(ctx, in, opts...)
+}
+
+func (m *ChatModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	if err := m.check(opts); err != nil {
+		return nil, err
+	}
+	return m.inner.Stream(ctx, in, opts...)
+}
+
+func (m *ChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	tc, ok := m.inner.(model.ToolCallingChatModel)
+	if !ok {
+		return nil, fmt.Errorf("sampling: 底层模型 %T 不支持 WithTools", m.inner)
+	}
+	inner, err := tc.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatModel{inner: inner, caps: m.caps, target: m.target}, nil
+}
+
+func (m *ChatModel) GetType() string {
+	return "Sampling"
+}
+
+// IsCallbacksEnabled 回调由底层模型触发
+func (m *ChatModel) IsCallbacksEnabled() bool {
+	return components.IsCallbacksEnabled(m.inner)
+}
@@ -0,0 +1,188 @@
+// Package sampling 提供命名的采样参数预设，并按模型能力表校验参数范围。
+//
+// 预设（precise / balanced / creative / code / translation）是一组 Params，profile 通过 preset 字段引用，
+// profile 中显式填写的字段优先。创建模型时按 Lookup 得到的能力表校验参数，超出范围或模型不支持的参数
+// 直接返回 *Error，而不是等提供方拒绝请求或静默忽略；Wrap 对单次调用通过 model.Option 覆盖的参数做同样的校验。
+package sampling
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/components/model"
+)
+
+// Params 一组采样参数，nil 或零值表示不设置，使用提供方默认值
+type Params struct {
+	Temperature      *float32 `json:"temperature,omitempty" yaml:"temperature,omitempty"`
+	TopP             *float32 `json:"top_p,omitempty" yaml:"top_p,omitempty"`
+	MaxTokens        int      `json:"max_tokens,omitempty" yaml:"max_tokens,omitempty"`
+	Stop             []string `json:"stop,omitempty" yaml:"stop,omitempty"`
+	PresencePenalty  *float32 `json:"presence_penalty,omitempty" yaml:"presence_penalty,omitempty"`
+	FrequencyPenalty *float32 `json:"frequency_penalty,omitempty" yaml:"frequency_penalty,omitempty"`
+}
+
+// Merge 返回以 p 为基础、over 中设置了的字段覆盖后的参数，不修改 p 和 over
+func (p Params) Merge(over Params) Params {
+	out := p
+	if over.Temperature != nil {
+		out.Temperature = over.Temperature
+	}
+	if over.TopP != nil {
+		out.TopP = over.TopP
+	}
+	if over.MaxTokens != 0 {
+		out.MaxTokens = over.MaxTokens
+	}
+	if over.Stop != nil {
+		out.Stop = over.Stop
+	}
+	if over.PresencePenalty != nil {
+		out.PresencePenalty = over.PresencePenalty
+	}
+	if over.FrequencyPenalty != nil {
+		out.FrequencyPenalty = over.FrequencyPenalty
+	}
+	// 复制指针指向的值，修改结果不会影响 p、over 或已注册的预设
+	out.Temperature = clone(out.Temperature)
+	out.TopP = clone(out.TopP)
+	out.PresencePenalty = clone(out.PresencePenalty)
+	out.FrequencyPenalty = clone(out.FrequencyPenalty)
+	out.Stop = append([]string(nil), out.Stop...)
+	return out
+}
+
+func clone(v *float32) *float32 {
+	if v == nil {
+		return nil
+	}
+	return ptr(*v)
+}
+
+// Options 把参数转换为单次调用的 model.Option。
+// eino 的通用选项不包含 presence / frequency penalty，这两个参数只能在创建模型时通过 profile 设置
+func (p Params) Options() []model.Option {
+	var opts []model.Option
+	if p.Temperature != nil {
+		opts = append(opts, model.WithTemperature(*p.Temperature))
+	}
+	if p.TopP != nil {
+		opts = append(opts, model.WithTopP(*p.TopP))
+	}
+	if p.MaxTokens != 0 {
+		opts = append(opts, model.WithMaxTokens(p.MaxTokens))
+	}
+	if p.Stop != nil {
+		opts = append(opts, model.WithStop(p.Stop))
+	}
+	return opts
+}
+
+// FromOptions 取出 opts 中设置的采样参数
+func FromOptions(opts ...model.Option) Params {
+	o := model.GetCommonOptions(&model.Options{}, opts...)
+	p := Params{Temperature: o.Temperature, TopP: o.TopP, Stop: o.Stop}
+	if o.MaxTokens != nil {
+		p.MaxTokens = *o.MaxTokens
+	}
+	return p
+}
+
+// String 以 key=value 形式列出设置了的参数
+func (p Params) String() string {
+	var parts []string
+	f := func(name string, v *float32) {
+		if v != nil {
+			parts = append(parts, fmt.Sprintf("%s=%v", name, *v))
+		}
+	}
+	f("temperature", p.Temperature)
+	f("top_p", p.TopP)
+	if p.MaxTokens != 0 {
+		parts = append(parts, fmt.Sprintf("max_tokens=%d", p.MaxTokens))
+	}
+	if len(p.Stop) > 0 {
+		parts = append(parts, fmt.Sprintf("stop=%q", p.Stop))
+	}
+	f("presence_penalty", p.PresencePenalty)
+	f("frequency_penalty", p.FrequencyPenalty)
+	if len(parts) == 0 {
+		return "默认"
+	}
+	return strings.Join(parts, " ")
+}
+
+// Preset 命名的采样参数预设
+type Preset struct {
+	Name        string
+	Description string
+	Params      Params
+}
+
+func ptr(v float32) *float32 {
+	return &v
+}
+
+var (
+	presetsMu sync.RWMutex
+	presets   = map[string]Preset{
+		"precise": {
+			Name:        "precise",
+			Description: "精确回答：低随机性",
+			Params:      Params{Temperature: ptr(0.2), TopP: ptr(0.9)},
+		},
+		"balanced": {
+			Name:        "balanced",
+			Description: "通用对话",
+			Params:      Params{Temperature: ptr(0.7), TopP: ptr(0.9)},
+		},
+		"creative": {
+			Name:        "creative",
+			Description: "创意写作：高随机性，降低重复",
+			Params:      Params{Temperature: ptr(1.2), TopP: ptr(0.95), PresencePenalty: ptr(0.3), FrequencyPenalty: ptr(0.3)},
+		},
+		"code": {
+			Name:        "code",
+			Description: "代码生成：接近确定的输出，留足输出长度",
+			Params:      Params{Temperature: ptr(0.1), TopP: ptr(0.95), MaxTokens: 4096},
+		},
+		"translation": {
+			Name:        "translation",
+			Description: "翻译：忠实原文，避免发挥",
+			Params:      Params{Temperature: ptr(0.3), TopP: ptr(0.9)},
+		},
+	}
+)
+
+// Register 注册一个预设，同名时覆盖
+func Register(p Preset) {
+	presetsMu.Lock()
+	defer presetsMu.Unlock()
+	presets[p.Name] = p
+}
+
+// Get 返回指定名称的预设
+func Get(name string) (Preset, error) {
+	presetsMu.RLock()
+	p, ok := presets[name]
+	presetsMu.RUnlock()
+	if !ok {
+		return Preset{}, fmt.Errorf("sampling: 未知的预设 %q，可用: %s", name, strings.Join(Names(), ", "))
+	}
+	p.Params = Params{}.Merge(p.Params)
+	return p, nil
+}
+
+// Names 返回所有预设名称（已排序）
+func Names() []string {
+	presetsMu.RLock()
+	defer presetsMu.RUnlock()
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
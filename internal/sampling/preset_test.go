@@ -0,0 +1,52 @@
+package sampling
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+)
+
+func TestMerge(t *testing.T) {
+	base := Params{Temperature: ptr(0.7), TopP: ptr(0.9), Stop: []string{"END"}}
+	out := base.Merge(Params{Temperature: ptr(0.1), MaxTokens: 100})
+	if *out.Temperature != 0.1 || *out.TopP != 0.9 || out.MaxTokens != 100 || !reflect.DeepEqual(out.Stop, []string{"END"}) {
+		t.Errorf("Merge = %s", out)
+	}
+	out.Stop[0] = "改过"
+	*out.TopP = 0.5
+	if base.Stop[0] != "END" || *base.TopP != 0.9 {
+		t.Error("Merge 的结果不应与原参数共享 Stop 和参数指针")
+	}
+}
+
+func TestGetReturnsCopy(t *testing.T) {
+	p, err := Get("code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	*p.Params.Temperature = 1.9
+	if again, _ := Get("code"); *again.Params.Temperature != 0.1 {
+		t.Errorf("修改 Get 的结果不应影响预设: temperature = %v", *again.Params.Temperature)
+	}
+	if _, err := Get("unknown"); err == nil {
+		t.Error("未知预设应返回错误")
+	}
+}
+
+func TestOptionsRoundTrip(t *testing.T) {
+	p := Params{Temperature: ptr(0.3), TopP: ptr(0.8), MaxTokens: 256, Stop: []string{"\n\n"}, PresencePenalty: ptr(0.5)}
+	got := FromOptions(p.Options()...)
+	// presence / frequency penalty 没有对应的通用选项
+	want := p
+	want.PresencePenalty = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromOptions(Options()) = %s，期望 %s", got, want)
+	}
+	if got := FromOptions(model.WithTemperature(1)); got.String() != "temperature=1" {
+		t.Errorf("String = %q", got.String())
+	}
+	if got := (Params{}).String(); got != "默认" {
+		t.Errorf("String = %q", got)
+	}
+}
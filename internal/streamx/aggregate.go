@@ -0,0 +1,134 @@
+package streamx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/tokens"
+)
+
+// Stats 一次流式响应的统计
+type Stats struct {
+	Start time.Time
+	// TTFT 从开始到第一个有内容的分片（正文、推理内容或工具调用）的时间
+	TTFT time.Duration
+	// Duration 从开始到最后一个分片的时间
+	Duration time.Duration
+	Chunks   int
+	// CompletionTokens 输出 token 数，响应中没有用量时为估算值
+	CompletionTokens int
+	Estimated        bool
+	// TokensPerSec 首个 token 之后的生成速度
+	TokensPerSec float64
+}
+
+func (s *Stats) String() string {
+	est := ""
+	if s.Estimated {
+		est = "（估算）"
+	}
+	return fmt.Sprintf("首 token %v，总耗时 %v，%d 个分片，输出 %d tokens%s，%.1f tokens/s",
+		s.TTFT.Round(time.Millisecond), s.Duration.Round(time.Millisecond), s.Chunks, s.CompletionTokens, est, s.TokensPerSec)
+}
+
+// Aggregator 把流式分片合并成一条完整消息。
+//
+// 合并使用 schema.ConcatMessages，工具调用增量、finish reason、用量和推理内容都会保留。
+// Add 可以直接作为 Tee 的消费者。
+type Aggregator struct {
+	start  time.Time
+	first  time.Time
+	last   time.Time
+	chunks []*schema.Message
+}
+
+// NewAggregator 创建合并器，start 为发起请求的时间，用于计算首 token 时间
+func NewAggregator(start time.Time) *Aggregator {
+	return &Aggregator{start: start}
+}
+
+// Add 加入一个分片
+func (a *Aggregator) Add(chunk *schema.Message) error {
+	if chunk == nil {
+		return nil
+	}
+	now := time.Now()
+	if a.first.IsZero() && hasOutput(chunk) {
+		a.first = now
+	}
+	a.last = now
+	a.chunks = append(a.chunks, chunk)
+	return nil
+}
+
+// Message 返回合并后的完整消息
+func (a *Aggregator) Message() (*schema.Message, error) {
+	if len(a.chunks) == 0 {
+		return nil, errors.New("streamx: 流中没有任何分片")
+	}
+	msg, err := schema.ConcatMessages(a.chunks)
+	if err != nil {
+		return nil, err
+	}
+	// ConcatMessages 不合并推理 token 明细，从分片中补上
+	if msg.ResponseMeta != nil && msg.ResponseMeta.Usage != nil {
+		for _, c := range a.chunks {
+			if c.ResponseMeta != nil && c.ResponseMeta.Usage != nil {
+				if n := c.ResponseMeta.Usage.CompletionTokensDetails.ReasoningTokens; n > 0 {
+					msg.ResponseMeta.Usage.CompletionTokensDetails.ReasoningTokens = n
+				}
+			}
+		}
+	}
+	return msg, nil
+}
+
+// Stats 返回统计信息
+func (a *Aggregator) Stats() *Stats {
+	s := &Stats{Start: a.start, Chunks: len(a.chunks)}
+	if !a.last.IsZero() {
+		s.Duration = a.last.Sub(a.start)
+	}
+	if !a.first.IsZero() {
+		s.TTFT = a.first.Sub(a.start)
+	}
+
+	if msg, err := a.Message(); err == nil && msg.ResponseMeta != nil && msg.ResponseMeta.Usage != nil &&
+		msg.ResponseMeta.Usage.CompletionTokens > 0 {
+		s.CompletionTokens = msg.ResponseMeta.Usage.CompletionTokens
+	} else {
+		s.CompletionTokens = tokens.Messages(a.chunks)
+		s.Estimated = true
+	}
+
+	if gen := a.last.Sub(a.first); !a.first.IsZero() && gen > 0 {
+		s.TokensPerSec = float64(s.CompletionTokens) / gen.Seconds()
+	}
+	return s
+}
+
+// Aggregate 读完整个流并返回合并后的消息和统计，结束后关闭 sr
+func Aggregate(sr *schema.StreamReader[*schema.Message], start time.Time) (*schema.Message, *Stats, error) {
+	defer sr.Close()
+	a := NewAggregator(start)
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, a.Stats(), err
+		}
+		_ = a.Add(chunk)
+	}
+	msg, err := a.Message()
+	return msg, a.Stats(), err
+}
+
+func hasOutput(m *schema.Message) bool {
+	return m.Content != "" || m.ReasoningContent != "" || len(m.ToolCalls) > 0
+}
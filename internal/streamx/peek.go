@@ -0,0 +1,55 @@
+// Package streamx 提供处理 schema.StreamReader 的工具函数。
+package streamx
+
+import (
+	"errors"
+	"io"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// Peek 读取流的第一个分片。
+//
+// 第一个分片之前出错时关闭原始流并返回该错误，调用方可以据此重试或切换模型；
+// 成功时返回的新流会先输出这个分片，再输出剩余内容。onDone 在新流结束或被关闭后调用，可以为 nil。
+func Peek[T any](sr *schema.StreamReader[T], onDone func()) (*schema.StreamReader[T], error) {
+	first, err := sr.Recv()
+	if errors.Is(err, io.EOF) {
+		sr.Close()
+		if onDone != nil {
+			onDone()
+		}
+		return schema.StreamReaderFromArray[T](nil), nil
+	}
+	if err != nil {
+		sr.Close()
+		if onDone != nil {
+			onDone()
+		}
+		return nil, err
+	}
+
+	out, sw := schema.Pipe[T](1)
+	go func() {
+		defer func() {
+			sr.Close()
+			sw.Close()
+			if onDone != nil {
+				onDone()
+			}
+		}()
+		if closed := sw.Send(first, nil); closed {
+			return
+		}
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if closed := sw.Send(chunk, err); closed || err != nil {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package streamx
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestPeekReplaysFirstChunk(t *testing.T) {
+	done := 0
+	sr, err := Peek(schema.StreamReaderFromArray([]string{"a", "b", "c"}), func() { done++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got += chunk
+	}
+	sr.Close()
+	if got != "abc" {
+		t.Errorf("读到 %q，期望 %q", got, "abc")
+	}
+	if done != 1 {
+		t.Errorf("onDone 调用了 %d 次", done)
+	}
+}
+
+func TestPeekFirstChunkError(t *testing.T) {
+	want := errors.New("连接断开")
+	src, sw := schema.Pipe[string](1)
+	go func() {
+		sw.Send("", want)
+		sw.Close()
+	}()
+	done := 0
+	if _, err := Peek(src, func() { done++ }); !errors.Is(err, want) {
+		t.Errorf("err = %v，期望 %v", err, want)
+	}
+	if done != 1 {
+		t.Errorf("onDone 调用了 %d 次", done)
+	}
+
+	sr, err := Peek(schema.StreamReaderFromArray[string](nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sr.Recv(); !errors.Is(err, io.EOF) {
+		t.Errorf("空流 Recv = %v，期望 io.EOF", err)
+	}
+}
@@ -0,0 +1,100 @@
+package streamx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// Sink 流的一个消费者，返回错误后不再收到后续分片
+type Sink[T any] func(chunk T) error
+
+// Tee 把 sr 分发给多个消费者，每个消费者在自己的 goroutine 中运行。
+//
+// 每个消费者最多缓冲 buffer 个分片（最少 1 个），缓冲满时停止读取上游，
+// 所以最慢的消费者决定整体速度，内存占用不会随流的长度增长。
+// 读完或出错后关闭 sr，等所有消费者处理完再返回；返回值合并了上游错误和各消费者的错误。
+func Tee[T any](sr *schema.StreamReader[T], buffer int, sinks ...Sink[T]) error {
+	defer sr.Close()
+	if buffer < 1 {
+		buffer = 1
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(sinks))
+		chs  = make([]chan T, len(sinks))
+	)
+	for i, sink := range sinks {
+		chs[i] = make(chan T, buffer)
+		wg.Add(1)
+		go func(i int, sink Sink[T], ch <-chan T) {
+			defer wg.Done()
+			for chunk := range ch {
+				if errs[i] != nil {
+					// 已经失败的消费者只排空通道，不阻塞其它消费者
+					continue
+				}
+				if err := sink(chunk); err != nil {
+					errs[i] = fmt.Errorf("消费者 %d: %w", i, err)
+				}
+			}
+		}(i, sink, chs[i])
+	}
+
+	var recvErr error
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			recvErr = err
+			break
+		}
+		for _, ch := range chs {
+			ch <- chunk
+		}
+	}
+	for _, ch := range chs {
+		close(ch)
+	}
+	wg.Wait()
+
+	return errors.Join(append([]error{recvErr}, errs...)...)
+}
+
+// ContentWriter 把分片的正文写入 w，可用于终端或文件
+func ContentWriter(w io.Writer) Sink[*schema.Message] {
+	return func(chunk *schema.Message) error {
+		if chunk.Content == "" {
+			return nil
+		}
+		_, err := io.WriteString(w, chunk.Content)
+		return err
+	}
+}
+
+// SSEWriter 以 Server-Sent Events 格式把分片写入 w，每个分片一条 data 事件。
+// w 实现 http.Flusher 时每条事件后立即刷新
+func SSEWriter(w io.Writer) Sink[*schema.Message] {
+	flusher, _ := w.(http.Flusher)
+	return func(chunk *schema.Message) error {
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+}
@@ -0,0 +1,264 @@
+// Package structured 让模型按 Go 结构体返回结构化结果。
+//
+// 结构体通过 json 和 jsonschema 标签描述字段，例如：
+//
+//	type Sentiment struct {
+//		Label      string `json:"label" jsonschema:"description=情感倾向,enum=正面,enum=负面,enum=中性"`
+//		Confidence int    `json:"confidence" jsonschema:"description=置信度,minimum=0,maximum=100"`
+//	}
+//
+// 没有 omitempty 的字段为必填。New 从结构体反射出 JSON Schema，Generate 请求模型、解码并校验结果，
+// 不合法时把校验错误发回模型修复，最多 MaxRepairs 次。
+package structured
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/schema"
+	"github.com/eino-contrib/jsonschema"
+)
+
+// Mode 获取结构化结果的方式
+type Mode string
+
+const (
+	// ModeJSON 在系统提示词中给出 JSON Schema，要求模型只输出 JSON
+	ModeJSON Mode = "json"
+	// ModeTool 把 Schema 作为唯一的工具并强制模型调用，参数即结果
+	ModeTool Mode = "tool"
+)
+
+// Config 结构化输出配置
+type Config struct {
+	Model model.BaseChatModel
+	// Mode 默认 ModeJSON；ModeTool 要求 Model 实现 model.ToolCallingChatModel
+	Mode Mode
+	// Name、Description ModeTool 下的工具名称和说明，默认 "submit_result"
+	Name        string
+	Description string
+	// MaxRepairs 校验失败后最多让模型修复的次数，默认 2，小于 0 表示不修复
+	MaxRepairs int
+}
+
+// ValidationError 模型输出不能解码或不符合 Schema
+type ValidationError struct {
+	// Output 从模型最后一次输出中取出的 JSON 文本（ModeTool 下为工具参数）
+	Output string
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return "structured: 输出不符合 Schema: " + strings.Join(e.Errors, "; ")
+}
+
+// Extractor 把模型输出解码为 T
+type Extractor[T any] struct {
+	cfg    Config
+	schema *jsonschema.Schema
+	// schemaJSON 发给模型的 Schema 文本
+	schemaJSON string
+	tool       *schema.ToolInfo
+	// model ModeTool 下绑定了工具的模型
+	model model.BaseChatModel
+}
+
+// New 从 T 反射出 JSON Schema 并创建 Extractor，T 必须是结构体
+func New[T any](cfg *Config) (*Extractor[T], error) {
+	if cfg == nil || cfg.Model == nil {
+		return nil, errors.New("structured: 缺少 Model")
+	}
+	c := *cfg
+	if c.Mode == "" {
+		c.Mode = ModeJSON
+	}
+	if c.Name == "" {
+		c.Name = "submit_result"
+	}
+	if c.Description == "" {
+		c.Description = "提交最终结果"
+	}
+	if c.MaxRepairs == 0 {
+		c.MaxRepairs = 2
+	}
+
+	params, err := utils.GoStruct2ParamsOneOf[T]()
+	if err != nil {
+		return nil, fmt.Errorf("structured: 生成 Schema 失败: %w", err)
+	}
+	js, err := params.ToJSONSchema()
+	if err != nil {
+		return nil, fmt.Errorf("structured: 生成 Schema 失败: %w", err)
+	}
+	if js == nil || js.Type != "object" {
+		return nil, fmt.Errorf("structured: %T 不是结构体", *new(T))
+	}
+	data, err := json.Marshal(js)
+	if err != nil {
+		return nil, fmt.Errorf("structured: 序列化 Schema 失败: %w", err)
+	}
+
+	e := &Extractor[T]{cfg: c, schema: js, schemaJSON: string(data), model: c.Model}
+	switch c.Mode {
+	case ModeJSON:
+	case ModeTool:
+		tc, ok := c.Model.(model.ToolCallingChatModel)
+		if !ok {
+			return nil, fmt.Errorf("structured: 模型 %T 不支持工具调用", c.Model)
+		}
+		e.tool = &schema.ToolInfo{Name: c.Name, Desc: c.Description, ParamsOneOf: params}
+		if e.model, err = tc.WithTools([]*schema.ToolInfo{e.tool}); err != nil {
+			return nil, fmt.Errorf("structured: 绑定工具失败: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("structured: 未知的模式 %q", c.Mode)
+	}
+	return e, nil
+}
+
+// Schema 返回 T 的 JSON Schema
+func (e *Extractor[T]) Schema() *jsonschema.Schema {
+	return e.schema
+}
+
+// Generate 请求模型并返回解码、校验后的结果。
+//
+// 修复次数用完仍不合法时返回的错误包含 *ValidationError，可用 errors.As 取出最后一次的输出和错误
+func (e *Extractor[T]) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*T, error) {
+	msgs := e.prepare(in)
+	if e.cfg.Mode == ModeTool {
+		opts = append(opts, model.WithToolChoice(schema.ToolChoiceForced, e.cfg.Name))
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := e.model.Generate(ctx, msgs, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("structured: 生成失败: %w", err)
+		}
+
+		raw, callID := e.output(resp)
+		v, verr := e.decode(raw)
+		if verr == nil {
+			return v, nil
+		}
+		if attempt >= e.cfg.MaxRepairs {
+			return nil, fmt.Errorf("structured: 修复 %d 次后仍然失败: %w", attempt, verr)
+		}
+		msgs = append(msgs, e.repair(resp, callID, verr)...)
+	}
+}
+
+// prepare ModeJSON 下把格式要求追加到系统提示词，不修改调用方的消息
+func (e *Extractor[T]) prepare(in []*schema.Message) []*schema.Message {
+	msgs := append([]*schema.Message(nil), in...)
+	if e.cfg.Mode != ModeJSON {
+		return msgs
+	}
+	instruction := "只输出一个符合以下 JSON Schema 的 JSON 对象，不要输出 Markdown 代码块或其它内容：\n" + e.schemaJSON
+	if len(msgs) > 0 && msgs[0].Role == schema.System {
+		sys := *msgs[0]
+		sys.Content += "\n\n" + instruction
+		msgs[0] = &sys
+		return msgs
+	}
+	return append([]*schema.Message{schema.SystemMessage(instruction)}, msgs...)
+}
+
+// output 取出模型输出的 JSON 文本，ModeTool 下同时返回工具调用 ID
+func (e *Extractor[T]) output(resp *schema.Message) (string, string) {
+	if e.cfg.Mode == ModeTool {
+		for _, tc := range resp.ToolCalls {
+			if tc.Function.Name == e.cfg.Name {
+				return tc.Function.Arguments, tc.ID
+			}
+		}
+		return "", ""
+	}
+	return ExtractJSON(resp.Content), ""
+}
+
+func (e *Extractor[T]) decode(raw string) (*T, *ValidationError) {
+	if strings.TrimSpace(raw) == "" {
+		msg := "没有输出 JSON 对象"
+		if e.cfg.Mode == ModeTool {
+			msg = "没有调用工具 " + e.cfg.Name
+		}
+		return nil, &ValidationError{Output: raw, Errors: []string{msg}}
+	}
+
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, &ValidationError{Output: raw, Errors: []string{"JSON 解析失败: " + err.Error()}}
+	}
+	if errs := Validate(e.schema, doc); len(errs) > 0 {
+		return nil, &ValidationError{Output: raw, Errors: errs}
+	}
+
+	var v T
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return nil, &ValidationError{Output: raw, Errors: []string{"解码失败: " + err.Error()}}
+	}
+	return &v, nil
+}
+
+// repair 把错误的输出和校验错误追加到对话中，让模型重新输出
+func (e *Extractor[T]) repair(resp *schema.Message, callID string, verr *ValidationError) []*schema.Message {
+	var b strings.Builder
+	b.WriteString("输出不符合要求：\n")
+	for _, msg := range verr.Errors {
+		b.WriteString("- " + msg + "\n")
+	}
+
+	if e.cfg.Mode == ModeTool && callID != "" {
+		b.WriteString("请修正后重新调用 " + e.cfg.Name + "。")
+		// 只保留结果工具的调用，每个调用都需要对应的工具消息
+		call := *resp
+		call.ToolCalls = nil
+		for _, tc := range resp.ToolCalls {
+			if tc.ID == callID {
+				call.ToolCalls = append(call.ToolCalls, tc)
+			}
+		}
+		return []*schema.Message{&call, schema.ToolMessage(b.String(), callID)}
+	}
+
+	if e.cfg.Mode == ModeTool {
+		b.WriteString("请调用 " + e.cfg.Name + " 提交结果。")
+	} else {
+		b.WriteString("请只输出修正后的 JSON 对象。")
+	}
+	return []*schema.Message{schema.AssistantMessage(resp.Content, nil), schema.UserMessage(b.String())}
+}
+
+// ExtractJSON 从模型回答中去掉 Markdown 代码块和前后的说明文字，取出第一个 JSON 对象，没有时返回空串
+func ExtractJSON(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "```"); i >= 0 {
+		rest := s[i+3:]
+		if nl := strings.IndexByte(rest, '\n'); nl >= 0 {
+			rest = rest[nl+1:]
+		}
+		if j := strings.Index(rest, "```"); j >= 0 {
+			s = strings.TrimSpace(rest[:j])
+		}
+	}
+	start := strings.IndexByte(s, '{')
+	if start < 0 {
+		return ""
+	}
+	// 从第一个 { 开始解码一个完整的值，忽略后面的文字
+	dec := json.NewDecoder(strings.NewReader(s[start:]))
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return s[start:]
+	}
+	return string(bytes.TrimSpace(raw))
+}
@@ -0,0 +1,207 @@
+package structured
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+type review struct {
+	Label      string   `json:"label" jsonschema:"enum=正面,enum=负面,enum=中性"`
+	Confidence int      `json:"confidence" jsonschema:"minimum=0,maximum=100"`
+	Summary    string   `json:"summary" jsonschema:"maxLength=10"`
+	Code       string   `json:"code,omitempty" jsonschema:"pattern=^[A-Z]{2}\\d+$"`
+	Tags       []string `json:"tags,omitempty" jsonschema:"minItems=1,maxItems=2"`
+	Score      float64  `json:"score,omitempty"`
+}
+
+// scripted 依次返回 replies，记录每次收到的消息
+type scripted struct {
+	replies  []*schema.Message
+	requests [][]*schema.Message
+	tools    []*schema.ToolInfo
+}
+
+func (m *scripted) Generate(_ context.Context, in []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	m.requests = append(m.requests, in)
+	if len(m.requests) > len(m.replies) {
+		return nil, errors.New("没有更多回复")
+	}
+	return m.replies[len(m.requests)-1], nil
+}
+
+func (m *scripted) Stream(context.Context, []*schema.Message, ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, errors.New("不支持")
+}
+
+func (m *scripted) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	m.tools = tools
+	return m, nil
+}
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestValidate(t *testing.T) {
+	e, err := New[review](&Config{Model: &scripted{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"合法", `{"label":"正面","confidence":90,"summary":"很好"}`, nil},
+		{"可选字段合法", `{"label":"中性","confidence":0,"summary":"一般","code":"AB12","tags":["x"],"score":1.5}`, nil},
+		{"缺少必填字段", `{"label":"正面"}`, []string{`$: 缺少必填字段 "confidence"`, `$: 缺少必填字段 "summary"`}},
+		{"枚举", `{"label":"好","confidence":1,"summary":"s"}`, []string{`$.label: 取值 "好" 不在可选值 ["正面","负面","中性"] 中`}},
+		{"数值范围", `{"label":"正面","confidence":150,"summary":"s"}`, []string{"$.confidence: 150 大于最大值 100"}},
+		{"整数类型", `{"label":"正面","confidence":1.5,"summary":"s"}`, []string{"$.confidence: 类型应为 integer，实际为 number"}},
+		{"字符串长度按字符计", `{"label":"正面","confidence":1,"summary":"这是一段超过十个字的总结内容"}`, []string{"$.summary: 长度 14 超过最大长度 10"}},
+		{"pattern", `{"label":"正面","confidence":1,"summary":"s","code":"ab1"}`, []string{`$.code: "ab1" 不匹配 ^[A-Z]{2}\d+$`}},
+		{"数组长度和元素类型", `{"label":"正面","confidence":1,"summary":"s","tags":["a",2,"c"]}`, []string{"$.tags: 元素个数 3 超过 2", "$.tags[1]: 类型应为 string，实际为 number"}},
+		{"不允许的字段", `{"label":"正面","confidence":1,"summary":"s","extra":true}`, []string{`$: 不允许的字段 "extra"`}},
+		{"顶层类型", `[1]`, []string{"$: 类型应为 object，实际为 array"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := Validate(e.Schema(), decode(t, c.doc))
+			if strings.Join(got, "\n") != strings.Join(c.want, "\n") {
+				t.Errorf("Validate =\n%s\n期望\n%s", strings.Join(got, "\n"), strings.Join(c.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestGenerateRepairsJSON(t *testing.T) {
+	cm := &scripted{replies: []*schema.Message{
+		schema.AssistantMessage("结果如下：\n```json\n{\"label\":\"好\",\"confidence\":150,\"summary\":\"不错\"}\n```", nil),
+		schema.AssistantMessage(`{"label":"正面","confidence":95,"summary":"不错"}`, nil),
+	}}
+	e, err := New[review](&Config{Model: cm})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := []*schema.Message{schema.SystemMessage("你是评论分析助手"), schema.UserMessage("这家店很棒")}
+	got, err := e.Generate(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, review{Label: "正面", Confidence: 95, Summary: "不错"}) {
+		t.Errorf("结果 = %+v", got)
+	}
+
+	if in[0].Content != "你是评论分析助手" {
+		t.Error("不应修改调用方的系统消息")
+	}
+	first := cm.requests[0]
+	if len(first) != 2 || !strings.Contains(first[0].Content, "你是评论分析助手") || !strings.Contains(first[0].Content, `"confidence"`) {
+		t.Errorf("系统提示词应追加 Schema: %q", first[0].Content)
+	}
+	// 修复请求带上错误的输出和所有校验错误
+	second := cm.requests[1]
+	if len(second) != 4 || second[2].Role != schema.Assistant || second[3].Role != schema.User {
+		t.Fatalf("修复请求的消息 = %d 条", len(second))
+	}
+	for _, want := range []string{"$.label: 取值 \"好\"", "$.confidence: 150 大于最大值 100", "请只输出修正后的 JSON 对象"} {
+		if !strings.Contains(second[3].Content, want) {
+			t.Errorf("修复提示缺少 %q:\n%s", want, second[3].Content)
+		}
+	}
+}
+
+func TestGenerateGivesUp(t *testing.T) {
+	bad := schema.AssistantMessage("抱歉，我无法回答", nil)
+	cm := &scripted{replies: []*schema.Message{bad, bad, bad}}
+	e, err := New[review](&Config{Model: cm, MaxRepairs: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.Generate(context.Background(), []*schema.Message{schema.UserMessage("这家店很棒")})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Errors[0] != "没有输出 JSON 对象" {
+		t.Fatalf("应返回 ValidationError，得到 %v", err)
+	}
+	if len(cm.requests) != 2 {
+		t.Errorf("MaxRepairs=1 时应请求 2 次，实际 %d 次", len(cm.requests))
+	}
+
+	// MaxRepairs < 0 不修复
+	cm = &scripted{replies: []*schema.Message{bad}}
+	e, _ = New[review](&Config{Model: cm, MaxRepairs: -1})
+	if _, err := e.Generate(context.Background(), []*schema.Message{schema.UserMessage("这家店很棒")}); !errors.As(err, &verr) || len(cm.requests) != 1 {
+		t.Errorf("不修复时应只请求 1 次并返回 ValidationError: %v", err)
+	}
+}
+
+func TestGenerateRepairsToolCall(t *testing.T) {
+	call := func(id, args string) *schema.Message {
+		return schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "other", Function: schema.FunctionCall{Name: "search", Arguments: "{}"}},
+			{ID: id, Function: schema.FunctionCall{Name: "submit_result", Arguments: args}},
+		})
+	}
+	cm := &scripted{replies: []*schema.Message{
+		call("c1", `{"label":"正面","confidence":-1,"summary":"s"}`),
+		call("c2", `{"label":"负面","confidence":10,"summary":"s"}`),
+	}}
+	e, err := New[review](&Config{Model: cm, Mode: ModeTool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cm.tools) != 1 || cm.tools[0].Name != "submit_result" {
+		t.Fatalf("应绑定结果工具: %+v", cm.tools)
+	}
+	got, err := e.Generate(context.Background(), []*schema.Message{schema.UserMessage("很差")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Label != "负面" || got.Confidence != 10 {
+		t.Errorf("结果 = %+v", got)
+	}
+
+	// 修复时只保留结果工具的调用，并用工具消息回复错误
+	second := cm.requests[1]
+	assistant, tool := second[len(second)-2], second[len(second)-1]
+	if len(assistant.ToolCalls) != 1 || assistant.ToolCalls[0].ID != "c1" {
+		t.Errorf("修复请求中的工具调用 = %+v", assistant.ToolCalls)
+	}
+	if tool.Role != schema.Tool || tool.ToolCallID != "c1" || !strings.Contains(tool.Content, "$.confidence: -1 小于最小值 0") {
+		t.Errorf("工具消息 = %+v", tool)
+	}
+	if len(cm.requests[0]) != 1 {
+		t.Error("ModeTool 下不应追加系统提示词")
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{`{"a":1}`, `{"a":1}`},
+		{"结果：{\"a\":{\"b\":\"}\"}} 以上", `{"a":{"b":"}"}}`},
+		{"```json\n{\"a\":1}\n```\n说明 {\"b\":2}", `{"a":1}`},
+		{"没有 JSON", ""},
+		{`{"a":`, `{"a":`},
+	}
+	for _, c := range cases {
+		if got := ExtractJSON(c.in); got != c.want {
+			t.Errorf("ExtractJSON(%q) = %q，期望 %q", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package structured
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/eino-contrib/jsonschema"
+)
+
+// Validate 按 JSON Schema 校验已解码的 JSON 值，返回所有违反的规则。
+//
+// v 应当由 json.Decoder.UseNumber 解码，数字为 json.Number。支持 Go 结构体反射出的常用关键字：
+// type、enum、const、数值范围、字符串长度和 pattern、数组长度和 items、properties、required、additionalProperties。
+func Validate(s *jsonschema.Schema, v any) []string {
+	var errs []string
+	validate(s, v, "$", &errs)
+	return errs
+}
+
+func validate(s *jsonschema.Schema, v any, path string, errs *[]string) {
+	if s == nil {
+		return
+	}
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if types := schemaTypes(s); len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool { return hasType(v, t) }) {
+		fail("类型应为 %s，实际为 %s", strings.Join(types, " 或 "), typeOf(v))
+		return
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return equal(e, v) }) {
+		fail("取值 %s 不在可选值 %s 中", format(v), format(s.Enum))
+	}
+	if s.Const != nil && !equal(s.Const, v) {
+		fail("取值应为 %s", format(s.Const))
+	}
+
+	switch x := v.(type) {
+	case json.Number:
+		validateNumber(s, x, fail)
+	case string:
+		n := uint64(utf8.RuneCountInString(x))
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("长度 %d 小于最小长度 %d", n, *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("长度 %d 超过最大长度 %d", n, *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(x) {
+				fail("%q 不匹配 %s", x, s.Pattern)
+			}
+		}
+	case []any:
+		n := uint64(len(x))
+		if s.MinItems != nil && n < *s.MinItems {
+			fail("元素个数 %d 少于 %d", n, *s.MinItems)
+		}
+		if s.MaxItems != nil && n > *s.MaxItems {
+			fail("元素个数 %d 超过 %d", n, *s.MaxItems)
+		}
+		for i, item := range x {
+			validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := x[name]; !ok {
+				fail("缺少必填字段 %q", name)
+			}
+		}
+		for _, name := range sortedKeys(x) {
+			var prop *jsonschema.Schema
+			if s.Properties != nil {
+				prop, _ = s.Properties.Get(name)
+			}
+			switch {
+			case prop != nil:
+				validate(prop, x[name], path+"."+name, errs)
+			case isFalse(s.AdditionalProperties):
+				fail("不允许的字段 %q", name)
+			case s.AdditionalProperties != nil:
+				validate(s.AdditionalProperties, x[name], path+"."+name, errs)
+			}
+		}
+	}
+}
+
+func validateNumber(s *jsonschema.Schema, n json.Number, fail func(string, ...any)) {
+	v, ok := new(big.Rat).SetString(n.String())
+	if !ok {
+		fail("无效的数字 %s", n)
+		return
+	}
+	bound := func(b json.Number) *big.Rat {
+		if b == "" {
+			return nil
+		}
+		r, _ := new(big.Rat).SetString(b.String())
+		return r
+	}
+	if m := bound(s.Minimum); m != nil && v.Cmp(m) < 0 {
+		fail("%s 小于最小值 %s", n, s.Minimum)
+	}
+	if m := bound(s.Maximum); m != nil && v.Cmp(m) > 0 {
+		fail("%s 大于最大值 %s", n, s.Maximum)
+	}
+	if m := bound(s.ExclusiveMinimum); m != nil && v.Cmp(m) <= 0 {
+		fail("%s 应大于 %s", n, s.ExclusiveMinimum)
+	}
+	if m := bound(s.ExclusiveMaximum); m != nil && v.Cmp(m) >= 0 {
+		fail("%s 应小于 %s", n, s.ExclusiveMaximum)
+	}
+	if m := bound(s.MultipleOf); m != nil && m.Sign() != 0 && !new(big.Rat).Quo(v, m).IsInt() {
+		fail("%s 不是 %s 的整数倍", n, s.MultipleOf)
+	}
+}
+
+func schemaTypes(s *jsonschema.Schema) []string {
+	if len(s.TypeEnhanced) > 0 {
+		return s.TypeEnhanced
+	}
+	if s.Type != "" {
+		return []string{s.Type}
+	}
+	return nil
+}
+
+func hasType(v any, typ string) bool {
+	switch typ {
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		r, ok := new(big.Rat).SetString(n.String())
+		return ok && r.IsInt()
+	default:
+		return typeOf(v) == typ
+	}
+}
+
+func typeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// equal 比较两个 JSON 值，数字按数值比较（schema 中的 enum 可能是字符串形式的数字）
+func equal(a, b any) bool {
+	if na, ok := number(a); ok {
+		nb, ok := number(b)
+		return ok && na.Cmp(nb) == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func number(v any) (*big.Rat, bool) {
+	switch x := v.(type) {
+	case json.Number:
+		return new(big.Rat).SetString(x.String())
+	case float64:
+		r := new(big.Rat)
+		if r.SetFloat64(x) == nil {
+			return nil, false
+		}
+		return r, true
+	case int:
+		return new(big.Rat).SetInt64(int64(x)), true
+	default:
+		return nil, false
+	}
+}
+
+func format(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// isFalse schema 是否为 false（不允许任何值）
+func isFalse(s *jsonschema.Schema) bool {
+	if s == nil {
+		return false
+	}
+	data, err := json.Marshal(s)
+	return err == nil && string(data) == "false"
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,167 @@
+// Package tokens 在本地估算消息的 token 数，不需要下载分词器。
+//
+// 估算按字符类别乘以系数：ASCII 字符、中日韩文字和其它字符各有一个系数，
+// 系数取自各提供方公布的换算比例，例如 DeepSeek 为 1 个英文字符约 0.3 个 token、1 个中文字符约 0.6 个 token。
+// 结果只是近似值，按上下文窗口做预算时需要留出余量。
+package tokens
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// Tokenizer 一类模型的 token 估算参数
+type Tokenizer struct {
+	Name string
+	// ASCII、CJK、Other 每个字符折合的 token 数
+	ASCII float64
+	CJK   float64
+	Other float64
+	// PerMessage 每条消息的角色标记等固定开销
+	PerMessage int
+	// ContextWindow 上下文窗口（输入加输出）的 token 数，0 表示未知
+	ContextWindow int
+}
+
+var (
+	// Approx 通用的保守估算：ASCII 约 4 字节一个 token，其它字符约 1 字一个 token
+	Approx = &Tokenizer{Name: "approx", ASCII: 0.25, CJK: 1, Other: 1}
+
+	// DeepSeek DeepSeek 官方给出的换算比例
+	DeepSeek = &Tokenizer{Name: "deepseek", ASCII: 0.3, CJK: 0.6, Other: 1, PerMessage: 4, ContextWindow: 128_000}
+
+	// Doubao 火山方舟豆包模型，中文约 1.5 字一个 token
+	Doubao = &Tokenizer{Name: "doubao", ASCII: 0.25, CJK: 0.7, Other: 1, PerMessage: 4, ContextWindow: 32_000}
+)
+
+var (
+	registryMu sync.RWMutex
+	// registry 按 "provider/model" 或 "provider" 登记的估算参数
+	registry = map[string]*Tokenizer{
+		"deepseek": DeepSeek,
+		"ark":      Doubao,
+	}
+)
+
+// Register 登记估算参数，key 为 "provider/model" 或只有 "provider"
+func Register(key string, t *Tokenizer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[key] = t
+}
+
+// windowSuffix 模型名中的上下文长度后缀，如 doubao-1.5-pro-256k
+var windowSuffix = regexp.MustCompile(`(?i)-(\d+)k(?:-|$)`)
+
+// Lookup 依次按 "provider/model"、"provider" 查找估算参数，都没有时返回 Approx。
+// 模型名带有 -32k、-256k 之类的后缀时，以后缀作为上下文窗口
+func Lookup(provider, modelName string) *Tokenizer {
+	registryMu.RLock()
+	t, ok := registry[provider+"/"+modelName]
+	if !ok {
+		t, ok = registry[provider]
+	}
+	registryMu.RUnlock()
+	if !ok {
+		t = Approx
+	}
+
+	if m := windowSuffix.FindStringSubmatch(modelName); m != nil {
+		if k, err := strconv.Atoi(m[1]); err == nil && k > 0 {
+			c := *t
+			c.ContextWindow = k * 1000
+			return &c
+		}
+	}
+	return t
+}
+
+// Count 估算一段文本的 token 数
+func (t *Tokenizer) Count(s string) int {
+	return int(math.Ceil(t.count(s)))
+}
+
+func (t *Tokenizer) count(s string) float64 {
+	var ascii, cjk, other int
+	for _, c := range s {
+		switch {
+		case c < utf8.RuneSelf:
+			ascii++
+		case isCJK(c):
+			cjk++
+		default:
+			other++
+		}
+	}
+	return float64(ascii)*t.ASCII + float64(cjk)*t.CJK + float64(other)*t.Other
+}
+
+// isCJK 中日韩文字及全角标点
+func isCJK(c rune) bool {
+	return unicode.In(c, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(c >= 0x3000 && c <= 0x303f) || (c >= 0xff00 && c <= 0xffef)
+}
+
+// Message 估算一条消息的 token 数：正文、思考过程、工具调用和固定开销
+func (t *Tokenizer) Message(m *schema.Message) int {
+	if m == nil {
+		return 0
+	}
+	n := t.count(m.Content) + t.count(m.ReasoningContent)
+	for _, tc := range m.ToolCalls {
+		n += t.count(tc.Function.Name) + t.count(tc.Function.Arguments)
+	}
+	return int(math.Ceil(n)) + t.PerMessage
+}
+
+// Messages 估算多条消息的 token 总数
+func (t *Tokenizer) Messages(msgs []*schema.Message) int {
+	n := 0
+	for _, m := range msgs {
+		n += t.Message(m)
+	}
+	return n
+}
+
+// Count 用 Approx 估算一段文本的 token 数
+func Count(s string) int {
+	return Approx.Count(s)
+}
+
+// Messages 用 Approx 估算多条消息的 token 总数，可以用作 memory.Estimator
+func Messages(msgs []*schema.Message) int {
+	return Approx.Messages(msgs)
+}
+
+// Truncate 截取 s 的前缀，使其估算值不超过 n 个 token；截断处尽量落在换行处
+func (t *Tokenizer) Truncate(s string, n int) string {
+	if t.Count(s) <= n {
+		return s
+	}
+	var used float64
+	for i, c := range s {
+		w := t.Other
+		switch {
+		case c < utf8.RuneSelf:
+			w = t.ASCII
+		case isCJK(c):
+			w = t.CJK
+		}
+		if used+w > float64(n) {
+			s = s[:i]
+			break
+		}
+		used += w
+	}
+	if j := strings.LastIndexByte(s, '\n'); j > len(s)/2 {
+		s = s[:j]
+	}
+	return s
+}
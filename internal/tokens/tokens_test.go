@@ -0,0 +1,37 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestApproxCount(t *testing.T) {
+	cases := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"你好", 2},
+		{"hi 你好。", 4},
+	}
+	for _, c := range cases {
+		if got := Count(c.s); got != c.want {
+			t.Errorf("Count(%q) = %d，期望 %d", c.s, got, c.want)
+		}
+	}
+}
+
+func TestMessages(t *testing.T) {
+	msg := schema.AssistantMessage("abcd", []schema.ToolCall{{Function: schema.FunctionCall{Name: "calc", Arguments: "{}"}}})
+	msg.ReasoningContent = "想"
+	// 正文 1 + 思考 1 + 工具名 1 + 参数 0.5，合计向上取整
+	if got := Messages([]*schema.Message{msg, nil}); got != 4 {
+		t.Errorf("Messages = %d，期望 4", got)
+	}
+	if got := DeepSeek.Message(schema.UserMessage("")); got != DeepSeek.PerMessage {
+		t.Errorf("空消息 = %d，期望固定开销 %d", got, DeepSeek.PerMessage)
+	}
+}
@@ -0,0 +1,209 @@
+// Package tools 收集第 4 章示例使用的工具，供这些示例、命令行和其它章节按名称复用。
+//
+// 内置 calculator、get_current_time、get_weather 三个工具，可以用 Register 注册更多工具。
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/schema"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]tool.InvokableTool{}
+)
+
+func init() {
+	for _, t := range []tool.InvokableTool{Calculator(), CurrentTime(), Weather()} {
+		if err := Register(t); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register 按工具名称注册，同名时覆盖
+func Register(t tool.InvokableTool) error {
+	info, err := t.Info(context.Background())
+	if err != nil {
+		return fmt.Errorf("获取工具信息失败: %w", err)
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[info.Name] = t
+	return nil
+}
+
+// Names 返回已注册的工具名称（已排序）
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get 按名称取出工具，names 为空时返回全部工具
+func Get(names ...string) ([]tool.BaseTool, error) {
+	if len(names) == 0 {
+		names = Names()
+	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	out := make([]tool.BaseTool, 0, len(names))
+	for _, name := range names {
+		t, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("未知的工具 %q，可用: %s", name, strings.Join(sortedKeys(registry), ", "))
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// Infos 返回工具的 ToolInfo，用于 model.WithTools 或 WithTools
+func Infos(ctx context.Context, ts []tool.BaseTool) ([]*schema.ToolInfo, error) {
+	infos := make([]*schema.ToolInfo, 0, len(ts))
+	for _, t := range ts {
+		info, err := t.Info(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取工具信息失败: %w", err)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func sortedKeys(m map[string]tool.InvokableTool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CalculatorParams 计算器参数
+type CalculatorParams struct {
+	Operation string  `json:"operation"`
+	A         float64 `json:"a"`
+	B         float64 `json:"b"`
+}
+
+// CalculatorResult 计算结果，出错时只填写 Error
+type CalculatorResult struct {
+	Result float64 `json:"result"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// Calculator 加减乘除计算器
+func Calculator() tool.InvokableTool {
+	return utils.NewTool(&schema.ToolInfo{
+		Name: "calculator",
+		Desc: "执行基本的数学计算，如加减乘除。",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"operation": {
+				Type:     schema.String,
+				Desc:     "运算类型: add(加), subtract(减), multiply(乘), divide(除)",
+				Enum:     []string{"add", "subtract", "multiply", "divide"},
+				Required: true,
+			},
+			"a": {Type: schema.Number, Desc: "第一个数字", Required: true},
+			"b": {Type: schema.Number, Desc: "第二个数字", Required: true},
+		}),
+	}, func(ctx context.Context, p *CalculatorParams) (*CalculatorResult, error) {
+		// 计算错误返回给模型，而不是中断对话
+		switch p.Operation {
+		case "add":
+			return &CalculatorResult{Result: p.A + p.B}, nil
+		case "subtract":
+			return &CalculatorResult{Result: p.A - p.B}, nil
+		case "multiply":
+			return &CalculatorResult{Result: p.A * p.B}, nil
+		case "divide":
+			if p.B == 0 {
+				return &CalculatorResult{Error: "division by zero"}, nil
+			}
+			return &CalculatorResult{Result: p.A / p.B}, nil
+		default:
+			return &CalculatorResult{Error: "unsupported operation: " + p.Operation}, nil
+		}
+	})
+}
+
+// TimeParams 时间工具参数
+type TimeParams struct {
+	Format string `json:"format"`
+}
+
+// TimeResult 当前时间
+type TimeResult struct {
+	CurrentTime string `json:"current_time"`
+}
+
+// CurrentTime 获取当前时间
+func CurrentTime() tool.InvokableTool {
+	return utils.NewTool(&schema.ToolInfo{
+		Name: "get_current_time",
+		Desc: "获取当前时间，支持不同格式（date, time, datetime）。",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"format": {
+				Type: schema.String,
+				Desc: "时间格式: date(日期), time(时间), datetime(完整时间)",
+				Enum: []string{"date", "time", "datetime"},
+			},
+		}),
+	}, func(ctx context.Context, p *TimeParams) (*TimeResult, error) {
+		now := time.Now()
+		switch p.Format {
+		case "date":
+			return &TimeResult{CurrentTime: now.Format("2006-01-02")}, nil
+		case "time":
+			return &TimeResult{CurrentTime: now.Format("15:04:05")}, nil
+		default:
+			return &TimeResult{CurrentTime: now.Format("2006-01-02 15:04:05")}, nil
+		}
+	})
+}
+
+// WeatherParams 天气工具参数
+type WeatherParams struct {
+	City string `json:"city"`
+}
+
+// weatherData 模拟的天气数据
+var weatherData = map[string]map[string]string{
+	"北京": {"temperature": "25°C", "condition": "晴朗", "humidity": "40%", "wind": "北风3级"},
+	"上海": {"temperature": "28°C", "condition": "多云", "humidity": "60%", "wind": "东风2级"},
+	"广州": {"temperature": "30°C", "condition": "雷阵雨", "humidity": "80%", "wind": "南风4级"},
+}
+
+// Weather 查询城市天气（模拟数据）
+func Weather() tool.InvokableTool {
+	return utils.NewTool(&schema.ToolInfo{
+		Name: "get_weather",
+		Desc: "查询指定城市的当前天气信息。",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"city": {
+				Type:     schema.String,
+				Desc:     "要查询天气的城市名称，例如：北京、上海、广州。",
+				Required: true,
+			},
+		}),
+	}, func(ctx context.Context, p *WeatherParams) (map[string]string, error) {
+		if w, ok := weatherData[p.City]; ok {
+			return w, nil
+		}
+		return map[string]string{"error": "未找到该城市的天气信息。"}, nil
+	})
+}
@@ -0,0 +1,118 @@
+package translate
+
+import (
+	"strings"
+	"unicode"
+)
+
+// languageNames 语言代码对应的名称，用于提示词
+var languageNames = map[string]string{
+	"zh": "中文",
+	"en": "English",
+	"ja": "日本語",
+	"ko": "한국어",
+	"fr": "Français",
+	"de": "Deutsch",
+	"es": "Español",
+	"ru": "Русский",
+}
+
+// languageAliases 目标语言的常见写法
+var languageAliases = map[string]string{
+	"中文": "zh", "汉语": "zh", "简体中文": "zh", "chinese": "zh",
+	"英文": "en", "英语": "en", "english": "en",
+	"日文": "ja", "日语": "ja", "japanese": "ja", "日本語": "ja",
+	"韩文": "ko", "韩语": "ko", "korean": "ko", "한국어": "ko",
+	"法语": "fr", "法文": "fr", "french": "fr", "français": "fr",
+	"德语": "de", "德文": "de", "german": "de", "deutsch": "de",
+	"西班牙语": "es", "spanish": "es", "español": "es",
+	"俄语": "ru", "俄文": "ru", "russian": "ru", "русский": "ru",
+}
+
+// NormalizeLanguage 把语言名称转换成语言代码，无法识别时原样返回
+func NormalizeLanguage(lang string) string {
+	l := strings.ToLower(strings.TrimSpace(lang))
+	if _, ok := languageNames[l]; ok {
+		return l
+	}
+	if code, ok := languageAliases[l]; ok {
+		return code
+	}
+	return lang
+}
+
+// LanguageName 返回语言代码对应的名称
+func LanguageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// 拉丁字母语言的常见词，用于区分英语、法语、德语、西班牙语
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "of", "to", "in", "with", "for", "this"},
+	"fr": {"le", "la", "les", "et", "est", "sont", "des", "une", "dans", "pour"},
+	"de": {"der", "die", "das", "und", "ist", "sind", "nicht", "ein", "mit", "für"},
+	"es": {"el", "los", "las", "y", "es", "son", "del", "una", "con", "para"},
+}
+
+// Detect 根据文字和常见词判断文本语言，返回语言代码。
+// 代码块、链接和占位符不参与判断；无法判断时返回 "en"
+func Detect(text string) string {
+	var prose strings.Builder
+	for _, seg := range segments(text) {
+		if seg.translate {
+			prose.WriteString(mask(seg.text).text)
+		}
+	}
+
+	var han, kana, hangul, cyrillic, latin int
+	for _, r := range prose.String() {
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	switch {
+	case kana > 0 && kana*5 >= han:
+		return "ja"
+	case hangul > 0 && hangul >= han:
+		return "ko"
+	// 中文每个字约等于几个拉丁字母，混排的技术文本以中文为主
+	case han*3 >= latin && han > 0:
+		return "zh"
+	case cyrillic > latin:
+		return "ru"
+	}
+
+	counts := map[string]int{}
+	words := strings.FieldsFunc(strings.ToLower(prose.String()), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		for lang, list := range stopwords {
+			for _, s := range list {
+				if w == s {
+					counts[lang]++
+				}
+			}
+		}
+	}
+	best := "en"
+	for _, lang := range []string{"fr", "de", "es"} {
+		if counts[lang] > counts[best] {
+			best = lang
+		}
+	}
+	return best
+}
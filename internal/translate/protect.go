@@ -0,0 +1,54 @@
+package translate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// protectedPattern 翻译时需要原样保留的内容：行内代码、链接地址、URL、模板占位符、格式化动词、HTML 标签
+var protectedPattern = regexp.MustCompile(strings.Join([]string{
+	"`[^`\n]+`",                            // 行内代码
+	`\]\([^)\s]+\)`,                        // Markdown 链接地址
+	`https?://[^\s)>\]\x{80}-\x{10FFFF}]+`, // URL，只取 ASCII 字符，紧跟的中文和全角标点不算在内
+	`\{\{[^{}]*\}\}`,                       // {{name}}
+	`\$\{[^{}]*\}`,                         // ${name}
+	`\{[A-Za-z_][A-Za-z0-9_.]*\}`,          // {name}
+	`%[-+# 0]*\d*(?:\.\d+)?[sdvfqxXt]`,     // %s %d %.2f
+	`</?[A-Za-z][^<>\n]*>`,                 // HTML 标签
+}, "|"))
+
+// masked 替换为占位标记后的文本
+type masked struct {
+	text   string
+	tokens []string
+}
+
+func token(i int) string {
+	return fmt.Sprintf("⟦%d⟧", i)
+}
+
+// mask 把需要保留的内容替换成 ⟦n⟧ 标记
+func mask(text string) masked {
+	var m masked
+	m.text = protectedPattern.ReplaceAllStringFunc(text, func(s string) string {
+		m.tokens = append(m.tokens, s)
+		return token(len(m.tokens) - 1)
+	})
+	return m
+}
+
+// unmask 还原标记，模型丢失或重复了标记时返回错误
+func (m masked) unmask(text string) (string, error) {
+	for i, orig := range m.tokens {
+		t := token(i)
+		switch n := strings.Count(text, t); {
+		case n == 0:
+			return "", fmt.Errorf("译文丢失了受保护内容 %q", orig)
+		case n > 1:
+			return "", fmt.Errorf("译文重复了受保护内容 %q", orig)
+		}
+		text = strings.Replace(text, t, orig, 1)
+	}
+	return text, nil
+}
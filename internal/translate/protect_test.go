@@ -0,0 +1,33 @@
+package translate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaskUnmask(t *testing.T) {
+	text := "运行 `go run .` 后访问 https://example.com/a，输出 {name} 和 %d 个<b>结果</b>"
+	m := mask(text)
+	want := "运行 ⟦0⟧ 后访问 ⟦1⟧，输出 ⟦2⟧ 和 ⟦3⟧ 个⟦4⟧结果⟦5⟧"
+	if m.text != want {
+		t.Fatalf("mask = %q，期望 %q", m.text, want)
+	}
+
+	// 译文中标记的顺序可以改变
+	got, err := m.unmask("Output ⟦2⟧ and ⟦3⟧ ⟦4⟧results⟦5⟧ after running ⟦0⟧ and visiting ⟦1⟧")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"`go run .`", "https://example.com/a", "{name}", "%d", "<b>results</b>"} {
+		if !strings.Contains(got, s) {
+			t.Errorf("unmask 结果缺少 %q: %s", s, got)
+		}
+	}
+
+	if _, err := m.unmask("⟦0⟧ ⟦1⟧ ⟦2⟧ ⟦3⟧ ⟦4⟧"); err == nil {
+		t.Error("丢失标记时应返回错误")
+	}
+	if _, err := m.unmask("⟦0⟧ ⟦0⟧ ⟦1⟧ ⟦2⟧ ⟦3⟧ ⟦4⟧ ⟦5⟧"); err == nil {
+		t.Error("重复标记时应返回错误")
+	}
+}
@@ -0,0 +1,187 @@
+package translate
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// segment 文档中的一段：需要翻译的段落，或原样保留的代码块、空白
+type segment struct {
+	text      string
+	translate bool
+}
+
+// segments 按段落切分文档，围栏代码块和段落之间的空白原样保留
+func segments(doc string) []segment {
+	var (
+		segs  []segment
+		buf   strings.Builder
+		kind  = -1 // -1 无，0 空白，1 段落，2 代码块
+		fence string
+	)
+	flush := func() {
+		if buf.Len() > 0 {
+			segs = append(segs, segment{text: buf.String(), translate: kind == 1})
+			buf.Reset()
+		}
+	}
+
+	for _, line := range strings.SplitAfter(doc, "\n") {
+		if line == "" {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			buf.WriteString(line)
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				flush()
+				kind = -1
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			kind = 2
+			fence = trimmed[:3]
+			buf.WriteString(line)
+		case trimmed == "":
+			if kind != 0 {
+				flush()
+				kind = 0
+			}
+			buf.WriteString(line)
+		default:
+			if kind != 1 {
+				flush()
+				kind = 1
+			}
+			buf.WriteString(line)
+		}
+	}
+	flush()
+	return segs
+}
+
+// piece 一次翻译请求的内容，或原样保留的文本
+type piece struct {
+	text      string
+	translate bool
+}
+
+// pieces 把相邻的段落合并成不超过 limit 个字符的块，超长段落按句子切分
+func pieces(doc string, limit int) []piece {
+	var (
+		out []piece
+		cur strings.Builder
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			out = append(out, piece{text: cur.String(), translate: true})
+			cur.Reset()
+		}
+	}
+
+	for _, seg := range segments(doc) {
+		switch {
+		case !seg.translate && strings.TrimSpace(seg.text) == "":
+			// 段落之间的空白：块内保留，块边界处原样输出
+			if cur.Len() > 0 && utf8.RuneCountInString(cur.String())+utf8.RuneCountInString(seg.text) < limit {
+				cur.WriteString(seg.text)
+			} else {
+				flush()
+				out = append(out, piece{text: seg.text})
+			}
+		case !seg.translate:
+			flush()
+			out = append(out, piece{text: seg.text})
+		case utf8.RuneCountInString(seg.text) > limit:
+			flush()
+			for _, s := range splitSentences(seg.text, limit) {
+				out = append(out, piece{text: s, translate: true})
+			}
+		default:
+			if utf8.RuneCountInString(cur.String())+utf8.RuneCountInString(seg.text) > limit {
+				flush()
+			}
+			cur.WriteString(seg.text)
+		}
+	}
+	flush()
+	return separateSpace(out)
+}
+
+// separateSpace 块首尾的空白移到块外，翻译时只发送正文。
+// 超长段落按句子切出的块以上一句后的空格开头，模型输出会被去掉首尾空白，留在块内就会丢失
+func separateSpace(ps []piece) []piece {
+	var out []piece
+	for _, p := range ps {
+		if !p.translate {
+			out = append(out, p)
+			continue
+		}
+		body := strings.Trim(p.text, " \t\n")
+		if body == "" {
+			out = append(out, piece{text: p.text})
+			continue
+		}
+		start := len(p.text) - len(strings.TrimLeft(p.text, " \t\n"))
+		if lead := p.text[:start]; lead != "" {
+			out = append(out, piece{text: lead})
+		}
+		out = append(out, piece{text: body, translate: true})
+		if tail := p.text[start+len(body):]; tail != "" {
+			out = append(out, piece{text: tail})
+		}
+	}
+	return out
+}
+
+// splitSentences 在句末标点处把 text 切成不超过 limit 个字符的若干块；单句超长时按字符硬切
+func splitSentences(text string, limit int) []string {
+	var (
+		sentences []string
+		start     int
+	)
+	runes := []rune(text)
+	for i, r := range runes {
+		end := false
+		switch r {
+		case '。', '！', '？', '；', '\n':
+			end = true
+		case '.', '!', '?', ';':
+			end = i+1 == len(runes) || runes[i+1] == ' ' || runes[i+1] == '\n'
+		}
+		if end {
+			sentences = append(sentences, string(runes[start:i+1]))
+			start = i + 1
+		}
+	}
+	if start < len(runes) {
+		sentences = append(sentences, string(runes[start:]))
+	}
+
+	var (
+		out []string
+		cur []rune
+	)
+	for _, s := range sentences {
+		sr := []rune(s)
+		if len(cur)+len(sr) > limit && len(cur) > 0 {
+			out = append(out, string(cur))
+			cur = nil
+		}
+		for len(sr) > limit {
+			out = append(out, string(sr[:limit]))
+			sr = sr[limit:]
+		}
+		cur = append(cur, sr...)
+	}
+	if len(cur) > 0 {
+		out = append(out, string(cur))
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package translate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSegments(t *testing.T) {
+	doc := "# 标题\n\n段落一\n段落一续\n\n```go\ncode\n\n```\n尾段"
+	want := []segment{
+		{"# 标题\n", true},
+		{"\n", false},
+		{"段落一\n段落一续\n", true},
+		{"\n", false},
+		{"```go\ncode\n\n```\n", false},
+		{"尾段", true},
+	}
+	if got := segments(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("segments =\n%+v\n期望\n%+v", got, want)
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	cases := []struct {
+		text  string
+		limit int
+		want  []string
+	}{
+		{"First one. Second one. Third.", 12, []string{"First one.", " Second one.", " Third."}},
+		{"Pi is 3.14. Done.", 100, []string{"Pi is 3.14. Done."}},
+		{"第一句。第二句！第三句？", 4, []string{"第一句。", "第二句！", "第三句？"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+	}
+	for _, c := range cases {
+		if got := splitSentences(c.text, c.limit); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("splitSentences(%q, %d) = %q，期望 %q", c.text, c.limit, got, c.want)
+		}
+	}
+}
+
+func TestPieces(t *testing.T) {
+	cases := []struct {
+		doc   string
+		limit int
+		want  []piece
+	}{
+		// 超长段落按句子切分，句子之间的空格留在块外
+		{"First one. Second one. Third.", 12, []piece{
+			{"First one.", true}, {" ", false}, {"Second one.", true}, {" ", false}, {"Third.", true},
+		}},
+		// 相邻段落合并成一块，块末尾的换行留在块外
+		{"a\n\nb\n", 100, []piece{{"a\n\nb", true}, {"\n", false}}},
+		// 代码块原样保留
+		{"说明\n```\nx := 1\n```\n", 100, []piece{{"说明", true}, {"\n", false}, {"```\nx := 1\n```\n", false}}},
+	}
+	for _, c := range cases {
+		got := pieces(c.doc, c.limit)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("pieces(%q) =\n%+v\n期望\n%+v", c.doc, got, c.want)
+		}
+		var sb strings.Builder
+		for _, p := range got {
+			sb.WriteString(p.text)
+		}
+		if sb.String() != c.doc {
+			t.Errorf("pieces(%q) 拼接后 = %q", c.doc, sb.String())
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Package translate 提供文档级翻译：按段落 / 句子切块并发翻译，强制术语表，
+// 保留 Markdown、代码块和占位符，自动识别源语言。
+package translate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Glossary 术语表：原文术语 -> 指定译法
+type Glossary map[string]string
+
+// Config 翻译器配置，零值字段使用默认值
+type Config struct {
+	Model model.BaseChatModel
+	// Glossary 术语表，译文必须使用指定译法
+	Glossary Glossary
+	// ChunkSize 每次请求的最大字符数，默认 1500
+	ChunkSize int
+	// Concurrency 并发请求数，默认 4
+	Concurrency int
+	// MaxRepairs 译文丢失占位符或违反术语表时的重试次数，默认 2
+	MaxRepairs int
+}
+
+// Translator 文档翻译器
+type Translator struct {
+	cm          model.BaseChatModel
+	glossary    Glossary
+	chunkSize   int
+	concurrency int
+	maxRepairs  int
+}
+
+// New 创建翻译器
+func New(cfg *Config) (*Translator, error) {
+	if cfg == nil || cfg.Model == nil {
+		return nil, errors.New("translate: 缺少模型")
+	}
+	t := &Translator{
+		cm:          cfg.Model,
+		glossary:    cfg.Glossary,
+		chunkSize:   cfg.ChunkSize,
+		concurrency: cfg.Concurrency,
+		maxRepairs:  cfg.MaxRepairs,
+	}
+	if t.chunkSize <= 0 {
+		t.chunkSize = 1500
+	}
+	if t.concurrency <= 0 {
+		t.concurrency = 4
+	}
+	if t.maxRepairs <= 0 {
+		t.maxRepairs = 2
+	}
+	return t, nil
+}
+
+// Translate 把 text 翻译成 targetLang（语言代码或名称，如 "zh"、"中文"、"English"）。
+// 源语言与目标语言相同时原样返回
+func (t *Translator) Translate(ctx context.Context, text, targetLang string) (string, error) {
+	src, dst := Detect(text), NormalizeLanguage(targetLang)
+	if src == dst {
+		return text, nil
+	}
+
+	ps := pieces(text, t.chunkSize)
+	out := make([]string, len(ps))
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, t.concurrency)
+	)
+	for i, p := range ps {
+		if !p.translate {
+			out[i] = p.text
+			continue
+		}
+		wg.Add(1)
+		go func(i int, text string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			res, err := t.translateChunk(ctx, text, src, dst)
+			if err != nil {
+				cancel(fmt.Errorf("翻译第 %d 块失败: %w", i+1, err))
+				return
+			}
+			out[i] = res
+		}(i, p.text)
+	}
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return "", err
+	}
+	return strings.Join(out, ""), nil
+}
+
+func (t *Translator) translateChunk(ctx context.Context, text, src, dst string) (string, error) {
+	m := mask(text)
+	terms := t.glossary.relevant(m.text)
+	messages := []*schema.Message{
+		schema.SystemMessage(systemPrompt(src, dst, terms)),
+		schema.UserMessage(m.text),
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.cm.Generate(ctx, messages)
+		if err != nil {
+			return "", err
+		}
+		out, err := m.unmask(strings.TrimSpace(resp.Content))
+		if err == nil {
+			err = terms.check(out)
+		}
+		if err == nil {
+			return out, nil
+		}
+		if attempt >= t.maxRepairs {
+			return "", err
+		}
+		// 把问题反馈给模型，重新翻译
+		messages = append(messages, resp,
+			schema.UserMessage(fmt.Sprintf("译文有问题：%v。请重新翻译上面的原文并修正，只输出译文。", err)))
+	}
+}
+
+func systemPrompt(src, dst string, terms Glossary) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "你是专业的翻译。请把用户提供的%s文本翻译成%s。要求：\n", LanguageName(src), LanguageName(dst))
+	sb.WriteString("1. 只输出译文，不要添加任何解释。\n")
+	sb.WriteString("2. 保留原文的 Markdown 格式（标题、列表、表格、强调、链接）和换行。\n")
+	sb.WriteString("3. 形如 ⟦0⟧ 的标记是受保护的内容，必须原样出现在译文的对应位置，不能翻译、删除或增加。\n")
+	if len(terms) > 0 {
+		sb.WriteString("4. 以下术语必须使用指定的译法：\n")
+		for _, src := range terms.keys() {
+			fmt.Fprintf(&sb, "   - %s → %s\n", src, terms[src])
+		}
+	}
+	return sb.String()
+}
+
+// relevant 返回在 text 中出现的术语
+func (g Glossary) relevant(text string) Glossary {
+	lower := strings.ToLower(text)
+	out := Glossary{}
+	for src, dst := range g {
+		if strings.Contains(lower, strings.ToLower(src)) {
+			out[src] = dst
+		}
+	}
+	return out
+}
+
+// check 译文中缺少指定译法时返回错误
+func (g Glossary) check(translated string) error {
+	lower := strings.ToLower(translated)
+	var missing []string
+	for _, src := range g.keys() {
+		if !strings.Contains(lower, strings.ToLower(g[src])) {
+			missing = append(missing, fmt.Sprintf("%s 应译为 %s", src, g[src]))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("未使用术语表译法（%s）", strings.Join(missing, "；"))
+	}
+	return nil
+}
+
+func (g Glossary) keys() []string {
+	keys := make([]string, 0, len(g))
+	for k := range g {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
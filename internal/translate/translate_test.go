@@ -0,0 +1,53 @@
+package translate
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// echo 原样返回要翻译的文本，并在前后加上空白，模拟模型输出多余的换行
+type echo struct {
+	mu     sync.Mutex
+	inputs []string
+}
+
+func (m *echo) Generate(_ context.Context, in []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	text := in[len(in)-1].Content
+	m.mu.Lock()
+	m.inputs = append(m.inputs, text)
+	m.mu.Unlock()
+	return schema.AssistantMessage("\n"+text+"\n", nil), nil
+}
+
+func (m *echo) Stream(context.Context, []*schema.Message, ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	panic("不应调用 Stream")
+}
+
+func TestTranslateKeepsSpaceBetweenChunks(t *testing.T) {
+	m := &echo{}
+	tr, err := New(&Config{Model: m, ChunkSize: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := "First one. Second one. Third.\n\n```\ncode\n```\n"
+	got, err := tr.Translate(context.Background(), doc, "中文")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != doc {
+		t.Errorf("Translate = %q，期望与原文结构相同 %q", got, doc)
+	}
+	if len(m.inputs) != 3 {
+		t.Errorf("请求 %d 次，期望 3 次: %q", len(m.inputs), m.inputs)
+	}
+	for _, in := range m.inputs {
+		if in != strings.TrimSpace(in) {
+			t.Errorf("发送的块 %q 不应带首尾空白", in)
+		}
+	}
+}
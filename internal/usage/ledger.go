@@ -0,0 +1,296 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/cache"
+	"eino-tutorial/internal/modelcb"
+	"eino-tutorial/internal/reasoning"
+)
+
+// Config 账本配置
+type Config struct {
+	// Prices 按模型名称配置的单价，未配置的模型费用记为 0
+	Prices map[string]Price
+	// Budget 整个账本的硬性预算
+	Budget Budget
+	// OnRecord 每次模型调用记录后调用，可用于打印日志
+	OnRecord func(rec *Record)
+}
+
+// Record 一次模型调用的用量
+type Record struct {
+	Run     string
+	Session string
+	Agent   string
+	Node    string
+	Model   string
+	Usage   Usage
+}
+
+// Ledger 用量账本，可在多个 goroutine 中共享
+type Ledger struct {
+	prices   map[string]Price
+	budget   Budget
+	onRecord func(rec *Record)
+
+	mu       sync.Mutex
+	total    Usage
+	models   map[string]*Usage
+	runs     map[string]*Usage
+	sessions map[string]*Usage
+	agents   map[string]*Usage
+	nodes    map[string]*Usage
+	cancels  map[int]context.CancelCauseFunc
+	nextID   int
+	exceeded bool
+
+	// streams 尚未读完的流式输出
+	streams sync.WaitGroup
+}
+
+// New 创建账本，cfg 可以为 nil
+func New(cfg *Config) *Ledger {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	return &Ledger{
+		prices:   cfg.Prices,
+		budget:   cfg.Budget,
+		onRecord: cfg.OnRecord,
+		models:   map[string]*Usage{},
+		runs:     map[string]*Usage{},
+		sessions: map[string]*Usage{},
+		agents:   map[string]*Usage{},
+		nodes:    map[string]*Usage{},
+		cancels:  map[int]context.CancelCauseFunc{},
+	}
+}
+
+// Bind 返回挂好账本回调的 ctx。
+//
+// 超出预算时返回的 ctx 以 ErrBudgetExceeded 取消。ctx 上已有的非全局回调处理器会被替换，
+// 需要同时使用其它处理器时，可以改用 Handler 配合 compose.WithCallbacks 或 callbacks.AppendGlobalHandlers。
+func (l *Ledger) Bind(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	l.mu.Lock()
+	id := l.nextID
+	l.nextID++
+	if l.exceeded {
+		cancel(ErrBudgetExceeded)
+	} else {
+		l.cancels[id] = cancel
+	}
+	l.mu.Unlock()
+
+	ctx = callbacks.InitCallbacks(ctx, nil, l.Handler())
+	return ctx, func() {
+		l.mu.Lock()
+		delete(l.cancels, id)
+		l.mu.Unlock()
+		cancel(context.Canceled)
+	}
+}
+
+type modelNameKey struct{}
+
+// Handler 返回记录模型调用用量的回调处理器
+func (l *Ledger) Handler() callbacks.Handler {
+	return callbacks.NewHandlerBuilder().
+		OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+			// 部分模型只在输入回调中给出模型名称
+			if in, ok := input.(*model.CallbackInput); ok && in.Config != nil && in.Config.Model != "" {
+				return context.WithValue(ctx, modelNameKey{}, in.Config.Model)
+			}
+			return ctx
+		}).
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			if u, name, msg, ok := extract(info, output); ok {
+				l.record(ctx, info, name, u, reasoning.Text(msg))
+			}
+			return ctx
+		}).
+		OnEndWithStreamOutputFn(func(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[callbacks.CallbackOutput]) context.Context {
+			l.streams.Add(1)
+			go func() {
+				defer l.streams.Done()
+				defer output.Close()
+
+				// 用量通常只出现在最后一个分片，推理内容分散在各个分片中
+				var (
+					usage   *model.TokenUsage
+					name    string
+					found   bool
+					thought strings.Builder
+					content strings.Builder
+				)
+				for {
+					chunk, err := output.Recv()
+					if errors.Is(err, io.EOF) {
+						break
+					}
+					if err != nil {
+						return
+					}
+					u, n, msg, ok := extract(info, chunk)
+					if !ok {
+						continue
+					}
+					found = true
+					if msg != nil {
+						thought.WriteString(reasoning.Of(msg))
+						content.WriteString(msg.Content)
+					}
+					if u != nil {
+						usage = u
+					}
+					if n != "" {
+						name = n
+					}
+				}
+				if found {
+					full := &schema.Message{ReasoningContent: thought.String(), Content: content.String()}
+					l.record(ctx, info, name, usage, reasoning.Text(full))
+				}
+			}()
+			return ctx
+		}).
+		Build()
+}
+
+// extract 从回调输出中取出用量、模型名称和输出消息，ok 为 false 表示不是模型调用
+func extract(info *callbacks.RunInfo, output callbacks.CallbackOutput) (*model.TokenUsage, string, *schema.Message, bool) {
+	switch o := output.(type) {
+	case *model.CallbackOutput:
+		// 缓存未命中时真实调用由底层模型自己上报，缓存层的回调只计命中
+		if status, ok := cache.Status(o); ok && status != cache.StatusHit {
+			return nil, "", nil, false
+		}
+		// Lambda 中直接调用模型时 RunInfo 属于 Lambda，所以不按 Component 过滤
+		u := o.TokenUsage
+		if u == nil {
+			u = modelcb.Usage(o.Message)
+		}
+		var name string
+		if o.Config != nil {
+			name = o.Config.Model
+		}
+		return u, name, o.Message, true
+	case *schema.Message:
+		// 未实现回调的模型由 Graph 代为触发，输出为消息本身
+		if info == nil || info.Component != components.ComponentOfChatModel {
+			return nil, "", nil, false
+		}
+		return modelcb.Usage(o), "", o, true
+	default:
+		return nil, "", nil, false
+	}
+}
+
+// record 记录一次调用，thought 为输出的推理内容，提供方没有上报推理 token 时据此估算
+func (l *Ledger) record(ctx context.Context, info *callbacks.RunInfo, name string, tu *model.TokenUsage, thought string) {
+	if name == "" {
+		name, _ = ctx.Value(modelNameKey{}).(string)
+	}
+	if name == "" && info != nil {
+		name = info.Type
+	}
+	if name == "" {
+		name = "unknown"
+	}
+
+	rec := &Record{
+		Run:     runOf(ctx),
+		Session: sessionOf(ctx),
+		Model:   name,
+		Usage:   Usage{Calls: 1},
+	}
+	rec.Agent, rec.Node = location(ctx)
+	if tu != nil {
+		rec.Usage.PromptTokens = tu.PromptTokens
+		rec.Usage.CachedTokens = tu.PromptTokenDetails.CachedTokens
+		rec.Usage.CompletionTokens = tu.CompletionTokens
+		rec.Usage.ReasoningTokens = tu.CompletionTokensDetails.ReasoningTokens
+		rec.Usage.TotalTokens = tu.TotalTokens
+		if rec.Usage.TotalTokens == 0 {
+			rec.Usage.TotalTokens = tu.PromptTokens + tu.CompletionTokens
+		}
+		if rec.Usage.ReasoningTokens == 0 && thought != "" && tu.CompletionTokens > 0 {
+			rec.Usage.ReasoningTokens = reasoning.Tokens(thought, tu.CompletionTokens)
+		}
+	}
+	if p, ok := l.prices[name]; ok {
+		rec.Usage.Cost = p.Cost(&rec.Usage)
+	}
+
+	l.mu.Lock()
+	l.total.add(&rec.Usage)
+	addTo(l.models, rec.Model, &rec.Usage)
+	addTo(l.runs, rec.Run, &rec.Usage)
+	addTo(l.sessions, rec.Session, &rec.Usage)
+	addTo(l.agents, rec.Agent, &rec.Usage)
+	addTo(l.nodes, rec.Node, &rec.Usage)
+	if !l.exceeded && l.budget.exceeded(&l.total) {
+		l.exceeded = true
+		for id, cancel := range l.cancels {
+			cancel(ErrBudgetExceeded)
+			delete(l.cancels, id)
+		}
+	}
+	l.mu.Unlock()
+
+	if l.onRecord != nil {
+		l.onRecord(rec)
+	}
+}
+
+func addTo(m map[string]*Usage, key string, u *Usage) {
+	if key == "" {
+		return
+	}
+	acc, ok := m[key]
+	if !ok {
+		acc = &Usage{}
+		m[key] = acc
+	}
+	acc.add(u)
+}
+
+// location 从执行地址中取出最内层的 agent 和 node 名称
+func location(ctx context.Context) (agent, node string) {
+	for _, seg := range compose.GetCurrentAddress(ctx) {
+		switch seg.Type {
+		case adk.AddressSegmentAgent:
+			agent = seg.ID
+		case compose.AddressSegmentNode:
+			node = seg.ID
+		}
+	}
+	return agent, node
+}
+
+// Exceeded 是否已经超出预算
+func (l *Ledger) Exceeded() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.exceeded
+}
+
+// Total 当前的累计用量
+func (l *Ledger) Total() Usage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.total
+}
@@ -0,0 +1,217 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"eino-tutorial/internal/modelcb"
+)
+
+// fakeModel 通过 modelcb 触发回调，回复中带上固定的用量
+type fakeModel struct {
+	usage schema.TokenUsage
+}
+
+func (m *fakeModel) reply() *schema.Message {
+	u := m.usage
+	msg := schema.AssistantMessage("好的", nil)
+	msg.ResponseMeta = &schema.ResponseMeta{Usage: &u}
+	return msg
+}
+
+func (m *fakeModel) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return modelcb.Generate(ctx, "Fake", in, opts, func(context.Context) (*schema.Message, error) {
+		return m.reply(), nil
+	})
+}
+
+// Stream 用量只出现在最后一个分片
+func (m *fakeModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return modelcb.Stream(ctx, "Fake", in, opts, func(context.Context) (*schema.StreamReader[*schema.Message], error) {
+		last := m.reply()
+		last.Content = "的"
+		return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage("好", nil), last}), nil
+	})
+}
+
+func (m *fakeModel) GetType() string {
+	return "Fake"
+}
+
+func (m *fakeModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+func usageOf(prompt, cached, completion int) schema.TokenUsage {
+	return schema.TokenUsage{
+		PromptTokens:       prompt,
+		PromptTokenDetails: schema.PromptTokenDetails{CachedTokens: cached},
+		CompletionTokens:   completion,
+		TotalTokens:        prompt + completion,
+	}
+}
+
+func TestLedgerTotals(t *testing.T) {
+	ledger := New(&Config{Prices: DefaultPrices()})
+	ctx, cancel := ledger.Bind(context.Background())
+	defer cancel()
+
+	cm := &fakeModel{usage: usageOf(1000, 400, 500)}
+	in := []*schema.Message{schema.UserMessage("你好")}
+	chat := model.WithModel("deepseek-chat")
+
+	run1 := WithSession(WithRun(ctx, "run-1"), "s1")
+	if _, err := cm.Generate(run1, in, chat); err != nil {
+		t.Fatal(err)
+	}
+	sr, err := cm.Stream(run1, in, chat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drain(sr)
+	if _, err := cm.Generate(WithRun(ctx, "run-2"), in, model.WithModel("other")); err != nil {
+		t.Fatal(err)
+	}
+
+	r := ledger.Report()
+	cost := (600*2 + 400*0.2 + 500*3) / 1e6
+	want := Usage{Calls: 3, PromptTokens: 3000, CachedTokens: 1200, CompletionTokens: 1500, TotalTokens: 4500, Cost: 2 * cost}
+	if !sameUsage(r.Total, want) {
+		t.Errorf("Total = %+v，期望 %+v", r.Total, want)
+	}
+	if u := r.Models["deepseek-chat"]; u == nil || u.Calls != 2 || !near(u.Cost, 2*cost) {
+		t.Errorf("deepseek-chat = %+v", u)
+	}
+	if u := r.Models["other"]; u == nil || u.Calls != 1 || u.Cost != 0 {
+		t.Errorf("没有单价的模型费用应为 0: %+v", u)
+	}
+	if r.Runs["run-1"].Calls != 2 || r.Runs["run-2"].Calls != 1 || len(r.Sessions) != 1 || r.Sessions["s1"].TotalTokens != 3000 {
+		t.Errorf("Runs = %+v，Sessions = %+v", r.Runs, r.Sessions)
+	}
+	if r.Budget != nil || r.Exceeded {
+		t.Errorf("未设置预算时 Budget = %+v，Exceeded = %v", r.Budget, r.Exceeded)
+	}
+
+	// 报告是快照，修改不影响账本
+	r.Models["other"].Calls = 100
+	if ledger.Report().Models["other"].Calls != 1 {
+		t.Error("修改报告不应影响账本")
+	}
+}
+
+func TestLedgerNodes(t *testing.T) {
+	ledger := New(nil)
+	ctx, cancel := ledger.Bind(context.Background())
+	defer cancel()
+
+	g := compose.NewGraph[[]*schema.Message, *schema.Message]()
+	_ = g.AddChatModelNode("answer", &fakeModel{usage: usageOf(10, 0, 5)})
+	_ = g.AddEdge(compose.START, "answer")
+	_ = g.AddEdge("answer", compose.END)
+	r, err := g.Compile(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Invoke(ctx, []*schema.Message{schema.UserMessage("你好")}); err != nil {
+		t.Fatal(err)
+	}
+	report := ledger.Report()
+	if u := report.Nodes["answer"]; u == nil || u.TotalTokens != 15 {
+		t.Errorf("Nodes = %+v", report.Nodes)
+	}
+	if u := report.Models["Fake"]; u == nil || u.Calls != 1 {
+		t.Errorf("没有模型名称时应按组件类型记录: %+v", report.Models)
+	}
+}
+
+func TestLedgerBudget(t *testing.T) {
+	var records []*Record
+	ledger := New(&Config{Budget: Budget{MaxTokens: 200}, OnRecord: func(rec *Record) { records = append(records, rec) }})
+	ctx, cancel := ledger.Bind(context.Background())
+	defer cancel()
+
+	cm := &fakeModel{usage: usageOf(100, 0, 50)}
+	in := []*schema.Message{schema.UserMessage("你好")}
+	if _, err := cm.Generate(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	if ledger.Exceeded() || ctx.Err() != nil {
+		t.Fatal("150 token 未超出 200 的预算")
+	}
+	if _, err := cm.Generate(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	if !ledger.Exceeded() || !errors.Is(context.Cause(ctx), ErrBudgetExceeded) {
+		t.Fatalf("超出预算后 ctx 应以 ErrBudgetExceeded 取消: %v", context.Cause(ctx))
+	}
+	if len(records) != 2 || records[1].Usage.TotalTokens != 150 {
+		t.Errorf("OnRecord 收到 %d 条记录", len(records))
+	}
+
+	// 超出预算后新绑定的 ctx 立即取消
+	ctx2, cancel2 := ledger.Bind(context.Background())
+	defer cancel2()
+	if !errors.Is(context.Cause(ctx2), ErrBudgetExceeded) {
+		t.Errorf("超出预算后 Bind 的 ctx 应已取消: %v", context.Cause(ctx2))
+	}
+
+	var buf bytes.Buffer
+	r := ledger.Report()
+	if err := r.WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"total", "300", "预算: tokens 200", "已超出"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("表格缺少 %q:\n%s", want, out)
+		}
+	}
+	buf.Reset()
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded.Total.TotalTokens != 300 || !decoded.Exceeded {
+		t.Errorf("JSON 报告 = %s (%v)", buf.String(), err)
+	}
+}
+
+func TestPriceCost(t *testing.T) {
+	u := &Usage{PromptTokens: 1_000_000, CachedTokens: 500_000, CompletionTokens: 1_000_000}
+	if got := (Price{Input: 2, CachedInput: 0.2, Output: 3}).Cost(u); !near(got, 1+0.1+3) {
+		t.Errorf("Cost = %v", got)
+	}
+	// 未配置缓存单价时按输入单价计
+	if got := (Price{Input: 2, Output: 3}).Cost(u); !near(got, 2+3) {
+		t.Errorf("Cost = %v", got)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-12
+}
+
+func sameUsage(a, b Usage) bool {
+	cost := near(a.Cost, b.Cost)
+	a.Cost, b.Cost = 0, 0
+	return cost && a == b
+}
+
+// drain 读完并关闭消息流
+func drain(sr *schema.StreamReader[*schema.Message]) {
+	defer sr.Close()
+	for {
+		if _, err := sr.Recv(); err != nil {
+			return
+		}
+	}
+}
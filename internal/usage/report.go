@@ -0,0 +1,110 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Report 账本快照
+type Report struct {
+	Total    Usage             `json:"total"`
+	Budget   *Budget           `json:"budget,omitempty"`
+	Exceeded bool              `json:"exceeded"`
+	Models   map[string]*Usage `json:"models,omitempty"`
+	Runs     map[string]*Usage `json:"runs,omitempty"`
+	Sessions map[string]*Usage `json:"sessions,omitempty"`
+	Agents   map[string]*Usage `json:"agents,omitempty"`
+	Nodes    map[string]*Usage `json:"nodes,omitempty"`
+}
+
+// Report 生成当前的用量报告。
+//
+// 会等待尚未读完的流式输出统计完成，所以调用前需要把流读完或关闭。
+func (l *Ledger) Report() *Report {
+	l.streams.Wait()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r := &Report{
+		Total:    l.total,
+		Exceeded: l.exceeded,
+		Models:   copyUsages(l.models),
+		Runs:     copyUsages(l.runs),
+		Sessions: copyUsages(l.sessions),
+		Agents:   copyUsages(l.agents),
+		Nodes:    copyUsages(l.nodes),
+	}
+	if l.budget != (Budget{}) {
+		b := l.budget
+		r.Budget = &b
+	}
+	return r
+}
+
+func copyUsages(m map[string]*Usage) map[string]*Usage {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]*Usage, len(m))
+	for k, v := range m {
+		u := *v
+		out[k] = &u
+	}
+	return out
+}
+
+// WriteJSON 以缩进 JSON 输出报告
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteTable 以表格输出报告
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "维度\t名称\t调用\t输入\t缓存\t输出\t推理\t总计\t费用\t")
+
+	sections := []struct {
+		name string
+		m    map[string]*Usage
+	}{
+		{"model", r.Models},
+		{"run", r.Runs},
+		{"session", r.Sessions},
+		{"agent", r.Agents},
+		{"node", r.Nodes},
+	}
+	for _, s := range sections {
+		keys := make([]string, 0, len(s.m))
+		for k := range s.m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			writeRow(tw, s.name, k, s.m[k])
+		}
+	}
+	writeRow(tw, "total", "", &r.Total)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if r.Budget != nil {
+		status := "未超出"
+		if r.Exceeded {
+			status = "已超出"
+		}
+		_, err := fmt.Fprintf(w, "预算: tokens %d, 费用 %.4f（%s）\n", r.Budget.MaxTokens, r.Budget.MaxCost, status)
+		return err
+	}
+	return nil
+}
+
+func writeRow(w io.Writer, dim, name string, u *Usage) {
+	fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%.4f\t\n",
+		dim, name, u.Calls, u.PromptTokens, u.CachedTokens, u.CompletionTokens, u.ReasoningTokens, u.TotalTokens, u.Cost)
+}
@@ -0,0 +1,98 @@
+// Package usage 基于回调统计 ChatModel 的 token 用量与费用。
+//
+// Ledger 提供一个 callbacks.Handler，挂到 ctx、compose.WithCallbacks 或全局回调上之后，
+// Chain、Graph、ReAct Agent 和 ADK Runner 中的每次模型调用都会被记录，
+// 并按 run、session、agent、node、model 分别汇总。
+// 超过预算时，通过 Bind 得到的 ctx 会以 ErrBudgetExceeded 取消，正在进行和后续的调用都会失败。
+package usage
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrBudgetExceeded 用量超过预算时 ctx 的取消原因，可用 context.Cause 取得
+var ErrBudgetExceeded = errors.New("usage: 超出预算")
+
+// Usage 一组调用的累计用量
+type Usage struct {
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CachedTokens     int     `json:"cached_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	ReasoningTokens  int     `json:"reasoning_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+func (u *Usage) add(o *Usage) {
+	u.Calls += o.Calls
+	u.PromptTokens += o.PromptTokens
+	u.CachedTokens += o.CachedTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.ReasoningTokens += o.ReasoningTokens
+	u.TotalTokens += o.TotalTokens
+	u.Cost += o.Cost
+}
+
+// Price 模型单价，单位为每百万 token，币种由使用方约定
+type Price struct {
+	// Input 未命中缓存的输入
+	Input float64 `json:"input" yaml:"input"`
+	// CachedInput 命中缓存的输入，0 时按 Input 计价
+	CachedInput float64 `json:"cached_input" yaml:"cached_input"`
+	// Output 输出（包括推理内容）
+	Output float64 `json:"output" yaml:"output"`
+}
+
+// Cost 按单价计算 u 的费用
+func (p Price) Cost(u *Usage) float64 {
+	cachedPrice := p.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = p.Input
+	}
+	uncached := u.PromptTokens - u.CachedTokens
+	return (float64(uncached)*p.Input + float64(u.CachedTokens)*cachedPrice + float64(u.CompletionTokens)*p.Output) / 1e6
+}
+
+// DefaultPrices DeepSeek 官方价格（元 / 百万 tokens），以官网为准
+func DefaultPrices() map[string]Price {
+	return map[string]Price{
+		"deepseek-chat":     {Input: 2, CachedInput: 0.2, Output: 3},
+		"deepseek-reasoner": {Input: 2, CachedInput: 0.2, Output: 3},
+	}
+}
+
+// Budget 硬性预算，零值字段表示不限制
+type Budget struct {
+	MaxTokens int     `json:"max_tokens" yaml:"max_tokens"`
+	MaxCost   float64 `json:"max_cost" yaml:"max_cost"`
+}
+
+func (b Budget) exceeded(u *Usage) bool {
+	return (b.MaxTokens > 0 && u.TotalTokens > b.MaxTokens) ||
+		(b.MaxCost > 0 && u.Cost > b.MaxCost)
+}
+
+type runKey struct{}
+type sessionKey struct{}
+
+// WithRun 为 ctx 中之后的模型调用标记 run ID
+func WithRun(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, runKey{}, id)
+}
+
+// WithSession 为 ctx 中之后的模型调用标记 session ID
+func WithSession(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, id)
+}
+
+func runOf(ctx context.Context) string {
+	id, _ := ctx.Value(runKey{}).(string)
+	return id
+}
+
+func sessionOf(ctx context.Context) string {
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
+}